@@ -14,6 +14,8 @@ import (
 	"github.com/ntdat104/go-finance-dataset/internal/application/service"
 	"github.com/ntdat104/go-finance-dataset/internal/interfaces"
 	"github.com/ntdat104/go-finance-dataset/pkg/config"
+	"github.com/ntdat104/go-finance-dataset/pkg/fixture"
+	"github.com/ntdat104/go-finance-dataset/pkg/httpclient"
 	"github.com/ntdat104/go-finance-dataset/pkg/logger"
 	"github.com/ntdat104/go-finance-dataset/pkg/middleware"
 )
@@ -28,14 +30,148 @@ func main() {
 	router.Use(gin.Recovery())
 	router.Use(middleware.CorsMiddleware())
 	router.Use(middleware.ZapLoggerWithBody())
+	router.Use(middleware.DataSourceMiddleware())
 
 	systemSvc := service.NewSystemSvc()
 	interfaces.NewSystemHandler(router, systemSvc)
 
-	binanceSvc := service.NewBinanceSvc()
+	cfg := config.GetGlobalConfig()
+
+	klineStoreSvc, err := service.NewKlineStoreSvc(cfg.Store.Path)
+	if err != nil {
+		log.Fatalf("service.NewKlineStoreSvc has error: %v", err)
+	}
+
+	rateLimiterSvc, err := service.NewRateLimiterSvc(cfg.RateLimit.WeightLimits, cfg.RateLimit.SAPIWeightLimits, cfg.RateLimit.SafetyMargin, cfg.RateLimit.MaxWait)
+	if err != nil {
+		log.Fatalf("service.NewRateLimiterSvc has error: %v", err)
+	}
+	interfaces.NewRateLimitHandler(router, cfg.Admin.Token, rateLimiterSvc)
+
+	httpClient := newHTTPClient(cfg.HTTPClient)
+
+	localCacheSvc, err := service.NewCacheSvc(cfg.Cache)
+	if err != nil {
+		log.Fatalf("service.NewCacheSvc has error: %v", err)
+	}
+
+	binanceEnv, err := cfg.Binance.Env()
+	if err != nil {
+		log.Fatalf("cfg.Binance.Env has error: %v", err)
+	}
+	log.Printf("Using Binance %s environment", cfg.Binance.Environment)
+
+	binanceSvc, err := service.NewBinanceSvc(klineStoreSvc, rateLimiterSvc, httpClient, localCacheSvc, cfg.Cache, cfg.BinanceAuth, binanceEnv.Spot, cfg.Binance.UnhealthyCooldown)
+	if err != nil {
+		log.Fatalf("service.NewBinanceSvc has error: %v", err)
+	}
 	interfaces.NewBinanceHandler(router, binanceSvc)
+	interfaces.NewAccountHandler(router, cfg.Admin.Token, binanceSvc)
+	interfaces.NewCacheHandler(router, cfg.Admin.Token, binanceSvc)
+
+	providers := []service.MarketDataProvider{service.NewBinanceProvider(binanceSvc)}
+	if cfg.Coinbase.Enabled {
+		baseURL, closeFixtures := exchangeBaseURL(service.ExchangeCoinbase, cfg.Coinbase)
+		defer closeFixtures()
+		// Each upstream gets its own client, so one exchange's outage never trips another's breaker.
+		providers = append(providers, service.NewCoinbaseProvider(baseURL, newHTTPClient(cfg.HTTPClient), localCacheSvc, cfg.Cache))
+	}
+	if cfg.Kraken.Enabled {
+		baseURL, closeFixtures := exchangeBaseURL(service.ExchangeKraken, cfg.Kraken)
+		defer closeFixtures()
+		providers = append(providers, service.NewKrakenProvider(baseURL, newHTTPClient(cfg.HTTPClient), localCacheSvc, cfg.Cache))
+	}
+	marketDataSvc := service.NewMarketDataSvc(providers...)
+	interfaces.NewMarketDataHandler(router, marketDataSvc)
+
+	var (
+		futuresSvcs []service.BinanceFuturesSvc
+		usdmSvc     service.BinanceFuturesSvc
+	)
+	futuresURLs := map[string][]string{
+		service.FuturesUSDM:  binanceEnv.USDM,
+		service.FuturesCOINM: binanceEnv.COINM,
+	}
+	for market, futuresCfg := range map[string]config.FuturesMarket{
+		service.FuturesUSDM:  cfg.Futures.USDM,
+		service.FuturesCOINM: cfg.Futures.COINM,
+	} {
+		if !futuresCfg.Enabled {
+			continue
+		}
+		futuresLimiterSvc, err := service.NewRateLimiterSvc(futuresCfg.WeightLimits, nil, cfg.RateLimit.SafetyMargin, cfg.RateLimit.MaxWait)
+		if err != nil {
+			log.Fatalf("service.NewRateLimiterSvc has error: %v", err)
+		}
+		baseURLs := futuresURLs[market]
+		if futuresCfg.BaseURL != "" {
+			baseURLs = []string{futuresCfg.BaseURL}
+		}
+		if futuresCfg.Fixtures != "" {
+			if len(baseURLs) > 0 {
+				futuresCfg.BaseURL = baseURLs[0]
+			}
+			baseURL, closeFixtures := exchangeBaseURL(market, futuresCfg.Exchange)
+			defer closeFixtures()
+			baseURLs = []string{baseURL}
+		}
+		futuresSvc, err := service.NewBinanceFuturesSvc(market, baseURLs, cfg.Binance.UnhealthyCooldown, futuresLimiterSvc, newHTTPClient(cfg.HTTPClient), localCacheSvc, cfg.Cache)
+		if err != nil {
+			log.Fatalf("service.NewBinanceFuturesSvc has error: %v", err)
+		}
+		futuresSvcs = append(futuresSvcs, futuresSvc)
+		if market == service.FuturesUSDM {
+			usdmSvc = futuresSvc
+		}
+	}
+	interfaces.NewFuturesHandler(router, futuresSvcs...)
+
+	if usdmSvc != nil {
+		fundingAnalyticsSvc, err := service.NewFundingAnalyticsSvc(binanceSvc, usdmSvc, cfg.Analytics.FundingWindows)
+		if err != nil {
+			log.Fatalf("service.NewFundingAnalyticsSvc has error: %v", err)
+		}
+		interfaces.NewFundingAnalyticsHandler(router, fundingAnalyticsSvc)
+	}
+
+	streamURL := binanceEnv.Stream
+	if cfg.Stream.BaseURL != "" {
+		streamURL = cfg.Stream.BaseURL
+	}
+	if cfg.Stream.Enabled && streamURL == "" {
+		log.Fatalf("Binance %s environment has no stream host", cfg.Binance.Environment)
+	}
+	streamSvc := service.NewBinanceStreamSvc(streamURL, cfg.Stream.MaxStreamsPerConnection)
+	defer streamSvc.Close()
+	streamSvc.AddListener(binanceSvc.HandleStreamEvent)
+	if cfg.Stream.Enabled {
+		if err := streamSvc.Subscribe(cfg.Stream.Streams...); err != nil {
+			log.Fatalf("streamSvc.Subscribe has error: %v", err)
+		}
+	}
+
+	orderBookSvc := service.NewOrderBookSvc(binanceSvc, streamSvc, cfg.OrderBook.SnapshotLimit)
+	defer orderBookSvc.Close()
+	streamSvc.AddListener(orderBookSvc.HandleStreamEvent)
+	if cfg.Stream.Enabled {
+		for _, symbol := range cfg.OrderBook.Symbols {
+			if err := orderBookSvc.Track(symbol); err != nil {
+				log.Fatalf("orderBookSvc.Track has error: %v", err)
+			}
+		}
+	}
+	interfaces.NewOrderBookHandler(router, orderBookSvc)
+
+	pushSvc := service.NewPushSvc(streamSvc, cfg.Push.MaxConnections, cfg.Push.MaxSubscriptions, cfg.Push.MaxStreams, cfg.Push.BufferSize)
+	streamSvc.AddListener(pushSvc.HandleStreamEvent)
+	interfaces.NewPushHandler(router, pushSvc, cfg.Push.AllowedOrigins)
+
+	exportSvc := service.NewExportSvc(binanceSvc)
+	interfaces.NewExportHandler(router, exportSvc)
+
+	klineGapSvc := service.NewKlineGapSvc(klineStoreSvc, binanceSvc, cfg.Store.GapScanInterval, cfg.Store.GapAutoRepair)
+	interfaces.NewKlineGapHandler(router, cfg.Admin.Token, klineGapSvc)
 
-	cfg := config.GetGlobalConfig()
 	srv := &http.Server{
 		Addr:    ":" + strconv.Itoa(cfg.HTTP.Port),
 		Handler: router,
@@ -61,3 +197,34 @@ func main() {
 	}
 	log.Println("Server exiting")
 }
+
+// exchangeBaseURL returns the base URL of an additional upstream API. With fixtures
+// configured it starts an in-process server replaying them, stopped by the returned func.
+func exchangeBaseURL(name string, cfg config.Exchange) (string, func() error) {
+	if cfg.Fixtures == "" {
+		return cfg.BaseURL, func() error { return nil }
+	}
+	upstream := ""
+	if cfg.Record {
+		upstream = cfg.BaseURL
+	}
+	fixtureSrv := fixture.NewServer(cfg.Fixtures, upstream)
+	baseURL, err := fixtureSrv.ListenAndServe("127.0.0.1:0")
+	if err != nil {
+		log.Fatalf("fixture.ListenAndServe has error: %v", err)
+	}
+	log.Printf("%s served from fixtures in %s", name, cfg.Fixtures)
+	return baseURL, fixtureSrv.Close
+}
+
+// newHTTPClient builds an upstream client with its own connection pool and circuit breakers.
+func newHTTPClient(cfg config.HTTPClient) httpclient.Client {
+	return httpclient.New(httpclient.Options{
+		Timeout:          cfg.Timeout,
+		MaxRetries:       cfg.MaxRetries,
+		RetryBackoff:     cfg.RetryBackoff,
+		MaxRetryBackoff:  cfg.MaxRetryBackoff,
+		BreakerThreshold: cfg.BreakerThreshold,
+		BreakerCooldown:  cfg.BreakerCooldown,
+	})
+}
@@ -14,9 +14,60 @@ const (
 	ApiBinanceDepth            = "/api/v1/crypto/depth"
 	ApiBinanceRecentTrades     = "/api/v1/crypto/trades"
 	ApiBinanceKlines           = "/api/v1/crypto/klines"
+	ApiBinanceKlinesRange      = "/api/v1/crypto/klines/range"
 	ApiBinanceHistoricalTrades = "/api/v1/crypto/historicalTrades"
 	ApiBinanceAggregateTrades  = "/api/v1/crypto/aggregateTrades"
 	ApiBinanceAvgPrice         = "/api/v1/crypto/avgPrice"
 	ApiBinanceTicker24Hr       = "/api/v1/crypto/ticker/24hr"
 	ApiBinanceAllBookTickers   = "/api/v1/crypto/bookTicker/all"
+	ApiBinanceExport           = "/api/v1/crypto/export"
+	ApiBinanceOrderBook        = "/api/v1/crypto/orderbook"
+
+	// binanceSvc account, admin only
+	ApiAccount            = "/api/v1/crypto/account"
+	ApiAccountBalances    = "/api/v1/crypto/account/balances"
+	ApiAccountOpenOrders  = "/api/v1/crypto/account/openOrders"
+	ApiAccountMyTrades    = "/api/v1/crypto/account/myTrades"
+	ApiAccountDeposits    = "/api/v1/crypto/account/deposits"
+	ApiAccountWithdrawals = "/api/v1/crypto/account/withdrawals"
+
+	// marketDataSvc
+	ApiMarketExchanges = "/api/v1/crypto/exchanges"
+	ApiMarketSymbols   = "/api/v1/crypto/:exchange/symbols"
+	ApiMarketTicker    = "/api/v1/crypto/:exchange/ticker"
+	ApiMarketBook      = "/api/v1/crypto/:exchange/book"
+	ApiMarketDepth     = "/api/v1/crypto/:exchange/depth"
+	ApiMarketTrades    = "/api/v1/crypto/:exchange/trades"
+	ApiMarketKlines    = "/api/v1/crypto/:exchange/klines"
+
+	// binanceFuturesSvc
+	ApiFuturesMarkPrice          = "/api/v1/crypto/futures/:market/markPrice"
+	ApiFuturesFundingRate        = "/api/v1/crypto/futures/:market/fundingRate"
+	ApiFuturesOpenInterest       = "/api/v1/crypto/futures/:market/openInterest"
+	ApiFuturesLongShortRatio     = "/api/v1/crypto/futures/:market/longShortRatio"
+	ApiFuturesPremiumIndexKlines = "/api/v1/crypto/futures/:market/premiumIndexKlines"
+	ApiFuturesContinuousKlines   = "/api/v1/crypto/futures/:market/continuousKlines"
+
+	// fundingAnalyticsSvc
+	ApiAnalyticsFunding        = "/api/v1/crypto/analytics/funding"
+	ApiAnalyticsFundingRolling = "/api/v1/crypto/analytics/funding/rolling"
+	ApiAnalyticsBasis          = "/api/v1/crypto/analytics/basis"
+	ApiAnalyticsRanking        = "/api/v1/crypto/analytics/ranking"
+
+	// pushSvc
+	ApiPushWebSocket = "/api/v1/stream/ws"
+	ApiPushSSE       = "/api/v1/stream/sse"
+
+	// rateLimiterSvc
+	ApiAdminRateLimit = "/api/v1/admin/ratelimit"
+
+	// cache
+	ApiAdminCacheStats = "/api/v1/admin/cache/stats"
+	ApiAdminCacheKeys  = "/api/v1/admin/cache/keys"
+	ApiAdminCacheEntry = "/api/v1/admin/cache/entry"
+	ApiAdminCacheWarm  = "/api/v1/admin/cache/warm"
+
+	// klineGapSvc
+	ApiAdminKlineGaps       = "/api/v1/admin/klines/gaps"
+	ApiAdminKlineGapsRepair = "/api/v1/admin/klines/gaps/repair"
 )
@@ -0,0 +1,86 @@
+package dto
+
+import "github.com/ntdat104/go-finance-dataset/pkg/decimal"
+
+// Account is the spot account of an API key.
+type Account struct {
+	AccountType     string    `json:"account_type"`
+	CanTrade        bool      `json:"can_trade"`
+	CanWithdraw     bool      `json:"can_withdraw"`
+	CanDeposit      bool      `json:"can_deposit"`
+	MakerCommission int       `json:"maker_commission"`
+	TakerCommission int       `json:"taker_commission"`
+	Permissions     []string  `json:"permissions"`
+	Balances        []Balance `json:"balances"`
+	UpdateTime      int64     `json:"update_time"`
+}
+
+// Balance is the free and locked amount of one asset.
+type Balance struct {
+	Asset  string          `json:"asset"`
+	Free   decimal.Decimal `json:"free"`
+	Locked decimal.Decimal `json:"locked"`
+}
+
+// Order is an order of the account.
+type Order struct {
+	Symbol             string          `json:"symbol"`
+	OrderID            int64           `json:"order_id"`
+	ClientOrderID      string          `json:"client_order_id"`
+	Price              decimal.Decimal `json:"price"`
+	OrigQty            decimal.Decimal `json:"orig_qty"`
+	ExecutedQty        decimal.Decimal `json:"executed_qty"`
+	CumulativeQuoteQty decimal.Decimal `json:"cumulative_quote_qty"`
+	Status             string          `json:"status"`
+	TimeInForce        string          `json:"time_in_force"`
+	Type               string          `json:"type"`
+	Side               string          `json:"side"`
+	StopPrice          decimal.Decimal `json:"stop_price"`
+	Time               int64           `json:"time"`
+	UpdateTime         int64           `json:"update_time"`
+	IsWorking          bool            `json:"is_working"`
+}
+
+// AccountTrade is a fill of one of the account's orders.
+type AccountTrade struct {
+	Symbol          string          `json:"symbol"`
+	ID              int64           `json:"id"`
+	OrderID         int64           `json:"order_id"`
+	Price           decimal.Decimal `json:"price"`
+	Qty             decimal.Decimal `json:"qty"`
+	QuoteQty        decimal.Decimal `json:"quote_qty"`
+	Commission      decimal.Decimal `json:"commission"`
+	CommissionAsset string          `json:"commission_asset"`
+	Time            int64           `json:"time"`
+	IsBuyer         bool            `json:"is_buyer"`
+	IsMaker         bool            `json:"is_maker"`
+}
+
+// Deposit is a deposit to the account. Status is 0 pending, 6 credited but
+// not withdrawable yet, 1 success, 7 wrong deposit, 8 awaiting user confirmation
+// or 2 rejected.
+type Deposit struct {
+	ID         string          `json:"id"`
+	Coin       string          `json:"coin"`
+	Network    string          `json:"network"`
+	Amount     decimal.Decimal `json:"amount"`
+	Status     int             `json:"status"`
+	Address    string          `json:"address"`
+	TxID       string          `json:"tx_id"`
+	InsertTime int64           `json:"insert_time"`
+}
+
+// Withdrawal is a withdrawal from the account. Status is 0 email sent,
+// 2 awaiting approval, 3 rejected, 4 processing or 6 completed.
+type Withdrawal struct {
+	ID             string          `json:"id"`
+	Coin           string          `json:"coin"`
+	Network        string          `json:"network"`
+	Amount         decimal.Decimal `json:"amount"`
+	TransactionFee decimal.Decimal `json:"transaction_fee"`
+	Status         int             `json:"status"`
+	Address        string          `json:"address"`
+	TxID           string          `json:"tx_id"`
+	ApplyTime      int64           `json:"apply_time"`
+	CompleteTime   int64           `json:"complete_time,omitempty"`
+}
@@ -0,0 +1,63 @@
+package dto
+
+import "github.com/ntdat104/go-finance-dataset/pkg/decimal"
+
+// FundingSummary is the current funding of a perpetual. The rate applies to
+// the period settled at NextFundingTime; AnnualizedRate scales it to 365 days
+// of funding periods without compounding.
+type FundingSummary struct {
+	Symbol               string          `json:"symbol"`
+	MarkPrice            decimal.Decimal `json:"mark_price"`
+	FundingRate          decimal.Decimal `json:"funding_rate"`
+	AnnualizedRate       decimal.Decimal `json:"annualized_rate"`
+	FundingIntervalHours int             `json:"funding_interval_hours"`
+	NextFundingTime      int64           `json:"next_funding_time"`
+	Time                 int64           `json:"time"`
+}
+
+// Basis compares a perpetual with its spot market. Basis is mark minus spot
+// and Premium mark minus index; the rates divide them by spot and index.
+type Basis struct {
+	Symbol      string          `json:"symbol"`
+	SpotPrice   decimal.Decimal `json:"spot_price"`
+	MarkPrice   decimal.Decimal `json:"mark_price"`
+	IndexPrice  decimal.Decimal `json:"index_price"`
+	Basis       decimal.Decimal `json:"basis"`
+	BasisRate   decimal.Decimal `json:"basis_rate"`
+	Premium     decimal.Decimal `json:"premium"`
+	PremiumRate decimal.Decimal `json:"premium_rate"`
+	Time        int64           `json:"time"`
+}
+
+// FundingWindow aggregates the fundings settled in the window ending at To.
+// Complete is false when the available history starts after From.
+type FundingWindow struct {
+	Window         string          `json:"window"`
+	From           int64           `json:"from"`
+	To             int64           `json:"to"`
+	Count          int             `json:"count"`
+	AverageRate    decimal.Decimal `json:"average_rate"`
+	AnnualizedRate decimal.Decimal `json:"annualized_rate"`
+	CumulativeRate decimal.Decimal `json:"cumulative_rate"`
+	Complete       bool            `json:"complete"`
+}
+
+// RollingFunding is the settled funding of a perpetual over several windows.
+type RollingFunding struct {
+	Symbol               string          `json:"symbol"`
+	FundingIntervalHours int             `json:"funding_interval_hours"`
+	Windows              []FundingWindow `json:"windows"`
+}
+
+// FundingRank is one perpetual in a cross-symbol ranking. The basis fields are
+// only set when the symbol also trades spot.
+type FundingRank struct {
+	Rank                 int              `json:"rank"`
+	Symbol               string           `json:"symbol"`
+	MarkPrice            decimal.Decimal  `json:"mark_price"`
+	FundingRate          decimal.Decimal  `json:"funding_rate"`
+	AnnualizedRate       decimal.Decimal  `json:"annualized_rate"`
+	FundingIntervalHours int              `json:"funding_interval_hours"`
+	SpotPrice            *decimal.Decimal `json:"spot_price,omitempty"`
+	BasisRate            *decimal.Decimal `json:"basis_rate,omitempty"`
+}
@@ -0,0 +1,275 @@
+package dto
+
+import "github.com/ntdat104/go-finance-dataset/pkg/decimal"
+
+type Ping struct {
+	ServerTime int64  `json:"server_time"`
+	Message    string `json:"message"`
+}
+
+type RateLimit struct {
+	RateLimitType string `json:"rate_limit_type"`
+	Interval      string `json:"interval"`
+	IntervalNum   int    `json:"interval_num"`
+	Limit         int    `json:"limit"`
+}
+
+// SymbolFilter is a trading rule attached to a symbol. Only the fields relevant
+// to the filter type are set.
+type SymbolFilter struct {
+	FilterType        string           `json:"filter_type"`
+	MinPrice          *decimal.Decimal `json:"min_price,omitempty"`
+	MaxPrice          *decimal.Decimal `json:"max_price,omitempty"`
+	TickSize          *decimal.Decimal `json:"tick_size,omitempty"`
+	MinQty            *decimal.Decimal `json:"min_qty,omitempty"`
+	MaxQty            *decimal.Decimal `json:"max_qty,omitempty"`
+	StepSize          *decimal.Decimal `json:"step_size,omitempty"`
+	MinNotional       *decimal.Decimal `json:"min_notional,omitempty"`
+	MaxNotional       *decimal.Decimal `json:"max_notional,omitempty"`
+	MultiplierUp      *decimal.Decimal `json:"multiplier_up,omitempty"`
+	MultiplierDown    *decimal.Decimal `json:"multiplier_down,omitempty"`
+	BidMultiplierUp   *decimal.Decimal `json:"bid_multiplier_up,omitempty"`
+	BidMultiplierDown *decimal.Decimal `json:"bid_multiplier_down,omitempty"`
+	AskMultiplierUp   *decimal.Decimal `json:"ask_multiplier_up,omitempty"`
+	AskMultiplierDown *decimal.Decimal `json:"ask_multiplier_down,omitempty"`
+	AvgPriceMins      *int             `json:"avg_price_mins,omitempty"`
+	ApplyToMarket     *bool            `json:"apply_to_market,omitempty"`
+	Limit             *int             `json:"limit,omitempty"`
+	MaxNumOrders      *int             `json:"max_num_orders,omitempty"`
+	MaxNumAlgoOrders  *int             `json:"max_num_algo_orders,omitempty"`
+}
+
+type SymbolInfo struct {
+	Symbol                 string         `json:"symbol"`
+	Status                 string         `json:"status"`
+	BaseAsset              string         `json:"base_asset"`
+	BaseAssetPrecision     int            `json:"base_asset_precision"`
+	QuoteAsset             string         `json:"quote_asset"`
+	QuoteAssetPrecision    int            `json:"quote_asset_precision"`
+	OrderTypes             []string       `json:"order_types"`
+	IcebergAllowed         bool           `json:"iceberg_allowed"`
+	OcoAllowed             bool           `json:"oco_allowed"`
+	IsSpotTradingAllowed   bool           `json:"is_spot_trading_allowed"`
+	IsMarginTradingAllowed bool           `json:"is_margin_trading_allowed"`
+	Filters                []SymbolFilter `json:"filters"`
+	Permissions            []string       `json:"permissions,omitempty"`
+}
+
+type ExchangeInfo struct {
+	Timezone   string       `json:"timezone"`
+	ServerTime int64        `json:"server_time"`
+	RateLimits []RateLimit  `json:"rate_limits"`
+	Symbols    []SymbolInfo `json:"symbols"`
+}
+
+type TickerPrice struct {
+	Symbol string          `json:"symbol"`
+	Price  decimal.Decimal `json:"price"`
+}
+
+type BookTicker struct {
+	Symbol   string          `json:"symbol"`
+	BidPrice decimal.Decimal `json:"bid_price"`
+	BidQty   decimal.Decimal `json:"bid_qty"`
+	AskPrice decimal.Decimal `json:"ask_price"`
+	AskQty   decimal.Decimal `json:"ask_qty"`
+}
+
+type PriceLevel struct {
+	Price    decimal.Decimal `json:"price"`
+	Quantity decimal.Decimal `json:"quantity"`
+}
+
+type DepthSnapshot struct {
+	LastUpdateID int64        `json:"last_update_id"`
+	Bids         []PriceLevel `json:"bids"`
+	Asks         []PriceLevel `json:"asks"`
+}
+
+type Trade struct {
+	ID           int64           `json:"id"`
+	Price        decimal.Decimal `json:"price"`
+	Qty          decimal.Decimal `json:"qty"`
+	QuoteQty     decimal.Decimal `json:"quote_qty"`
+	Time         int64           `json:"time"`
+	IsBuyerMaker bool            `json:"is_buyer_maker"`
+	IsBestMatch  bool            `json:"is_best_match"`
+}
+
+type AggTrade struct {
+	AggTradeID   int64           `json:"agg_trade_id"`
+	Price        decimal.Decimal `json:"price"`
+	Qty          decimal.Decimal `json:"qty"`
+	FirstTradeID int64           `json:"first_trade_id"`
+	LastTradeID  int64           `json:"last_trade_id"`
+	Time         int64           `json:"time"`
+	IsBuyerMaker bool            `json:"is_buyer_maker"`
+	IsBestMatch  bool            `json:"is_best_match"`
+}
+
+type Kline struct {
+	OpenTime                 int64           `json:"open_time"`
+	Open                     decimal.Decimal `json:"open"`
+	High                     decimal.Decimal `json:"high"`
+	Low                      decimal.Decimal `json:"low"`
+	Close                    decimal.Decimal `json:"close"`
+	Volume                   decimal.Decimal `json:"volume"`
+	CloseTime                int64           `json:"close_time"`
+	QuoteAssetVolume         decimal.Decimal `json:"quote_asset_volume"`
+	NumberOfTrades           int64           `json:"number_of_trades"`
+	TakerBuyBaseAssetVolume  decimal.Decimal `json:"taker_buy_base_asset_volume"`
+	TakerBuyQuoteAssetVolume decimal.Decimal `json:"taker_buy_quote_asset_volume"`
+}
+
+type AvgPrice struct {
+	Mins      int             `json:"mins"`
+	Price     decimal.Decimal `json:"price"`
+	CloseTime int64           `json:"close_time"`
+}
+
+type Ticker24h struct {
+	Symbol             string          `json:"symbol"`
+	PriceChange        decimal.Decimal `json:"price_change"`
+	PriceChangePercent decimal.Decimal `json:"price_change_percent"`
+	WeightedAvgPrice   decimal.Decimal `json:"weighted_avg_price"`
+	PrevClosePrice     decimal.Decimal `json:"prev_close_price"`
+	LastPrice          decimal.Decimal `json:"last_price"`
+	LastQty            decimal.Decimal `json:"last_qty"`
+	BidPrice           decimal.Decimal `json:"bid_price"`
+	BidQty             decimal.Decimal `json:"bid_qty"`
+	AskPrice           decimal.Decimal `json:"ask_price"`
+	AskQty             decimal.Decimal `json:"ask_qty"`
+	OpenPrice          decimal.Decimal `json:"open_price"`
+	HighPrice          decimal.Decimal `json:"high_price"`
+	LowPrice           decimal.Decimal `json:"low_price"`
+	Volume             decimal.Decimal `json:"volume"`
+	QuoteVolume        decimal.Decimal `json:"quote_volume"`
+	OpenTime           int64           `json:"open_time"`
+	CloseTime          int64           `json:"close_time"`
+	FirstID            int64           `json:"first_id"`
+	LastID             int64           `json:"last_id"`
+	Count              int64           `json:"count"`
+}
+
+// KlineSeries describes one symbol/interval series held by the kline store.
+type KlineSeries struct {
+	Symbol        string `json:"symbol"`
+	Interval      string `json:"interval"`
+	Count         int    `json:"count"`
+	Records       int    `json:"records"`
+	Bytes         int64  `json:"bytes"`
+	FirstOpenTime int64  `json:"first_open_time,omitempty"`
+	LastOpenTime  int64  `json:"last_open_time,omitempty"`
+}
+
+// TimeWindow is an inclusive range of candle open times.
+type TimeWindow struct {
+	StartTime int64 `json:"start_time"`
+	EndTime   int64 `json:"end_time"`
+	Count     int64 `json:"count"`
+}
+
+// KlineGapReport lists the integrity problems found in a stored kline series.
+// Open time lists are capped; the counts are always exact.
+type KlineGapReport struct {
+	Symbol          string       `json:"symbol"`
+	Interval        string       `json:"interval"`
+	ScannedAt       int64        `json:"scanned_at"`
+	Records         int          `json:"records"`
+	FirstOpenTime   int64        `json:"first_open_time"`
+	LastOpenTime    int64        `json:"last_open_time"`
+	MissingCount    int64        `json:"missing_count"`
+	Missing         []TimeWindow `json:"missing"`
+	DuplicateCount  int          `json:"duplicate_count"`
+	Duplicates      []int64      `json:"duplicates"`
+	OutOfOrderCount int          `json:"out_of_order_count"`
+	OutOfOrder      []int64      `json:"out_of_order"`
+	MisalignedCount int          `json:"misaligned_count"`
+	Misaligned      []int64      `json:"misaligned"`
+}
+
+// Healthy reports whether the series has no missing, duplicate, out-of-order or misaligned rows.
+func (r *KlineGapReport) Healthy() bool {
+	return r.MissingCount == 0 && r.DuplicateCount == 0 && r.OutOfOrderCount == 0 && r.MisalignedCount == 0
+}
+
+// Stream event types, named after the Binance stream suffix.
+const (
+	StreamEventTrade       = "trade"
+	StreamEventAggTrade    = "aggTrade"
+	StreamEventKline       = "kline"
+	StreamEventBookTicker  = "bookTicker"
+	StreamEventDepthUpdate = "depthUpdate"
+	// StreamEventDisconnect reports that a stream's connection dropped, so
+	// updates may have been missed until it is back.
+	StreamEventDisconnect = "disconnect"
+)
+
+// StreamKline is a candle pushed by a kline stream; it keeps changing until Closed.
+type StreamKline struct {
+	Interval string `json:"interval"`
+	Closed   bool   `json:"closed"`
+	Kline
+}
+
+// DepthUpdate is an incremental order book change from a depth stream.
+type DepthUpdate struct {
+	EventTime     int64        `json:"event_time"`
+	FirstUpdateID int64        `json:"first_update_id"`
+	FinalUpdateID int64        `json:"final_update_id"`
+	Bids          []PriceLevel `json:"bids"`
+	Asks          []PriceLevel `json:"asks"`
+}
+
+// StreamEvent is one message received from a market data stream. Exactly one
+// of the payload fields is set, according to Type; disconnect events have none.
+type StreamEvent struct {
+	Stream      string       `json:"stream"`
+	Type        string       `json:"type"`
+	Symbol      string       `json:"symbol"`
+	Trade       *Trade       `json:"trade,omitempty"`
+	AggTrade    *AggTrade    `json:"agg_trade,omitempty"`
+	Kline       *StreamKline `json:"kline,omitempty"`
+	BookTicker  *BookTicker  `json:"book_ticker,omitempty"`
+	DepthUpdate *DepthUpdate `json:"depth_update,omitempty"`
+}
+
+// OrderBook is a locally maintained order book. Checksum is the CRC32 (IEEE)
+// of the returned levels serialised as "bidPrice:bidQty:askPrice:askQty:..."
+// interleaving bids and asks from the top, with prices and quantities as sent
+// by the exchange.
+type OrderBook struct {
+	Symbol       string       `json:"symbol"`
+	LastUpdateID int64        `json:"last_update_id"`
+	EventTime    int64        `json:"event_time"`
+	Bids         []PriceLevel `json:"bids"`
+	Asks         []PriceLevel `json:"asks"`
+	Checksum     uint32       `json:"checksum"`
+}
+
+// PushMessage is an update pushed to a subscribed client.
+type PushMessage struct {
+	Topic   string `json:"topic"`
+	Channel string `json:"channel"`
+	Symbol  string `json:"symbol"`
+	Payload any    `json:"payload"`
+}
+
+// WeightWindow is the request weight budget of one Binance rate limit interval.
+type WeightWindow struct {
+	Interval  string `json:"interval"`
+	Limit     int    `json:"limit"`
+	Used      int    `json:"used"`
+	Remaining int    `json:"remaining"`
+	ResetsAt  int64  `json:"resets_at"`
+}
+
+// RateLimitBudget is the client side view of the upstream request weight budget.
+type RateLimitBudget struct {
+	Windows     []WeightWindow `json:"windows"`
+	SAPIWindows []WeightWindow `json:"sapi_windows"`
+	BannedUntil int64          `json:"banned_until,omitempty"`
+	LastStatus  int            `json:"last_status,omitempty"`
+	Queued      int64          `json:"queued"`
+	Shed        int64          `json:"shed"`
+}
@@ -0,0 +1,67 @@
+package dto
+
+// SingleflightStats counts upstream fetches collapsed into an in-flight one.
+type SingleflightStats struct {
+	Requests  int64 `json:"requests"`
+	Executed  int64 `json:"executed"`
+	Collapsed int64 `json:"collapsed"`
+	InFlight  int   `json:"in_flight"`
+}
+
+// RefreshStats describes the background cache refresh workers.
+type RefreshStats struct {
+	Workers   int   `json:"workers"`
+	Pending   int   `json:"pending"`
+	Completed int64 `json:"completed"`
+	Failed    int64 `json:"failed"`
+	Dropped   int64 `json:"dropped"`
+}
+
+// LocalCacheStats describes a cache backend. Bytes are an estimate and only
+// tracked in memory; a two-tier cache reports its shared tier under L2.
+type LocalCacheStats struct {
+	Backend     string           `json:"backend"`
+	Entries     int              `json:"entries"`
+	Bytes       int64            `json:"bytes"`
+	MaxEntries  int              `json:"max_entries"`
+	MaxBytes    int64            `json:"max_bytes"`
+	Hits        int64            `json:"hits"`
+	Misses      int64            `json:"misses"`
+	Evictions   int64            `json:"evictions"`
+	Expirations int64            `json:"expirations"`
+	Rejected    int64            `json:"rejected"`
+	Errors      int64            `json:"errors,omitempty"`
+	L2          *LocalCacheStats `json:"l2,omitempty"`
+}
+
+// CacheStats describes the market data cache.
+type CacheStats struct {
+	Local        LocalCacheStats   `json:"local"`
+	Singleflight SingleflightStats `json:"singleflight"`
+	Refresh      RefreshStats      `json:"refresh"`
+}
+
+// CacheEntry describes one cached key. FetchedAt and AgeMs are set for
+// market data entries, Value only when a single entry is requested.
+type CacheEntry struct {
+	Key       string `json:"key"`
+	ExpiresAt int64  `json:"expires_at"`
+	FetchedAt int64  `json:"fetched_at,omitempty"`
+	AgeMs     int64  `json:"age_ms,omitempty"`
+	Value     any    `json:"value,omitempty"`
+}
+
+// CacheWarmItem names a market data request to fetch into the cache.
+// Cache is the cache name, e.g. "depth" or "klines".
+type CacheWarmItem struct {
+	Cache    string `json:"cache"`
+	Symbol   string `json:"symbol,omitempty"`
+	Interval string `json:"interval,omitempty"`
+	Limit    int    `json:"limit,omitempty"`
+}
+
+type CacheWarmResult struct {
+	CacheWarmItem
+	Key   string `json:"key,omitempty"`
+	Error string `json:"error,omitempty"`
+}
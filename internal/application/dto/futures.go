@@ -0,0 +1,55 @@
+package dto
+
+import "github.com/ntdat104/go-finance-dataset/pkg/decimal"
+
+// MarkPrice is the mark and index price of a futures contract with its current funding.
+// Pair is only set for COIN-M contracts.
+type MarkPrice struct {
+	Symbol               string          `json:"symbol"`
+	Pair                 string          `json:"pair,omitempty"`
+	MarkPrice            decimal.Decimal `json:"mark_price"`
+	IndexPrice           decimal.Decimal `json:"index_price"`
+	EstimatedSettlePrice decimal.Decimal `json:"estimated_settle_price"`
+	LastFundingRate      decimal.Decimal `json:"last_funding_rate"`
+	InterestRate         decimal.Decimal `json:"interest_rate"`
+	NextFundingTime      int64           `json:"next_funding_time"`
+	Time                 int64           `json:"time"`
+}
+
+// FundingRate is one settled funding of a perpetual contract.
+type FundingRate struct {
+	Symbol      string           `json:"symbol"`
+	FundingRate decimal.Decimal  `json:"funding_rate"`
+	FundingTime int64            `json:"funding_time"`
+	MarkPrice   *decimal.Decimal `json:"mark_price,omitempty"`
+}
+
+// OpenInterest is the open interest of a futures contract, in contracts for
+// COIN-M and in base asset for USD-M.
+type OpenInterest struct {
+	Symbol       string          `json:"symbol"`
+	Pair         string          `json:"pair,omitempty"`
+	ContractType string          `json:"contract_type,omitempty"`
+	OpenInterest decimal.Decimal `json:"open_interest"`
+	Time         int64           `json:"time"`
+}
+
+// LongShortRatio is the long/short ratio of a symbol (USD-M) or pair (COIN-M)
+// over one period. For position ratios Long and Short are position shares,
+// otherwise account shares.
+type LongShortRatio struct {
+	Symbol         string          `json:"symbol"`
+	LongShortRatio decimal.Decimal `json:"long_short_ratio"`
+	Long           decimal.Decimal `json:"long"`
+	Short          decimal.Decimal `json:"short"`
+	Timestamp      int64           `json:"timestamp"`
+}
+
+// FundingInfo is the funding schedule and rate bounds of a perpetual whose
+// settings differ from the market default.
+type FundingInfo struct {
+	Symbol                   string          `json:"symbol"`
+	FundingIntervalHours     int             `json:"funding_interval_hours"`
+	AdjustedFundingRateCap   decimal.Decimal `json:"adjusted_funding_rate_cap"`
+	AdjustedFundingRateFloor decimal.Decimal `json:"adjusted_funding_rate_floor"`
+}
@@ -0,0 +1,28 @@
+package dto
+
+import "github.com/ntdat104/go-finance-dataset/pkg/decimal"
+
+// MarketSymbol is a tradable pair in the exchange-independent naming,
+// base and quote asset concatenated (e.g. BTCUSDT).
+type MarketSymbol struct {
+	Symbol         string `json:"symbol"`
+	ExchangeSymbol string `json:"exchange_symbol"`
+	BaseAsset      string `json:"base_asset"`
+	QuoteAsset     string `json:"quote_asset"`
+	Status         string `json:"status"`
+	Active         bool   `json:"active"`
+}
+
+// MarketTicker is the rolling 24h ticker of a symbol as every exchange reports it.
+type MarketTicker struct {
+	Symbol      string          `json:"symbol"`
+	LastPrice   decimal.Decimal `json:"last_price"`
+	BidPrice    decimal.Decimal `json:"bid_price"`
+	AskPrice    decimal.Decimal `json:"ask_price"`
+	OpenPrice   decimal.Decimal `json:"open_price"`
+	HighPrice   decimal.Decimal `json:"high_price"`
+	LowPrice    decimal.Decimal `json:"low_price"`
+	Volume      decimal.Decimal `json:"volume"`
+	QuoteVolume decimal.Decimal `json:"quote_volume"`
+	Time        int64           `json:"time"`
+}
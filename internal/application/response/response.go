@@ -5,6 +5,7 @@ import (
 	"github.com/ntdat104/go-finance-dataset/internal/application/constants"
 	"github.com/ntdat104/go-finance-dataset/pkg/base64"
 	"github.com/ntdat104/go-finance-dataset/pkg/config"
+	"github.com/ntdat104/go-finance-dataset/pkg/datasource"
 	"github.com/ntdat104/go-finance-dataset/pkg/datetime"
 	"github.com/ntdat104/go-finance-dataset/pkg/http"
 	"github.com/ntdat104/go-finance-dataset/pkg/json"
@@ -20,6 +21,11 @@ type Meta struct {
 	Code      int    `json:"code"`
 	Message   string `json:"message"`
 	Token     string `json:"token,omitempty"`
+	// DataSource, FetchedAt and AgeMs tell where the data came from and how old it is.
+	// They are omitted for responses not backed by market data.
+	DataSource string `json:"data_source,omitempty"`
+	FetchedAt  int64  `json:"fetched_at,omitempty"`
+	AgeMs      *int64 `json:"age_ms,omitempty"`
 }
 
 type Response struct {
@@ -35,12 +41,15 @@ func getMessageID(ctx *gin.Context) string {
 	return messageID
 }
 
-func buildResponse(ctx *gin.Context, code int, obj any) {
+// NewResponse builds the response envelope outside of a request, e.g. for pushed updates.
+func NewResponse(messageID string, code int, obj any) Response {
+	if messageID == "" {
+		messageID = uuid.NewShortUUID()
+	}
 	now := datetime.GetCurrentMiliseconds()
-
-	response := Response{
+	return Response{
 		Meta: Meta{
-			MessageID: getMessageID(ctx),
+			MessageID: messageID,
 			Timestamp: now,
 			Datetime:  datetime.ConvertMillisecondsToString(now, datetime.YYYY_MM_DD_HH_MM_SS),
 			Code:      code,
@@ -48,6 +57,18 @@ func buildResponse(ctx *gin.Context, code int, obj any) {
 		},
 		Data: obj,
 	}
+}
+
+func buildResponse(ctx *gin.Context, code int, obj any) {
+	response := NewResponse(getMessageID(ctx), code, obj)
+	if info := datasource.FromContext(ctx.Request.Context()); info != nil {
+		if source, fetchedAt, ok := info.Get(); ok {
+			age := max(response.Meta.Timestamp-fetchedAt.UnixMilli(), 0)
+			response.Meta.DataSource = source
+			response.Meta.FetchedAt = fetchedAt.UnixMilli()
+			response.Meta.AgeMs = &age
+		}
+	}
 
 	// Set custom response header
 	privateKey, err := base64.DecodeToString(config.GetGlobalConfig().App.PrivateKey)
@@ -0,0 +1,59 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ntdat104/go-finance-dataset/pkg/config"
+	"github.com/ntdat104/go-finance-dataset/pkg/datasource"
+	"github.com/ntdat104/go-finance-dataset/pkg/logger"
+	"github.com/ntdat104/go-finance-dataset/pkg/middleware"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitDefault()
+	config.InitConfig("../../../config/dev.yml")
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+func TestMetaReportsDataSource(t *testing.T) {
+	fetchedAt := time.Now().Add(-90 * time.Second)
+	router := gin.New()
+	router.Use(middleware.DataSourceMiddleware())
+	router.GET("/stale", func(ctx *gin.Context) {
+		datasource.Record(ctx.Request.Context(), datasource.Stale, fetchedAt)
+		Success(ctx, "data")
+	})
+	router.GET("/plain", func(ctx *gin.Context) {
+		Success(ctx, "data")
+	})
+
+	get := func(path string) Meta {
+		t.Helper()
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		var body Response
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		return body.Meta
+	}
+
+	meta := get("/stale")
+	if meta.DataSource != datasource.Stale || meta.FetchedAt != fetchedAt.UnixMilli() {
+		t.Errorf("meta = %+v, want the stale source and fetch time", meta)
+	}
+	if meta.AgeMs == nil || *meta.AgeMs < 90000 || *meta.AgeMs > 100000 {
+		t.Errorf("age_ms = %v, want about 90s", meta.AgeMs)
+	}
+
+	if meta := get("/plain"); meta.DataSource != "" || meta.FetchedAt != 0 || meta.AgeMs != nil {
+		t.Errorf("meta = %+v, want no data source fields", meta)
+	}
+}
@@ -1,194 +1,361 @@
 package service
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"log"
-	"net/http"
-	"net/url"
+	"slices"
 	"sync"
 	"time"
+
+	"github.com/ntdat104/go-finance-dataset/internal/application/dto"
+	"github.com/ntdat104/go-finance-dataset/pkg/config"
+	"github.com/ntdat104/go-finance-dataset/pkg/datasource"
+	"github.com/ntdat104/go-finance-dataset/pkg/httpclient"
 )
 
 type BinanceSvc interface {
-	GetPing() (any, error)
-	GetServerTime() (any, error)
-	GetExchangeInfo() (any, error)
-	GetTickerPrice(symbol string) (any, error)
-	GetAllTickerPrices() (any, error)
-	GetBookTicker(symbol string) (any, error)
-	GetDepth(symbol string, limit int) (any, error)
-	GetRecentTrades(symbol string, limit int) (any, error)
-	GetKlines(symbol, interval string, limit int) (any, error)
-	GetHistoricalTrades(symbol string, limit int, fromId *int64) (any, error)
-	GetAggregateTrades(symbol string, fromId, startTime, endTime *int64, limit int) (any, error)
-	GetAvgPrice(symbol string) (any, error)
-	GetTicker24Hr(symbol string) (any, error)
-	GetAllBookTickers() (any, error)
+	GetPing(ctx context.Context) (*dto.Ping, error)
+	GetServerTime(ctx context.Context) (*dto.SystemTime, error)
+	GetAccount(ctx context.Context, apiKey string) (*dto.Account, error)
+	GetBalances(ctx context.Context, apiKey string) ([]dto.Balance, error)
+	GetOpenOrders(ctx context.Context, apiKey, symbol string) ([]dto.Order, error)
+	GetMyTrades(ctx context.Context, apiKey, symbol string, fromId, startTime, endTime *int64, limit int) ([]dto.AccountTrade, error)
+	GetDepositHistory(ctx context.Context, apiKey, coin string, startTime, endTime *int64, limit int) ([]dto.Deposit, error)
+	GetWithdrawHistory(ctx context.Context, apiKey, coin string, startTime, endTime *int64, limit int) ([]dto.Withdrawal, error)
+	GetExchangeInfo(ctx context.Context) (*dto.ExchangeInfo, error)
+	GetTickerPrice(ctx context.Context, symbol string) (*dto.TickerPrice, error)
+	GetAllTickerPrices(ctx context.Context) ([]dto.TickerPrice, error)
+	GetBookTicker(ctx context.Context, symbol string) (*dto.BookTicker, error)
+	GetDepth(ctx context.Context, symbol string, limit int) (*dto.DepthSnapshot, error)
+	GetDepthSnapshot(ctx context.Context, symbol string, limit int) (*dto.DepthSnapshot, error)
+	GetRecentTrades(ctx context.Context, symbol string, limit int) ([]dto.Trade, error)
+	GetKlines(ctx context.Context, symbol, interval string, limit int) ([]dto.Kline, error)
+	GetHistoricalTrades(ctx context.Context, symbol string, limit int, fromId *int64) ([]dto.Trade, error)
+	GetAggregateTrades(ctx context.Context, symbol string, fromId, startTime, endTime *int64, limit int) ([]dto.AggTrade, error)
+	GetAvgPrice(ctx context.Context, symbol string) (*dto.AvgPrice, error)
+	GetTicker24Hr(ctx context.Context, symbol string) (*dto.Ticker24h, error)
+	GetAllBookTickers(ctx context.Context) ([]dto.BookTicker, error)
+	GetKlinesRange(ctx context.Context, symbol, interval string, startTime, endTime int64) ([]dto.Kline, error)
+	StreamKlinesRange(ctx context.Context, symbol, interval string, startTime, endTime int64, fn func([]dto.Kline) error) error
+	StreamAggTradesRange(ctx context.Context, symbol string, startTime, endTime int64, fn func([]dto.AggTrade) error) error
+	StreamHistoricalTradesRange(ctx context.Context, symbol string, startTime, endTime int64, fn func([]dto.Trade) error) error
+	HandleStreamEvent(event dto.StreamEvent)
+	CacheStats() dto.CacheStats
+	CacheEntries(prefix string, limit int) []dto.CacheEntry
+	CacheEntry(key string) (*dto.CacheEntry, bool)
+	InvalidateCache(key, prefix string) int
+	WarmCache(ctx context.Context, item dto.CacheWarmItem) (string, error)
 }
 
+const (
+	// klinesPageLimit is the maximum number of candles Binance returns per klines request.
+	klinesPageLimit = 1000
+	// tradesPageLimit is the maximum number of trades Binance returns per trades request.
+	tradesPageLimit = 1000
+	// aggTradesMaxWindow is the widest startTime/endTime window the aggTrades endpoint accepts.
+	aggTradesMaxWindow = time.Hour
+	// streamTradesBuffer is the number of streamed trades kept per symbol for GetRecentTrades.
+	streamTradesBuffer = 1000
+	// maxKlinesRange caps the number of candles returned by a non-streaming range request.
+	maxKlinesRange = 100000
+)
+
 type binanceSvc struct {
+	*restClient
 	baseURL       string
-	localCacheSvc LocalCacheSvc
-	cacheTTL      time.Duration
-	cacheDelay    time.Duration
-	lock          sync.RWMutex
+	klineStoreSvc KlineStoreSvc
+	streamTrades  sync.Map // symbol -> *tradeBuffer
 }
 
-func NewBinanceSvc() BinanceSvc {
-	return &binanceSvc{
-		baseURL:       "https://api.binance.com",
-		localCacheSvc: NewLocalCacheSvc(),
-		cacheTTL:      1 * time.Minute,
-		cacheDelay:    500 * time.Millisecond,
-	}
+// tradeBuffer holds the most recent trades received from a trade stream.
+type tradeBuffer struct {
+	lock      sync.Mutex
+	trades    []dto.Trade
+	updatedAt time.Time
 }
 
-// fetchData makes an HTTP GET request to the given API URL with parameters.
-func (s *binanceSvc) fetchData(apiURL string, params map[string]string) (any, error) {
-	u, err := url.Parse(apiURL)
-	if err != nil {
-		return nil, fmt.Errorf("error parsing URL: %w", err)
-	}
-	q := u.Query()
-	for key, value := range params {
-		q.Set(key, value)
+// NewBinanceSvc creates the Binance service. cacheCfg holds the cache policies
+// and the size of the background refresh worker pool, authCfg the API keys.
+// baseURLs are equivalent spot hosts failed over in order, each skipped for
+// unhealthyCooldown after failing; an empty list defaults to the production host.
+func NewBinanceSvc(klineStoreSvc KlineStoreSvc, rateLimiterSvc RateLimiterSvc, httpClient httpclient.Client, localCacheSvc LocalCacheSvc, cacheCfg config.Cache, authCfg config.BinanceAuth, baseURLs []string, unhealthyCooldown time.Duration) (BinanceSvc, error) {
+	baseURLs = trimBaseURLs(baseURLs, "https://api.binance.com")
+	s := &binanceSvc{
+		restClient:    newRestClient("spot", rateLimiterSvc, httpClient, localCacheSvc, cacheCfg),
+		baseURL:       baseURLs[0],
+		klineStoreSvc: klineStoreSvc,
 	}
-	u.RawQuery = q.Encode()
-
-	resp, err := http.Get(u.String())
+	s.hosts = newHostPool(baseURLs, unhealthyCooldown)
+	auth, err := newBinanceAuth(authCfg, s.fetchServerTime)
 	if err != nil {
-		return nil, fmt.Errorf("error fetching data from %s: %w", u.String(), err)
+		return nil, err
 	}
-	defer resp.Body.Close()
+	s.auth = auth
+	return s, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("received non-OK status code %d from %s, response: %s", resp.StatusCode, u.String(), resp.Status)
-	}
+// General Endpoints (Spot)
 
-	var response any
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return nil, fmt.Errorf("error decoding response from %s: %w", u.String(), err)
-	}
-	return response, nil
+// GetPing tests connectivity to the Rest API.
+func (s *binanceSvc) GetPing(ctx context.Context) (*dto.Ping, error) {
+	return &dto.Ping{
+		ServerTime: time.Now().UnixMilli(),
+		Message:    "success",
+	}, nil
 }
 
-// fetchAndCache fetches data from the API and stores it in the local cache.
-func (s *binanceSvc) fetchAndCache(key, delayKey, apiURL string, params map[string]string) (any, error) {
-	data, err := s.fetchData(apiURL, params)
+// GetServerTime tests connectivity to the Rest API and get the current server time.
+func (s *binanceSvc) GetServerTime(ctx context.Context) (*dto.SystemTime, error) {
+	serverTime, err := s.fetchServerTime(ctx)
 	if err != nil {
 		return nil, err
 	}
+	return &dto.SystemTime{ServerTime: serverTime}, nil
+}
 
-	s.localCacheSvc.Set(key, data, s.cacheTTL)
-	s.localCacheSvc.Set(delayKey, true, s.cacheDelay)
-	return data, nil
+func (s *binanceSvc) fetchServerTime(ctx context.Context) (int64, error) {
+	return fetchTyped(ctx, s.restClient, s.baseURL+"/api/v3/time", nil, parseServerTime)
 }
 
-// refreshCache asynchronously refreshes the cache for a given key if the delay period has passed.
-func (s *binanceSvc) refreshCache(key, delayKey, apiURL string, params map[string]string) {
-	s.lock.Lock()
-	defer s.lock.Unlock()
+// GetExchangeInfo current exchange trading rules and symbol information.
+func (s *binanceSvc) GetExchangeInfo(ctx context.Context) (*dto.ExchangeInfo, error) {
+	return getWithCache(ctx, s.restClient, "exchangeinfo", "global", fmt.Sprintf("%v/api/v3/exchangeInfo", s.baseURL), nil, parseExchangeInfo)
+}
 
-	if _, delayExists := s.localCacheSvc.Get(delayKey); delayExists {
-		return
-	}
-	s.localCacheSvc.Set(delayKey, true, s.cacheDelay)
+// Account Endpoints (Spot)
+
+// GetAccount returns the account of the API key called apiKey, or of the
+// default key when empty, leaving out zero balances.
+func (s *binanceSvc) GetAccount(ctx context.Context, apiKey string) (*dto.Account, error) {
+	ctx = withAPIKey(ctx, apiKey)
+	params := map[string]string{"omitZeroBalances": "true"}
+	return getWithCache(ctx, s.restClient, "account", accountKey(apiKey), s.baseURL+"/api/v3/account", params, parseAccount)
+}
 
-	data, err := s.fetchData(apiURL, params)
+// GetBalances returns the non-zero balances of the account of apiKey.
+func (s *binanceSvc) GetBalances(ctx context.Context, apiKey string) ([]dto.Balance, error) {
+	account, err := s.GetAccount(ctx, apiKey)
 	if err != nil {
-		log.Printf("Failed to refresh spot cache for %s: %v", key, err)
-		s.localCacheSvc.Del(delayKey)
-		return
+		return nil, err
 	}
-
-	s.localCacheSvc.Set(key, data, s.cacheTTL)
+	return account.Balances, nil
 }
 
-// getWithCache retrieves data from cache or fetches it from the API, caching the result.
-func (s *binanceSvc) getWithCache(cacheName, keySuffix, apiURL string, params map[string]string) (any, error) {
-	key := fmt.Sprintf("spot_%s:%s", cacheName, keySuffix)
-	delayKey := fmt.Sprintf("spot_%s:%s:delay", cacheName, keySuffix)
+// GetOpenOrders returns the open orders of the account of apiKey on symbol, or
+// on every symbol when empty.
+func (s *binanceSvc) GetOpenOrders(ctx context.Context, apiKey, symbol string) ([]dto.Order, error) {
+	ctx = withAPIKey(ctx, apiKey)
+	var params map[string]string
+	keySuffix := accountKey(apiKey)
+	if symbol != "" {
+		params = map[string]string{"symbol": symbol}
+		keySuffix += "-" + symbol
+	}
+	return getWithCache(ctx, s.restClient, "openorders", keySuffix, s.baseURL+"/api/v3/openOrders", params, parseOrders)
+}
 
-	if cachedData, found := s.localCacheSvc.Get(key); found {
-		go s.refreshCache(key, delayKey, apiURL, params)
-		return cachedData, nil
+// GetMyTrades returns the fills of the account of apiKey on symbol. Binance
+// limits startTime to endTime to 24 hours.
+func (s *binanceSvc) GetMyTrades(ctx context.Context, apiKey, symbol string, fromId, startTime, endTime *int64, limit int) ([]dto.AccountTrade, error) {
+	ctx = withAPIKey(ctx, apiKey)
+	params := map[string]string{
+		"symbol": symbol,
+		"limit":  fmt.Sprintf("%d", limit),
 	}
+	keySuffix := fmt.Sprintf("%s-%s-%d", accountKey(apiKey), symbol, limit)
+	setOptionalParam(params, &keySuffix, "fromId", "f", fromId)
+	setOptionalParam(params, &keySuffix, "startTime", "s", startTime)
+	setOptionalParam(params, &keySuffix, "endTime", "e", endTime)
+	return getWithCache(ctx, s.restClient, "mytrades", keySuffix, s.baseURL+"/api/v3/myTrades", params, parseMyTrades)
+}
 
-	return s.fetchAndCache(key, delayKey, apiURL, params)
+// GetDepositHistory returns the deposits of the account of apiKey, of coin or
+// of every coin when empty. Binance limits startTime to endTime to 90 days and
+// defaults to the latest 90 days.
+func (s *binanceSvc) GetDepositHistory(ctx context.Context, apiKey, coin string, startTime, endTime *int64, limit int) ([]dto.Deposit, error) {
+	ctx = withAPIKey(ctx, apiKey)
+	params, keySuffix := walletHistoryParams(apiKey, coin, startTime, endTime, limit)
+	return getWithCache(ctx, s.restClient, "deposits", keySuffix, s.baseURL+"/sapi/v1/capital/deposit/hisrec", params, parseDeposits)
 }
 
-// General Endpoints (Spot)
+// GetWithdrawHistory returns the withdrawals of the account of apiKey, of coin
+// or of every coin when empty, with the same window limits as deposits.
+func (s *binanceSvc) GetWithdrawHistory(ctx context.Context, apiKey, coin string, startTime, endTime *int64, limit int) ([]dto.Withdrawal, error) {
+	ctx = withAPIKey(ctx, apiKey)
+	params, keySuffix := walletHistoryParams(apiKey, coin, startTime, endTime, limit)
+	return getWithCache(ctx, s.restClient, "withdrawals", keySuffix, s.baseURL+"/sapi/v1/capital/withdraw/history", params, parseWithdrawals)
+}
 
-// GetPing tests connectivity to the Rest API.
-func (s *binanceSvc) GetPing() (any, error) {
-	return map[string]any{
-		"serverTime": time.Now().UnixMilli(),
-		"message":    "success",
-	}, nil
+func walletHistoryParams(apiKey, coin string, startTime, endTime *int64, limit int) (map[string]string, string) {
+	params := map[string]string{"limit": fmt.Sprintf("%d", limit)}
+	keySuffix := fmt.Sprintf("%s-%d", accountKey(apiKey), limit)
+	if coin != "" {
+		params["coin"] = coin
+		keySuffix += "-" + coin
+	}
+	setOptionalParam(params, &keySuffix, "startTime", "s", startTime)
+	setOptionalParam(params, &keySuffix, "endTime", "e", endTime)
+	return params, keySuffix
 }
 
-// GetServerTime tests connectivity to the Rest API and get the current server time.
-func (s *binanceSvc) GetServerTime() (any, error) {
-	return map[string]any{
-		"serverTime": time.Now().UnixMilli(),
-	}, nil
+// setOptionalParam sets params[name] and tags keySuffix when v is given.
+func setOptionalParam(params map[string]string, keySuffix *string, name, tag string, v *int64) {
+	if v == nil {
+		return
+	}
+	params[name] = fmt.Sprintf("%d", *v)
+	*keySuffix += fmt.Sprintf("-%s%d", tag, *v)
 }
 
-// GetExchangeInfo current exchange trading rules and symbol information.
-func (s *binanceSvc) GetExchangeInfo() (any, error) {
-	return s.getWithCache("exchangeinfo", "global", fmt.Sprintf("%v/api/v3/exchangeInfo", s.baseURL), nil)
+// accountKey names the cache entries of an API key's account data.
+func accountKey(apiKey string) string {
+	if apiKey == "" {
+		return "default"
+	}
+	return apiKey
 }
 
 // Market Data Endpoints (Spot)
 
 // GetTickerPrice returns the latest price for a symbol or all symbols.
-func (s *binanceSvc) GetTickerPrice(symbol string) (any, error) {
+func (s *binanceSvc) GetTickerPrice(ctx context.Context, symbol string) (*dto.TickerPrice, error) {
 	params := map[string]string{"symbol": symbol}
-	return s.getWithCache("tickerprice", symbol, fmt.Sprintf("%v/api/v3/ticker/price", s.baseURL), params)
+	return getWithCache(ctx, s.restClient, "tickerprice", symbol, fmt.Sprintf("%v/api/v3/ticker/price", s.baseURL), params, parseTickerPrice)
 }
 
 // GetAllTickerPrices returns the latest price for all symbols.
-func (s *binanceSvc) GetAllTickerPrices() (any, error) {
-	return s.getWithCache("alltickerprices", "global", fmt.Sprintf("%v/api/v3/ticker/price", s.baseURL), nil)
+func (s *binanceSvc) GetAllTickerPrices(ctx context.Context) ([]dto.TickerPrice, error) {
+	return getWithCache(ctx, s.restClient, "alltickerprices", "global", fmt.Sprintf("%v/api/v3/ticker/price", s.baseURL), nil, parseTickerPrices)
 }
 
 // GetBookTicker returns the best price/qty on the order book for a symbol.
-func (s *binanceSvc) GetBookTicker(symbol string) (any, error) {
+func (s *binanceSvc) GetBookTicker(ctx context.Context, symbol string) (*dto.BookTicker, error) {
 	params := map[string]string{"symbol": symbol}
-	return s.getWithCache("bookticker", symbol, s.baseURL+"/api/v3/ticker/bookTicker", params)
+	return getWithCache(ctx, s.restClient, "bookticker", symbol, s.baseURL+"/api/v3/ticker/bookTicker", params, parseBookTicker)
 }
 
 // GetDepth returns the order book for a symbol.
-func (s *binanceSvc) GetDepth(symbol string, limit int) (any, error) {
+func (s *binanceSvc) GetDepth(ctx context.Context, symbol string, limit int) (*dto.DepthSnapshot, error) {
+	params := map[string]string{
+		"symbol": symbol,
+		"limit":  fmt.Sprintf("%d", limit),
+	}
+	return getWithCache(ctx, s.restClient, "depth", fmt.Sprintf("%s-%d", symbol, limit), s.baseURL+"/api/v3/depth", params, parseDepth)
+}
+
+// GetDepthSnapshot returns the order book for a symbol straight from upstream, bypassing the cache.
+func (s *binanceSvc) GetDepthSnapshot(ctx context.Context, symbol string, limit int) (*dto.DepthSnapshot, error) {
 	params := map[string]string{
 		"symbol": symbol,
 		"limit":  fmt.Sprintf("%d", limit),
 	}
-	return s.getWithCache("depth", fmt.Sprintf("%s-%d", symbol, limit), s.baseURL+"/api/v3/depth", params)
+	snapshot, err := fetchTyped(ctx, s.restClient, s.baseURL+"/api/v3/depth", params, parseDepth)
+	if err != nil {
+		return nil, err
+	}
+	datasource.Record(ctx, datasource.Upstream, time.Now())
+	return snapshot, nil
 }
 
 // GetRecentTrades Get recent trades.
-func (s *binanceSvc) GetRecentTrades(symbol string, limit int) (any, error) {
+// Symbols with a subscribed trade stream are served from the streamed trades
+// when the buffer holds the limit asked for; anything else goes upstream.
+func (s *binanceSvc) GetRecentTrades(ctx context.Context, symbol string, limit int) ([]dto.Trade, error) {
+	if val, ok := s.streamTrades.Load(symbol); ok {
+		buffer := val.(*tradeBuffer)
+		buffer.lock.Lock()
+		if limit > 0 && limit <= len(buffer.trades) && time.Since(buffer.updatedAt) < s.policies.get("recenttrades", "").ttl {
+			trades := slices.Clone(buffer.trades[len(buffer.trades)-limit:])
+			updatedAt := buffer.updatedAt
+			buffer.lock.Unlock()
+			datasource.Record(ctx, datasource.Cache, updatedAt)
+			return trades, nil
+		}
+		buffer.lock.Unlock()
+	}
+
 	params := map[string]string{
 		"symbol": symbol,
 		"limit":  fmt.Sprintf("%d", limit),
 	}
-	return s.getWithCache("recenttrades", fmt.Sprintf("%s-%d", symbol, limit), s.baseURL+"/api/v3/trades", params)
+	return getWithCache(ctx, s.restClient, "recenttrades", fmt.Sprintf("%s-%d", symbol, limit), s.baseURL+"/api/v3/trades", params, parseTrades)
 }
 
 // GetKlines returns candlestick data for a symbol.
-func (s *binanceSvc) GetKlines(symbol, interval string, limit int) (any, error) {
+// Closed candles are served from the kline store when it holds the complete recent window,
+// so only the still-open candle has to come from upstream.
+func (s *binanceSvc) GetKlines(ctx context.Context, symbol, interval string, limit int) ([]dto.Kline, error) {
+	if klines, ok := s.getKlinesFromStore(ctx, symbol, interval, limit); ok {
+		return klines, nil
+	}
+
 	params := map[string]string{
 		"symbol":   symbol,
 		"interval": interval,
 		"limit":    fmt.Sprintf("%d", limit),
 	}
-	return s.getWithCache("klines", fmt.Sprintf("%s-%s-%d", symbol, interval, limit), s.baseURL+"/api/v3/klines", params)
+	klines, err := getWithCache(ctx, s.restClient, "klines", fmt.Sprintf("%s-%s-%d", symbol, interval, limit), s.baseURL+"/api/v3/klines", params, parseKlines)
+	if err != nil {
+		return nil, err
+	}
+	s.storeClosedKlines(symbol, interval, klines)
+	return klines, nil
+}
+
+// getKlinesFromStore assembles the latest limit candles from the store plus the open candle.
+// It reports false when the store cannot serve the request without holes.
+func (s *binanceSvc) getKlinesFromStore(ctx context.Context, symbol, interval string, limit int) ([]dto.Kline, bool) {
+	if ValidateInterval(interval) != nil || limit <= 1 {
+		return nil, false
+	}
+	stored, err := s.klineStoreSvc.Last(symbol, interval, limit-1)
+	if err != nil || len(stored) < limit-1 {
+		return nil, false
+	}
+	currentOpenTime := AlignOpenTime(interval, time.Now().UnixMilli())
+	for i := range stored {
+		next := currentOpenTime
+		if i+1 < len(stored) {
+			next = stored[i+1].OpenTime
+		}
+		if NextOpenTime(interval, stored[i].OpenTime) != next {
+			return nil, false
+		}
+	}
+
+	params := map[string]string{
+		"symbol":   symbol,
+		"interval": interval,
+		"limit":    "1",
+	}
+	open, err := getWithCache(ctx, s.restClient, "klines", fmt.Sprintf("%s-%s-1", symbol, interval), s.baseURL+"/api/v3/klines", params, parseKlines)
+	if err != nil || len(open) != 1 || open[0].OpenTime != currentOpenTime {
+		return nil, false
+	}
+	return append(stored, open[0]), true
+}
+
+// storeClosedKlines persists the candles that can no longer change.
+func (s *binanceSvc) storeClosedKlines(symbol, interval string, klines []dto.Kline) {
+	if ValidateInterval(interval) != nil {
+		return
+	}
+	now := time.Now().UnixMilli()
+	closed := make([]dto.Kline, 0, len(klines))
+	for _, k := range klines {
+		if k.CloseTime < now {
+			closed = append(closed, k)
+		}
+	}
+	if err := s.klineStoreSvc.Append(symbol, interval, closed); err != nil {
+		log.Printf("Failed to store klines for %s %s: %v", symbol, interval, err)
+	}
 }
 
 // GetHistoricalTrades Get compressed, aggregate trades.
-func (s *binanceSvc) GetHistoricalTrades(symbol string, limit int, fromId *int64) (any, error) {
+func (s *binanceSvc) GetHistoricalTrades(ctx context.Context, symbol string, limit int, fromId *int64) ([]dto.Trade, error) {
 	params := map[string]string{
 		"symbol": symbol,
 		"limit":  fmt.Sprintf("%d", limit),
@@ -200,11 +367,11 @@ func (s *binanceSvc) GetHistoricalTrades(symbol string, limit int, fromId *int64
 	if fromId != nil {
 		keySuffix += fmt.Sprintf("-%d", *fromId)
 	}
-	return s.getWithCache("historicaltrades", keySuffix, s.baseURL+"/api/v3/historicalTrades", params)
+	return getWithCache(ctx, s.restClient, "historicaltrades", keySuffix, s.baseURL+"/api/v3/historicalTrades", params, parseTrades)
 }
 
 // GetAggregateTrades Get compressed, aggregate trades.
-func (s *binanceSvc) GetAggregateTrades(symbol string, fromId, startTime, endTime *int64, limit int) (any, error) {
+func (s *binanceSvc) GetAggregateTrades(ctx context.Context, symbol string, fromId, startTime, endTime *int64, limit int) ([]dto.AggTrade, error) {
 	params := map[string]string{
 		"symbol": symbol,
 	}
@@ -229,22 +396,270 @@ func (s *binanceSvc) GetAggregateTrades(symbol string, fromId, startTime, endTim
 	if endTime != nil {
 		keySuffix += fmt.Sprintf("-e%d", *endTime)
 	}
-	return s.getWithCache("aggregatetrades", keySuffix, s.baseURL+"/api/v3/aggTrades", params)
+	return getWithCache(ctx, s.restClient, "aggregatetrades", keySuffix, s.baseURL+"/api/v3/aggTrades", params, parseAggTrades)
 }
 
 // GetAvgPrice Current average price for a symbol.
-func (s *binanceSvc) GetAvgPrice(symbol string) (any, error) {
+func (s *binanceSvc) GetAvgPrice(ctx context.Context, symbol string) (*dto.AvgPrice, error) {
 	params := map[string]string{"symbol": symbol}
-	return s.getWithCache("avgprice", symbol, s.baseURL+"/api/v3/avgPrice", params)
+	return getWithCache(ctx, s.restClient, "avgprice", symbol, s.baseURL+"/api/v3/avgPrice", params, parseAvgPrice)
 }
 
 // GetTicker24Hr 24hr Ticker Price Change Statistics.
-func (s *binanceSvc) GetTicker24Hr(symbol string) (any, error) {
+func (s *binanceSvc) GetTicker24Hr(ctx context.Context, symbol string) (*dto.Ticker24h, error) {
 	params := map[string]string{"symbol": symbol}
-	return s.getWithCache("ticker24hr", symbol, s.baseURL+"/api/v3/ticker/24hr", params)
+	return getWithCache(ctx, s.restClient, "ticker24hr", symbol, s.baseURL+"/api/v3/ticker/24hr", params, parseTicker24h)
 }
 
 // GetAllBookTickers returns the best price/qty on the order book for all symbols.
-func (s *binanceSvc) GetAllBookTickers() (any, error) {
-	return s.getWithCache("allbooktickers", "global", s.baseURL+"/api/v3/ticker/bookTicker", nil)
+func (s *binanceSvc) GetAllBookTickers(ctx context.Context) ([]dto.BookTicker, error) {
+	return getWithCache(ctx, s.restClient, "allbooktickers", "global", s.baseURL+"/api/v3/ticker/bookTicker", nil, parseBookTickers)
+}
+
+// Historical Data Endpoints (Spot)
+
+// GetKlinesRange returns every candle between startTime and endTime (inclusive, in milliseconds).
+// Ranges fully covered by the kline store are served from disk.
+func (s *binanceSvc) GetKlinesRange(ctx context.Context, symbol, interval string, startTime, endTime int64) ([]dto.Kline, error) {
+	if klines, ok := s.getKlinesRangeFromStore(symbol, interval, startTime, endTime); ok {
+		return klines, nil
+	}
+
+	var klines []dto.Kline
+	err := s.StreamKlinesRange(ctx, symbol, interval, startTime, endTime, func(page []dto.Kline) error {
+		if len(klines)+len(page) > maxKlinesRange {
+			return fmt.Errorf("range exceeds %d klines, use streaming instead", maxKlinesRange)
+		}
+		klines = append(klines, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if klines == nil {
+		klines = []dto.Kline{}
+	}
+	return klines, nil
+}
+
+// StreamKlinesRange walks the range between startTime and endTime in upstream-sized pages
+// and calls fn with each page of new candles in open time order.
+func (s *binanceSvc) StreamKlinesRange(ctx context.Context, symbol, interval string, startTime, endTime int64, fn func([]dto.Kline) error) error {
+	if err := ValidateInterval(interval); err != nil {
+		return err
+	}
+	if endTime < startTime {
+		return fmt.Errorf("endTime %d is before startTime %d", endTime, startTime)
+	}
+
+	cursor := startTime
+	lastOpenTime := int64(-1)
+	for cursor <= endTime {
+		page, err := s.fetchKlinesPage(ctx, symbol, interval, cursor, endTime, klinesPageLimit)
+		if err != nil {
+			return err
+		}
+
+		fresh := page[:0]
+		for _, k := range page {
+			// Pages overlap on their boundary candle, and upstream never returns
+			// candles out of order, so anything not newer than the last one is a duplicate.
+			if k.OpenTime <= lastOpenTime || k.OpenTime > endTime {
+				continue
+			}
+			fresh = append(fresh, k)
+			lastOpenTime = k.OpenTime
+		}
+		if len(fresh) > 0 {
+			s.storeClosedKlines(symbol, interval, fresh)
+			if err := fn(fresh); err != nil {
+				return err
+			}
+		}
+		if len(page) < klinesPageLimit || len(fresh) == 0 {
+			return nil
+		}
+		cursor = NextOpenTime(interval, lastOpenTime)
+	}
+	return nil
+}
+
+// getKlinesRangeFromStore returns the stored candles for the range if none of them are missing.
+func (s *binanceSvc) getKlinesRangeFromStore(symbol, interval string, startTime, endTime int64) ([]dto.Kline, bool) {
+	if ValidateInterval(interval) != nil || endTime < startTime {
+		return nil, false
+	}
+	stored, err := s.klineStoreSvc.Range(symbol, interval, startTime, endTime)
+	if err != nil || len(stored) == 0 {
+		return nil, false
+	}
+
+	expected := AlignOpenTime(interval, startTime)
+	if expected < startTime {
+		expected = NextOpenTime(interval, expected)
+	}
+	for _, k := range stored {
+		if k.OpenTime != expected {
+			return nil, false
+		}
+		expected = NextOpenTime(interval, expected)
+	}
+	// The store only holds closed candles, so a range reaching into the open candle is never complete.
+	if expected <= endTime {
+		return nil, false
+	}
+	return stored, true
+}
+
+// fetchKlinesPage fetches one page of candles starting at startTime without caching.
+func (s *binanceSvc) fetchKlinesPage(ctx context.Context, symbol, interval string, startTime, endTime int64, limit int) ([]dto.Kline, error) {
+	params := map[string]string{
+		"symbol":    symbol,
+		"interval":  interval,
+		"startTime": fmt.Sprintf("%d", startTime),
+		"endTime":   fmt.Sprintf("%d", endTime),
+		"limit":     fmt.Sprintf("%d", limit),
+	}
+	return fetchTyped(ctx, s.restClient, s.baseURL+"/api/v3/klines", params, parseKlines)
+}
+
+// StreamAggTradesRange walks the aggregate trades between startTime and endTime (inclusive, in
+// milliseconds) and calls fn with each page. The first trade is located with hour-wide time windows,
+// after which pages are walked by id, which avoids the window limit.
+func (s *binanceSvc) StreamAggTradesRange(ctx context.Context, symbol string, startTime, endTime int64, fn func([]dto.AggTrade) error) error {
+	if endTime < startTime {
+		return fmt.Errorf("endTime %d is before startTime %d", endTime, startTime)
+	}
+
+	var page []dto.AggTrade
+	for windowStart := startTime; windowStart <= endTime && len(page) == 0; windowStart += aggTradesMaxWindow.Milliseconds() {
+		windowEnd := min(windowStart+aggTradesMaxWindow.Milliseconds()-1, endTime)
+		params := map[string]string{
+			"symbol":    symbol,
+			"startTime": fmt.Sprintf("%d", windowStart),
+			"endTime":   fmt.Sprintf("%d", windowEnd),
+			"limit":     fmt.Sprintf("%d", tradesPageLimit),
+		}
+		var err error
+		page, err = fetchTyped(ctx, s.restClient, s.baseURL+"/api/v3/aggTrades", params, parseAggTrades)
+		if err != nil {
+			return err
+		}
+	}
+
+	for len(page) > 0 {
+		inRange := page
+		for i, t := range page {
+			if t.Time > endTime {
+				inRange = page[:i]
+				break
+			}
+		}
+		if len(inRange) > 0 {
+			if err := fn(inRange); err != nil {
+				return err
+			}
+		}
+		if len(inRange) < len(page) || len(page) < tradesPageLimit {
+			return nil
+		}
+
+		params := map[string]string{
+			"symbol": symbol,
+			"fromId": fmt.Sprintf("%d", page[len(page)-1].AggTradeID+1),
+			"limit":  fmt.Sprintf("%d", tradesPageLimit),
+		}
+		var err error
+		page, err = fetchTyped(ctx, s.restClient, s.baseURL+"/api/v3/aggTrades", params, parseAggTrades)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StreamHistoricalTradesRange walks the individual trades between startTime and endTime (inclusive,
+// in milliseconds) and calls fn with each page. The first trade id is taken from the aggregate trades,
+// since the historical trades endpoint can only be paged by id.
+func (s *binanceSvc) StreamHistoricalTradesRange(ctx context.Context, symbol string, startTime, endTime int64, fn func([]dto.Trade) error) error {
+	var fromID int64 = -1
+	errFound := errors.New("found")
+	err := s.StreamAggTradesRange(ctx, symbol, startTime, endTime, func(page []dto.AggTrade) error {
+		fromID = page[0].FirstTradeID
+		return errFound
+	})
+	if err != nil && !errors.Is(err, errFound) {
+		return err
+	}
+	if fromID < 0 {
+		return nil
+	}
+
+	for {
+		params := map[string]string{
+			"symbol": symbol,
+			"fromId": fmt.Sprintf("%d", fromID),
+			"limit":  fmt.Sprintf("%d", tradesPageLimit),
+		}
+		page, err := fetchTyped(ctx, s.restClient, s.baseURL+"/api/v3/historicalTrades", params, parseTrades)
+		if err != nil {
+			return err
+		}
+
+		inRange := page
+		for i, t := range page {
+			if t.Time > endTime {
+				inRange = page[:i]
+				break
+			}
+		}
+		if len(inRange) > 0 {
+			if err := fn(inRange); err != nil {
+				return err
+			}
+		}
+		if len(inRange) < len(page) || len(page) < tradesPageLimit {
+			return nil
+		}
+		fromID = page[len(page)-1].ID + 1
+	}
+}
+
+// Stream Ingestion
+
+// HandleStreamEvent feeds a stream event into the cache and the kline store, so the REST
+// endpoints serve stream-fresh data without spending request weight.
+func (s *binanceSvc) HandleStreamEvent(event dto.StreamEvent) {
+	switch event.Type {
+	case dto.StreamEventTrade:
+		s.setStreamed("tickerprice", "", event.Symbol, &dto.TickerPrice{Symbol: event.Symbol, Price: event.Trade.Price})
+		val, _ := s.streamTrades.LoadOrStore(event.Symbol, &tradeBuffer{})
+		buffer := val.(*tradeBuffer)
+		buffer.lock.Lock()
+		buffer.trades = append(buffer.trades, *event.Trade)
+		buffer.updatedAt = time.Now()
+		if len(buffer.trades) > 2*streamTradesBuffer {
+			buffer.trades = slices.Clone(buffer.trades[len(buffer.trades)-streamTradesBuffer:])
+		}
+		buffer.lock.Unlock()
+	case dto.StreamEventAggTrade:
+		s.setStreamed("tickerprice", "", event.Symbol, &dto.TickerPrice{Symbol: event.Symbol, Price: event.AggTrade.Price})
+	case dto.StreamEventBookTicker:
+		s.setStreamed("bookticker", "", event.Symbol, event.BookTicker)
+	case dto.StreamEventKline:
+		k := event.Kline
+		// This is the key getKlinesFromStore reads the open candle from.
+		s.setStreamed("klines", k.Interval, fmt.Sprintf("%s-%s-1", event.Symbol, k.Interval), []dto.Kline{k.Kline})
+		if k.Closed {
+			if err := s.klineStoreSvc.Append(event.Symbol, k.Interval, []dto.Kline{k.Kline}); err != nil {
+				log.Printf("Failed to store streamed kline for %s %s: %v", event.Symbol, k.Interval, err)
+			}
+		}
+	}
+}
+
+// setStreamed stores streamed data under the same key getWithCache uses and defers the REST refresh.
+func (s *binanceSvc) setStreamed(cacheName, interval, keySuffix string, data any) {
+	key, delayKey := s.cacheKeys(cacheName, keySuffix)
+	s.setCached(key, delayKey, data, s.policies.get(cacheName, interval))
 }
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+// accountServer serves canned signed account endpoints and records the
+// queries and API keys it receives per path. Unsigned requests are rejected.
+type accountServer struct {
+	*httptest.Server
+	lock     sync.Mutex
+	requests map[string][]url.Values
+	keys     map[string][]string
+}
+
+func newAccountServer(t *testing.T) *accountServer {
+	t.Helper()
+	bodies := map[string]string{
+		"/api/v3/account": `{"makerCommission":10,"takerCommission":10,"canTrade":true,"canWithdraw":true,"canDeposit":true,"updateTime":1746100800000,"accountType":"SPOT",
+			"balances":[{"asset":"BTC","free":"0.50000000","locked":"0.01000000"},{"asset":"USDT","free":"1000.00","locked":"0.00"}],"permissions":["SPOT"]}`,
+		"/api/v3/openOrders": `[{"symbol":"BTCUSDT","orderId":28,"clientOrderId":"web_1","price":"60000.00","origQty":"0.01","executedQty":"0.00","cummulativeQuoteQty":"0.00",
+			"status":"NEW","timeInForce":"GTC","type":"LIMIT","side":"BUY","stopPrice":"0.00","time":1746100000000,"updateTime":1746100000000,"isWorking":true}]`,
+		"/api/v3/myTrades": `[{"symbol":"BTCUSDT","id":28457,"orderId":100234,"price":"67000.10","qty":"0.002","quoteQty":"134.0002","commission":"0.000002",
+			"commissionAsset":"BTC","time":1746100700000,"isBuyer":true,"isMaker":false}]`,
+		"/sapi/v1/capital/deposit/hisrec": `[{"id":"769800519366885376","amount":"0.001","coin":"BTC","network":"BTC","status":1,"address":"1HPn8Rx2y6nNSfagQBKy27GB99Vbzg89wv",
+			"txId":"b3c6219639c8ae3f9cf010cdc24fw7f7yt8j1e063f9b4bd1a05cb44c4b6e2509","insertTime":1746000000000}]`,
+		"/sapi/v1/capital/withdraw/history": `[{"id":"b6ae22b3aa844210a7041aee7589627c","amount":"8.91000000","transactionFee":"0.004","coin":"USDT","network":"ETH","status":6,
+			"address":"0x94df8b352de7f46f64b01d3666bf6e936e44ce60","txId":"0xb5ef8c13b968a406cc62a93a8bd80f9e9a906ef1b3fcf20a2e48573c17659268",
+			"applyTime":"2025-05-01 10:00:00","completeTime":"2025-05-01 10:05:30"}]`,
+	}
+	s := &accountServer{requests: make(map[string][]url.Values), keys: make(map[string][]string)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		body, ok := bodies[r.URL.Path]
+		if !ok || q.Get("signature") == "" || q.Get("timestamp") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":-1102,"msg":"Mandatory parameter 'signature' was not sent."}`))
+			return
+		}
+		s.lock.Lock()
+		s.requests[r.URL.Path] = append(s.requests[r.URL.Path], q)
+		s.keys[r.URL.Path] = append(s.keys[r.URL.Path], r.Header.Get(headerAPIKey))
+		s.lock.Unlock()
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *accountServer) received(path string) ([]url.Values, []string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.requests[path], s.keys[path]
+}
+
+func newTestAccountSvc(t *testing.T) (*binanceSvc, *accountServer) {
+	t.Helper()
+	server := newAccountServer(t)
+	svc := newTestBinanceSvc(t, server.URL)
+	svc.auth = newTestAuth(t, func(context.Context) (int64, error) { return time.Now().UnixMilli(), nil })
+	return svc, server
+}
+
+func TestAccountEndpointsAreSigned(t *testing.T) {
+	svc, server := newTestAccountSvc(t)
+	ctx := context.Background()
+
+	account, err := svc.GetAccount(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.AccountType != "SPOT" || len(account.Balances) != 2 {
+		t.Fatalf("account = %+v", account)
+	}
+	assertDecimal(t, "BTC free", account.Balances[0].Free, "0.5")
+	assertDecimal(t, "BTC locked", account.Balances[0].Locked, "0.01")
+
+	// Balances come from the cached account; another key has its own entry.
+	if _, err := svc.GetBalances(ctx, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.GetBalances(ctx, "other"); err != nil {
+		t.Fatal(err)
+	}
+	if _, keys := server.received("/api/v3/account"); len(keys) != 2 || keys[0] != "main-key" || keys[1] != "other-key" {
+		t.Errorf("account requests used keys %v, want main-key then other-key", keys)
+	}
+
+	orders, err := svc.GetOpenOrders(ctx, "", "BTCUSDT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orders) != 1 || orders[0].OrderID != 28 || orders[0].Side != "BUY" {
+		t.Fatalf("orders = %+v", orders)
+	}
+	assertDecimal(t, "Price", orders[0].Price, "60000")
+	if _, err := svc.GetOpenOrders(ctx, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if queries, _ := server.received("/api/v3/openOrders"); len(queries) != 2 || queries[0].Get("symbol") != "BTCUSDT" || queries[1].Has("symbol") {
+		t.Errorf("openOrders queries = %v, want one on BTCUSDT and one on every symbol", queries)
+	}
+
+	fromID, startTime, endTime := int64(28000), int64(1746014400000), int64(1746100800000)
+	trades, err := svc.GetMyTrades(ctx, "", "BTCUSDT", &fromID, &startTime, &endTime, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trades) != 1 || trades[0].ID != 28457 || !trades[0].IsBuyer {
+		t.Fatalf("trades = %+v", trades)
+	}
+	assertDecimal(t, "Commission", trades[0].Commission, "0.000002")
+	queries, _ := server.received("/api/v3/myTrades")
+	if len(queries) != 1 {
+		t.Fatalf("%d myTrades requests, want 1", len(queries))
+	}
+	want := url.Values{"symbol": {"BTCUSDT"}, "limit": {"10"}, "fromId": {"28000"}, "startTime": {"1746014400000"}, "endTime": {"1746100800000"}}
+	for name := range want {
+		if got := queries[0].Get(name); got != want.Get(name) {
+			t.Errorf("myTrades %s = %q, want %q", name, got, want.Get(name))
+		}
+	}
+
+	deposits, err := svc.GetDepositHistory(ctx, "", "BTC", nil, nil, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deposits) != 1 || deposits[0].Status != 1 || deposits[0].InsertTime != 1746000000000 {
+		t.Fatalf("deposits = %+v", deposits)
+	}
+	if queries, _ := server.received("/sapi/v1/capital/deposit/hisrec"); len(queries) != 1 || queries[0].Get("coin") != "BTC" || queries[0].Has("startTime") {
+		t.Errorf("deposit queries = %v, want coin BTC and no time range", queries)
+	}
+
+	withdrawals, err := svc.GetWithdrawHistory(ctx, "", "", nil, nil, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(withdrawals) != 1 || withdrawals[0].Status != 6 {
+		t.Fatalf("withdrawals = %+v", withdrawals)
+	}
+	assertDecimal(t, "TransactionFee", withdrawals[0].TransactionFee, "0.004")
+	if want := time.Date(2025, 5, 1, 10, 5, 30, 0, time.UTC).UnixMilli(); withdrawals[0].CompleteTime != want {
+		t.Errorf("CompleteTime = %d, want %d", withdrawals[0].CompleteTime, want)
+	}
+}
+
+func TestAccountWithoutCredentials(t *testing.T) {
+	svc, server := newTestAccountSvc(t)
+	if _, err := svc.GetAccount(context.Background(), "unset"); !errors.Is(err, ErrMissingCredentials) {
+		t.Errorf("GetAccount(unset) error = %v, want ErrMissingCredentials", err)
+	}
+	if _, err := svc.GetAccount(context.Background(), "missing"); err == nil {
+		t.Error("GetAccount(missing) succeeded for an unknown key")
+	}
+	if queries, _ := server.received("/api/v3/account"); len(queries) != 0 {
+		t.Errorf("%d account requests went out without credentials", len(queries))
+	}
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ntdat104/go-finance-dataset/internal/application/dto"
+)
+
+// Wire types mirror the raw Binance spot account and wallet payloads.
+
+type binanceBalance struct {
+	Asset  string `json:"asset"`
+	Free   string `json:"free"`
+	Locked string `json:"locked"`
+}
+
+type binanceAccount struct {
+	MakerCommission int              `json:"makerCommission"`
+	TakerCommission int              `json:"takerCommission"`
+	CanTrade        bool             `json:"canTrade"`
+	CanWithdraw     bool             `json:"canWithdraw"`
+	CanDeposit      bool             `json:"canDeposit"`
+	UpdateTime      int64            `json:"updateTime"`
+	AccountType     string           `json:"accountType"`
+	Balances        []binanceBalance `json:"balances"`
+	Permissions     []string         `json:"permissions"`
+}
+
+func parseAccount(data []byte) (*dto.Account, error) {
+	var w binanceAccount
+	if err := decodeStrict(data, &w); err != nil {
+		return nil, err
+	}
+	if w.AccountType == "" {
+		return nil, errors.New("field accountType: missing")
+	}
+	out := &dto.Account{
+		AccountType:     w.AccountType,
+		CanTrade:        w.CanTrade,
+		CanWithdraw:     w.CanWithdraw,
+		CanDeposit:      w.CanDeposit,
+		MakerCommission: w.MakerCommission,
+		TakerCommission: w.TakerCommission,
+		Permissions:     w.Permissions,
+		Balances:        make([]dto.Balance, 0, len(w.Balances)),
+		UpdateTime:      w.UpdateTime,
+	}
+	for i, b := range w.Balances {
+		p := &decimalParser{}
+		balance := dto.Balance{
+			Asset:  b.Asset,
+			Free:   p.required("free", b.Free),
+			Locked: p.required("locked", b.Locked),
+		}
+		if p.err != nil {
+			return nil, fmt.Errorf("balance[%d]: %w", i, p.err)
+		}
+		out.Balances = append(out.Balances, balance)
+	}
+	return out, nil
+}
+
+type binanceOrder struct {
+	Symbol             string `json:"symbol"`
+	OrderID            int64  `json:"orderId"`
+	ClientOrderID      string `json:"clientOrderId"`
+	Price              string `json:"price"`
+	OrigQty            string `json:"origQty"`
+	ExecutedQty        string `json:"executedQty"`
+	CumulativeQuoteQty string `json:"cummulativeQuoteQty"`
+	Status             string `json:"status"`
+	TimeInForce        string `json:"timeInForce"`
+	Type               string `json:"type"`
+	Side               string `json:"side"`
+	StopPrice          string `json:"stopPrice"`
+	Time               int64  `json:"time"`
+	UpdateTime         int64  `json:"updateTime"`
+	IsWorking          bool   `json:"isWorking"`
+}
+
+type binanceMyTrade struct {
+	Symbol          string `json:"symbol"`
+	ID              int64  `json:"id"`
+	OrderID         int64  `json:"orderId"`
+	Price           string `json:"price"`
+	Qty             string `json:"qty"`
+	QuoteQty        string `json:"quoteQty"`
+	Commission      string `json:"commission"`
+	CommissionAsset string `json:"commissionAsset"`
+	Time            int64  `json:"time"`
+	IsBuyer         bool   `json:"isBuyer"`
+	IsMaker         bool   `json:"isMaker"`
+}
+
+type binanceDeposit struct {
+	ID         string `json:"id"`
+	Amount     string `json:"amount"`
+	Coin       string `json:"coin"`
+	Network    string `json:"network"`
+	Status     int    `json:"status"`
+	Address    string `json:"address"`
+	TxID       string `json:"txId"`
+	InsertTime int64  `json:"insertTime"`
+}
+
+type binanceWithdrawal struct {
+	ID             string `json:"id"`
+	Amount         string `json:"amount"`
+	TransactionFee string `json:"transactionFee"`
+	Coin           string `json:"coin"`
+	Network        string `json:"network"`
+	Status         int    `json:"status"`
+	Address        string `json:"address"`
+	TxID           string `json:"txId"`
+	ApplyTime      string `json:"applyTime"`
+	CompleteTime   string `json:"completeTime"`
+}
+
+// walletTimeLayout is the UTC layout of the wallet endpoints' time strings.
+const walletTimeLayout = "2006-01-02 15:04:05"
+
+// parseWalletTime converts a wallet time string to milliseconds; empty is zero.
+func parseWalletTime(field, value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	t, err := time.Parse(walletTimeLayout, value)
+	if err != nil {
+		return 0, fmt.Errorf("field %s: %w", field, err)
+	}
+	return t.UnixMilli(), nil
+}
+
+func parseOrders(data []byte) ([]dto.Order, error) {
+	var w []binanceOrder
+	if err := decodeStrict(data, &w); err != nil {
+		return nil, err
+	}
+	out := make([]dto.Order, 0, len(w))
+	for i, item := range w {
+		if err := requireSymbol(item.Symbol); err != nil {
+			return nil, fmt.Errorf("order[%d]: %w", i, err)
+		}
+		p := &decimalParser{}
+		order := dto.Order{
+			Symbol:             item.Symbol,
+			OrderID:            item.OrderID,
+			ClientOrderID:      item.ClientOrderID,
+			Price:              p.required("price", item.Price),
+			OrigQty:            p.required("origQty", item.OrigQty),
+			ExecutedQty:        p.required("executedQty", item.ExecutedQty),
+			CumulativeQuoteQty: p.required("cummulativeQuoteQty", item.CumulativeQuoteQty),
+			Status:             item.Status,
+			TimeInForce:        item.TimeInForce,
+			Type:               item.Type,
+			Side:               item.Side,
+			StopPrice:          p.orZero("stopPrice", item.StopPrice),
+			Time:               item.Time,
+			UpdateTime:         item.UpdateTime,
+			IsWorking:          item.IsWorking,
+		}
+		if p.err != nil {
+			return nil, fmt.Errorf("order[%d]: %w", i, p.err)
+		}
+		out = append(out, order)
+	}
+	return out, nil
+}
+
+func parseMyTrades(data []byte) ([]dto.AccountTrade, error) {
+	var w []binanceMyTrade
+	if err := decodeStrict(data, &w); err != nil {
+		return nil, err
+	}
+	out := make([]dto.AccountTrade, 0, len(w))
+	for i, item := range w {
+		p := &decimalParser{}
+		trade := dto.AccountTrade{
+			Symbol:          item.Symbol,
+			ID:              item.ID,
+			OrderID:         item.OrderID,
+			Price:           p.required("price", item.Price),
+			Qty:             p.required("qty", item.Qty),
+			QuoteQty:        p.required("quoteQty", item.QuoteQty),
+			Commission:      p.required("commission", item.Commission),
+			CommissionAsset: item.CommissionAsset,
+			Time:            item.Time,
+			IsBuyer:         item.IsBuyer,
+			IsMaker:         item.IsMaker,
+		}
+		if p.err != nil {
+			return nil, fmt.Errorf("trade[%d]: %w", i, p.err)
+		}
+		out = append(out, trade)
+	}
+	return out, nil
+}
+
+func parseDeposits(data []byte) ([]dto.Deposit, error) {
+	var w []binanceDeposit
+	if err := decodeStrict(data, &w); err != nil {
+		return nil, err
+	}
+	out := make([]dto.Deposit, 0, len(w))
+	for i, item := range w {
+		p := &decimalParser{}
+		deposit := dto.Deposit{
+			ID:         item.ID,
+			Coin:       item.Coin,
+			Network:    item.Network,
+			Amount:     p.required("amount", item.Amount),
+			Status:     item.Status,
+			Address:    item.Address,
+			TxID:       item.TxID,
+			InsertTime: item.InsertTime,
+		}
+		if p.err != nil {
+			return nil, fmt.Errorf("deposit[%d]: %w", i, p.err)
+		}
+		out = append(out, deposit)
+	}
+	return out, nil
+}
+
+func parseWithdrawals(data []byte) ([]dto.Withdrawal, error) {
+	var w []binanceWithdrawal
+	if err := decodeStrict(data, &w); err != nil {
+		return nil, err
+	}
+	out := make([]dto.Withdrawal, 0, len(w))
+	for i, item := range w {
+		p := &decimalParser{}
+		withdrawal := dto.Withdrawal{
+			ID:             item.ID,
+			Coin:           item.Coin,
+			Network:        item.Network,
+			Amount:         p.required("amount", item.Amount),
+			TransactionFee: p.orZero("transactionFee", item.TransactionFee),
+			Status:         item.Status,
+			Address:        item.Address,
+			TxID:           item.TxID,
+		}
+		if p.err != nil {
+			return nil, fmt.Errorf("withdrawal[%d]: %w", i, p.err)
+		}
+		var err error
+		if withdrawal.ApplyTime, err = parseWalletTime("applyTime", item.ApplyTime); err != nil {
+			return nil, fmt.Errorf("withdrawal[%d]: %w", i, err)
+		}
+		if withdrawal.CompleteTime, err = parseWalletTime("completeTime", item.CompleteTime); err != nil {
+			return nil, fmt.Errorf("withdrawal[%d]: %w", i, err)
+		}
+		out = append(out, withdrawal)
+	}
+	return out, nil
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ntdat104/go-finance-dataset/pkg/apikey"
+	"github.com/ntdat104/go-finance-dataset/pkg/config"
+)
+
+const (
+	// defaultRecvWindow is how long after its timestamp Binance accepts a signed request.
+	defaultRecvWindow = 5 * time.Second
+	// defaultTimeSyncInterval is how often the server clock offset is measured again.
+	defaultTimeSyncInterval = 10 * time.Minute
+	// codeInvalidTimestamp is the Binance error for a timestamp outside the recvWindow.
+	codeInvalidTimestamp = -1021
+	// headerAPIKey carries the API key of authenticated requests.
+	headerAPIKey = "X-MBX-APIKEY"
+)
+
+// ErrMissingCredentials is returned for endpoints needing an API key when none is configured.
+var ErrMissingCredentials = errors.New("binance api key not configured")
+
+// endpointSecurity is what Binance requires to call an endpoint.
+type endpointSecurity int
+
+const (
+	securityNone endpointSecurity = iota
+	// securityAPIKey endpoints need the X-MBX-APIKEY header.
+	securityAPIKey
+	// securitySigned endpoints also need a timestamp and a signature.
+	securitySigned
+)
+
+// binanceSecurity returns the security Binance requires for a spot endpoint.
+func binanceSecurity(path string) endpointSecurity {
+	switch {
+	case strings.HasSuffix(path, "/api/v3/historicalTrades"):
+		return securityAPIKey
+	case strings.HasSuffix(path, "/api/v3/account"),
+		strings.HasSuffix(path, "/api/v3/openOrders"),
+		strings.HasSuffix(path, "/api/v3/myTrades"),
+		strings.HasSuffix(path, "/sapi/v1/capital/deposit/hisrec"),
+		strings.HasSuffix(path, "/sapi/v1/capital/withdraw/history"):
+		return securitySigned
+	default:
+		return securityNone
+	}
+}
+
+// apiKeyCtxKey selects the API key of a request by name.
+type apiKeyCtxKey struct{}
+
+// withAPIKey makes authenticated requests made with ctx use the key called name.
+// An empty name uses the default key.
+func withAPIKey(ctx context.Context, name string) context.Context {
+	if name == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, apiKeyCtxKey{}, name)
+}
+
+func apiKeyName(ctx context.Context) string {
+	name, _ := ctx.Value(apiKeyCtxKey{}).(string)
+	return name
+}
+
+// binanceAuth authenticates the requests of endpoints that need an API key.
+type binanceAuth struct {
+	keys       *apikey.Store
+	recvWindow time.Duration
+	clock      *serverClock
+}
+
+// newBinanceAuth loads the configured keys. Keys whose API key expands to
+// nothing, such as an unset ${BINANCE_API_KEY}, are skipped so their endpoints
+// fail with ErrMissingCredentials instead of the service failing to start.
+func newBinanceAuth(cfg config.BinanceAuth, fetchServerTime func(ctx context.Context) (int64, error)) (*binanceAuth, error) {
+	specs := make([]apikey.Spec, 0, len(cfg.Keys))
+	for _, k := range cfg.Keys {
+		spec := apikey.Spec{
+			Name:           k.Name,
+			Type:           k.Type,
+			APIKey:         os.ExpandEnv(k.APIKey),
+			Secret:         os.ExpandEnv(k.Secret),
+			PrivateKeyPath: os.ExpandEnv(k.PrivateKeyPath),
+		}
+		if spec.APIKey == "" {
+			log.Printf("Binance api key %q has no key set, skipping it", k.Name)
+			continue
+		}
+		specs = append(specs, spec)
+	}
+	keys, err := apikey.NewStore(cfg.DefaultKey, specs...)
+	if err != nil {
+		return nil, err
+	}
+	auth := &binanceAuth{
+		keys:       keys,
+		recvWindow: cfg.RecvWindow,
+		clock:      &serverClock{fetch: fetchServerTime, syncInterval: cfg.TimeSyncInterval},
+	}
+	if auth.recvWindow <= 0 {
+		auth.recvWindow = defaultRecvWindow
+	}
+	if auth.clock.syncInterval <= 0 {
+		auth.clock.syncInterval = defaultTimeSyncInterval
+	}
+	return auth, nil
+}
+
+// authenticate sets the API key header of req and, for signed endpoints,
+// returns the query with timestamp, recvWindow and signature appended.
+func (a *binanceAuth) authenticate(ctx context.Context, req *http.Request, q url.Values, security endpointSecurity) (string, error) {
+	key, err := a.keys.Get(apiKeyName(ctx))
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrMissingCredentials, err)
+	}
+	req.Header.Set(headerAPIKey, key.APIKey)
+	if security != securitySigned {
+		return q.Encode(), nil
+	}
+
+	signed := make(url.Values, len(q)+2)
+	for k, v := range q {
+		signed[k] = v
+	}
+	signed.Set("timestamp", strconv.FormatInt(a.clock.now(ctx), 10))
+	signed.Set("recvWindow", strconv.FormatInt(a.recvWindow.Milliseconds(), 10))
+	payload := signed.Encode()
+	signature, err := key.Sign(payload)
+	if err != nil {
+		return "", fmt.Errorf("error signing request: %w", err)
+	}
+	return payload + "&signature=" + url.QueryEscape(signature), nil
+}
+
+// serverClock tracks the offset between the local clock and Binance's, so
+// signed requests carry a timestamp inside their recvWindow despite drift.
+type serverClock struct {
+	fetch        func(ctx context.Context) (int64, error)
+	syncInterval time.Duration
+
+	syncLock sync.Mutex
+	lock     sync.Mutex
+	offset   time.Duration
+	syncedAt time.Time
+}
+
+// now returns the current server time in milliseconds, measuring the offset
+// again once it is older than syncInterval. Failed measurements keep the last offset.
+func (c *serverClock) now(ctx context.Context) int64 {
+	c.lock.Lock()
+	due := time.Since(c.syncedAt) >= c.syncInterval
+	c.lock.Unlock()
+	if due {
+		if err := c.sync(ctx); err != nil {
+			log.Printf("Failed to sync Binance server time: %v", err)
+		}
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return time.Now().Add(c.offset).UnixMilli()
+}
+
+// sync measures the offset, taking the local time halfway through the round trip.
+func (c *serverClock) sync(ctx context.Context) error {
+	c.syncLock.Lock()
+	defer c.syncLock.Unlock()
+	c.lock.Lock()
+	fresh := time.Since(c.syncedAt) < c.syncInterval
+	c.lock.Unlock()
+	if fresh {
+		return nil
+	}
+
+	start := time.Now()
+	serverTime, err := c.fetch(ctx)
+	if err != nil {
+		return err
+	}
+	end := time.Now()
+	local := start.Add(end.Sub(start) / 2)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.offset = time.UnixMilli(serverTime).Sub(local)
+	c.syncedAt = end
+	return nil
+}
+
+// reset forces the next timestamp to measure the offset again.
+func (c *serverClock) reset() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.syncedAt = time.Time{}
+}
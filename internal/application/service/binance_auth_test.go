@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ntdat104/go-finance-dataset/pkg/config"
+)
+
+const testSecret = "secret"
+
+func newTestAuth(t *testing.T, fetch func(context.Context) (int64, error)) *binanceAuth {
+	t.Helper()
+	auth, err := newBinanceAuth(config.BinanceAuth{
+		Keys: []config.APIKey{
+			{Name: "main", Type: "hmac", APIKey: "main-key", Secret: testSecret},
+			{Name: "other", Type: "hmac", APIKey: "other-key", Secret: testSecret},
+			{Name: "unset", Type: "hmac", APIKey: "${UNSET_TEST_BINANCE_KEY}", Secret: testSecret},
+		},
+	}, fetch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return auth
+}
+
+func TestBinanceSecurity(t *testing.T) {
+	tests := map[string]endpointSecurity{
+		"/api/v3/klines":           securityNone,
+		"/api/v3/historicalTrades": securityAPIKey,
+		"/api/v3/account":          securitySigned,
+	}
+	for path, want := range tests {
+		if got := binanceSecurity(path); got != want {
+			t.Errorf("binanceSecurity(%s) = %d, want %d", path, got, want)
+		}
+	}
+}
+
+func TestBinanceAuthenticate(t *testing.T) {
+	serverTime := time.Now().Add(3 * time.Second).UnixMilli()
+	auth := newTestAuth(t, func(context.Context) (int64, error) { return serverTime, nil })
+	q := url.Values{"symbol": {"BTCUSDT"}}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v3/historicalTrades", nil)
+	query, err := auth.authenticate(withAPIKey(context.Background(), "other"), req, q, securityAPIKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "symbol=BTCUSDT" || req.Header.Get(headerAPIKey) != "other-key" {
+		t.Errorf("api key request: query %q, key %q", query, req.Header.Get(headerAPIKey))
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v3/account", nil)
+	query, err = auth.authenticate(context.Background(), req, q, securitySigned)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Header.Get(headerAPIKey) != "main-key" {
+		t.Errorf("signed request used key %q, want the default", req.Header.Get(headerAPIKey))
+	}
+	payload, signature, ok := strings.Cut(query, "&signature=")
+	if !ok {
+		t.Fatalf("query %q has no signature", query)
+	}
+	mac := hmac.New(sha256.New, []byte(testSecret))
+	mac.Write([]byte(payload))
+	if want := hex.EncodeToString(mac.Sum(nil)); signature != want {
+		t.Errorf("signature = %s, want %s", signature, want)
+	}
+	values, _ := url.ParseQuery(payload)
+	if values.Get("symbol") != "BTCUSDT" || values.Get("recvWindow") != "5000" {
+		t.Errorf("signed payload = %q", payload)
+	}
+	// The timestamp follows the server clock, not the local one.
+	timestamp, _ := strconv.ParseInt(values.Get("timestamp"), 10, 64)
+	if drift := timestamp - serverTime; drift < 0 || drift > 1000 {
+		t.Errorf("timestamp is %dms off the server time", drift)
+	}
+	if q.Has("timestamp") {
+		t.Error("authenticate modified the caller's query")
+	}
+
+	_, err = auth.authenticate(withAPIKey(context.Background(), "unset"), req, q, securitySigned)
+	if !errors.Is(err, ErrMissingCredentials) {
+		t.Errorf("unset key: err = %v, want ErrMissingCredentials", err)
+	}
+}
+
+func TestServerClockSync(t *testing.T) {
+	var fetches atomic.Int32
+	clock := &serverClock{
+		fetch: func(context.Context) (int64, error) {
+			fetches.Add(1)
+			return time.Now().Add(-time.Hour).UnixMilli(), nil
+		},
+		syncInterval: time.Hour,
+	}
+	ctx := context.Background()
+	if offset := time.Since(time.UnixMilli(clock.now(ctx))); offset < 59*time.Minute {
+		t.Errorf("now is %v behind, want about an hour", offset)
+	}
+	clock.now(ctx)
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("fetched the server time %d times within the sync interval, want 1", n)
+	}
+	clock.reset()
+	clock.now(ctx)
+	if n := fetches.Load(); n != 2 {
+		t.Errorf("fetched the server time %d times after reset, want 2", n)
+	}
+
+	// A failed sync keeps the last offset.
+	clock.fetch = func(context.Context) (int64, error) { return 0, errors.New("down") }
+	clock.reset()
+	if offset := time.Since(time.UnixMilli(clock.now(ctx))); offset < 59*time.Minute {
+		t.Errorf("after a failed sync now is %v behind, want the old offset", offset)
+	}
+}
+
+func TestFetchRetriesInvalidTimestamp(t *testing.T) {
+	var serverTimes, requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Query().Get("signature") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":-1100,"msg":"Illegal characters found in parameter 'symbol'."}`))
+			return
+		}
+		// The first sync leaves the clock an hour behind, so only the
+		// request signed after the resync is inside the recvWindow.
+		timestamp, _ := strconv.ParseInt(r.URL.Query().Get("timestamp"), 10, 64)
+		if time.Since(time.UnixMilli(timestamp)) > time.Minute {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":-1021,"msg":"Timestamp for this request is outside of the recvWindow."}`))
+			return
+		}
+		w.Write([]byte(`{"balances":[]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	c := newRestClient("spot", nil, server.Client(), NewLocalCacheSvc(0, 0), config.Cache{})
+	c.auth = newTestAuth(t, func(context.Context) (int64, error) {
+		if serverTimes.Add(1) == 1 {
+			return time.Now().Add(-time.Hour).UnixMilli(), nil
+		}
+		return time.Now().UnixMilli(), nil
+	})
+
+	body, err := c.fetchData(context.Background(), server.URL+"/api/v3/account", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != `{"balances":[]}` {
+		t.Errorf("body = %s", body)
+	}
+	if requests.Load() != 2 || serverTimes.Load() != 2 {
+		t.Errorf("%d requests and %d server time syncs, want 2 of each", requests.Load(), serverTimes.Load())
+	}
+
+	// Other API errors are not retried.
+	requests.Store(0)
+	_, err = c.fetchData(context.Background(), server.URL+"/api/v3/klines", nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest || requests.Load() != 1 {
+		t.Errorf("unsigned failure: err = %v after %d requests", err, requests.Load())
+	}
+}
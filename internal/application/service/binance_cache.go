@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ntdat104/go-finance-dataset/internal/application/dto"
+)
+
+// Cache Introspection
+
+// CacheStats reports how the market data cache is doing.
+func (s *binanceSvc) CacheStats() dto.CacheStats {
+	return dto.CacheStats{
+		Local:        s.localCacheSvc.Stats(),
+		Singleflight: s.flights.Stats(),
+		Refresh:      s.refreshPool.Stats(),
+	}
+}
+
+// CacheEntries describes up to limit cached keys starting with prefix.
+func (s *binanceSvc) CacheEntries(prefix string, limit int) []dto.CacheEntry {
+	keys := s.localCacheSvc.Keys(prefix)
+	if limit > 0 && len(keys) > limit {
+		keys = keys[:limit]
+	}
+	entries := make([]dto.CacheEntry, 0, len(keys))
+	for _, key := range keys {
+		if entry, ok := s.describeCacheEntry(key); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// CacheEntry describes one cached key along with its value.
+func (s *binanceSvc) CacheEntry(key string) (*dto.CacheEntry, bool) {
+	entry, ok := s.describeCacheEntry(key)
+	if !ok {
+		return nil, false
+	}
+	value, ok := s.localCacheSvc.Peek(key)
+	if !ok {
+		return nil, false
+	}
+	if cached, ok := value.(cachedValue); ok {
+		value = cached.data
+	}
+	entry.Value = value
+	return &entry, true
+}
+
+func (s *binanceSvc) describeCacheEntry(key string) (dto.CacheEntry, bool) {
+	expireTime, ok := s.localCacheSvc.GetExpireTime(key)
+	if !ok {
+		return dto.CacheEntry{}, false
+	}
+	entry := dto.CacheEntry{
+		Key:       key,
+		ExpiresAt: expireTime.UnixMilli(),
+	}
+	if value, ok := s.localCacheSvc.Peek(key); ok {
+		if cached, ok := value.(cachedValue); ok {
+			entry.FetchedAt = cached.fetchedAt.UnixMilli()
+			entry.AgeMs = time.Since(cached.fetchedAt).Milliseconds()
+		}
+	}
+	return entry, true
+}
+
+// InvalidateCache deletes key, or every key starting with prefix, and returns
+// how many entries were removed. Refresh delay keys go with their entries but
+// are not counted.
+func (s *binanceSvc) InvalidateCache(key, prefix string) int {
+	if prefix != "" {
+		deleted := 0
+		for _, k := range s.localCacheSvc.Keys(prefix) {
+			if !strings.HasSuffix(k, ":delay") {
+				deleted++
+			}
+		}
+		s.localCacheSvc.DelPrefix(prefix)
+		return deleted
+	}
+	deleted := 0
+	if _, ok := s.localCacheSvc.Peek(key); ok {
+		deleted++
+	}
+	s.localCacheSvc.Del(key)
+	s.localCacheSvc.Del(key + ":delay")
+	return deleted
+}
+
+// WarmCache fetches the described request from upstream into the cache and
+// returns its key. The cached entry is only replaced once the fetch succeeds.
+func (s *binanceSvc) WarmCache(ctx context.Context, item dto.CacheWarmItem) (string, error) {
+	symbol, interval, limit := item.Symbol, item.Interval, item.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	keySuffix := symbol
+	params := map[string]string{"symbol": symbol}
+	var load func(key, delayKey string, policy cachePolicy) error
+	switch item.Cache {
+	case "exchangeinfo":
+		keySuffix, params = "global", nil
+		load = warmWith(ctx, s.restClient, s.baseURL+"/api/v3/exchangeInfo", params, parseExchangeInfo)
+	case "alltickerprices":
+		keySuffix, params = "global", nil
+		load = warmWith(ctx, s.restClient, s.baseURL+"/api/v3/ticker/price", params, parseTickerPrices)
+	case "allbooktickers":
+		keySuffix, params = "global", nil
+		load = warmWith(ctx, s.restClient, s.baseURL+"/api/v3/ticker/bookTicker", params, parseBookTickers)
+	case "tickerprice":
+		load = warmWith(ctx, s.restClient, s.baseURL+"/api/v3/ticker/price", params, parseTickerPrice)
+	case "bookticker":
+		load = warmWith(ctx, s.restClient, s.baseURL+"/api/v3/ticker/bookTicker", params, parseBookTicker)
+	case "avgprice":
+		load = warmWith(ctx, s.restClient, s.baseURL+"/api/v3/avgPrice", params, parseAvgPrice)
+	case "ticker24hr":
+		load = warmWith(ctx, s.restClient, s.baseURL+"/api/v3/ticker/24hr", params, parseTicker24h)
+	case "depth":
+		keySuffix = fmt.Sprintf("%s-%d", symbol, limit)
+		params["limit"] = strconv.Itoa(limit)
+		load = warmWith(ctx, s.restClient, s.baseURL+"/api/v3/depth", params, parseDepth)
+	case "recenttrades":
+		keySuffix = fmt.Sprintf("%s-%d", symbol, limit)
+		params["limit"] = strconv.Itoa(limit)
+		load = warmWith(ctx, s.restClient, s.baseURL+"/api/v3/trades", params, parseTrades)
+	case "klines":
+		if err := ValidateInterval(interval); err != nil {
+			return "", err
+		}
+		keySuffix = fmt.Sprintf("%s-%s-%d", symbol, interval, limit)
+		params["interval"] = interval
+		params["limit"] = strconv.Itoa(limit)
+		load = warmWith(ctx, s.restClient, s.baseURL+"/api/v3/klines", params, parseKlines)
+	default:
+		return "", fmt.Errorf("cache %q cannot be warmed", item.Cache)
+	}
+	if keySuffix != "global" {
+		if err := requireSymbol(symbol); err != nil {
+			return "", err
+		}
+	}
+
+	key, delayKey := s.cacheKeys(item.Cache, keySuffix)
+	return key, load(key, delayKey, s.policies.get(item.Cache, params["interval"]))
+}
+
+// warmWith returns a loader that fetches straight from upstream, bypassing the
+// cache as well as the trade stream and kline store, and caches the result.
+func warmWith[T any](ctx context.Context, c *restClient, apiURL string, params map[string]string, parse func([]byte) (T, error)) func(key, delayKey string, policy cachePolicy) error {
+	return func(key, delayKey string, policy cachePolicy) error {
+		_, err := fetchAndCache(ctx, c, key, delayKey, apiURL, params, policy, parse)
+		return err
+	}
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ntdat104/go-finance-dataset/internal/application/dto"
+	"github.com/ntdat104/go-finance-dataset/pkg/config"
+	"github.com/ntdat104/go-finance-dataset/pkg/datasource"
+)
+
+// priceServer answers /api/v3/ticker/price with price, or with a 500 while failing is set.
+func priceServer(t *testing.T, price *atomic.Value, failing *atomic.Bool) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			http.Error(w, "unavailable", http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprintf(w, `{"symbol":%q,"price":%q}`, r.URL.Query().Get("symbol"), price.Load())
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func cachedPrice(t *testing.T, svc *binanceSvc, key string) string {
+	t.Helper()
+	entry, ok := svc.CacheEntry(key)
+	if !ok {
+		t.Fatalf("%s is not cached", key)
+	}
+	return entry.Value.(*dto.TickerPrice).Price.String()
+}
+
+func TestWarmCache(t *testing.T) {
+	var price atomic.Value
+	var failing atomic.Bool
+	price.Store("100")
+	svc := newTestBinanceSvc(t, priceServer(t, &price, &failing).URL)
+	ctx := context.Background()
+	item := dto.CacheWarmItem{Cache: "tickerprice", Symbol: "BTCUSDT"}
+
+	key, err := svc.WarmCache(ctx, item)
+	if err != nil || key != "spot_tickerprice:BTCUSDT" {
+		t.Fatalf("WarmCache = %q, %v", key, err)
+	}
+	if got := cachedPrice(t, svc, key); got != "100" {
+		t.Fatalf("cached price = %s, want 100", got)
+	}
+
+	// A failed warm leaves the cached entry alone.
+	failing.Store(true)
+	if _, err := svc.WarmCache(ctx, item); err == nil {
+		t.Fatal("WarmCache succeeded against a failing upstream")
+	}
+	if got := cachedPrice(t, svc, key); got != "100" {
+		t.Fatalf("cached price = %s after a failed warm, want 100", got)
+	}
+
+	// A fresh entry is still refetched.
+	failing.Store(false)
+	price.Store("101")
+	if _, err := svc.WarmCache(ctx, item); err != nil {
+		t.Fatal(err)
+	}
+	if got := cachedPrice(t, svc, key); got != "101" {
+		t.Fatalf("cached price = %s, want the warmed 101", got)
+	}
+
+	for _, bad := range []dto.CacheWarmItem{
+		{Cache: "unknown", Symbol: "BTCUSDT"},
+		{Cache: "tickerprice"},
+		{Cache: "klines", Symbol: "BTCUSDT", Interval: "2m"},
+	} {
+		if _, err := svc.WarmCache(ctx, bad); err == nil {
+			t.Errorf("WarmCache(%+v) succeeded", bad)
+		}
+	}
+}
+
+func TestInvalidateCache(t *testing.T) {
+	var price atomic.Value
+	var failing atomic.Bool
+	price.Store("100")
+	svc := newTestBinanceSvc(t, priceServer(t, &price, &failing).URL)
+	ctx := context.Background()
+	warm := func(symbols ...string) {
+		t.Helper()
+		for _, symbol := range symbols {
+			if _, err := svc.WarmCache(ctx, dto.CacheWarmItem{Cache: "tickerprice", Symbol: symbol}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// Each entry also has a refresh delay key, which is not counted.
+	warm("BTCUSDT", "ETHUSDT")
+	if n := svc.InvalidateCache("", "spot_tickerprice:"); n != 2 {
+		t.Errorf("InvalidateCache by prefix = %d, want 2", n)
+	}
+	if keys := svc.localCacheSvc.Keys("spot_tickerprice:"); len(keys) != 0 {
+		t.Errorf("keys left = %v", keys)
+	}
+
+	warm("BTCUSDT")
+	if n := svc.InvalidateCache("spot_tickerprice:BTCUSDT", ""); n != 1 {
+		t.Errorf("InvalidateCache by key = %d, want 1", n)
+	}
+	if n := svc.InvalidateCache("spot_tickerprice:BTCUSDT", ""); n != 0 {
+		t.Errorf("InvalidateCache of a missing key = %d, want 0", n)
+	}
+	if keys := svc.localCacheSvc.Keys("spot_tickerprice:"); len(keys) != 0 {
+		t.Errorf("keys left = %v", keys)
+	}
+}
+
+func TestServeStaleOnError(t *testing.T) {
+	var price atomic.Value
+	var failing atomic.Bool
+	price.Store("100")
+	svc := newTestBinanceSvc(t, priceServer(t, &price, &failing).URL)
+	serveStale := true
+	svc.policies = newCachePolicies(config.Cache{Default: config.CachePolicy{
+		TTL:               time.Minute,
+		RefreshInterval:   time.Hour,
+		MaxStaleness:      time.Minute,
+		ServeStaleOnError: &serveStale,
+	}})
+	const key = "spot_tickerprice:BTCUSDT"
+
+	// get fetches the price and reports where the data came from.
+	get := func() (string, string, time.Time, error) {
+		t.Helper()
+		ctx, info := datasource.NewContext(context.Background())
+		ticker, err := svc.GetTickerPrice(ctx, "BTCUSDT")
+		if err != nil {
+			return "", "", time.Time{}, err
+		}
+		source, fetchedAt, _ := info.Get()
+		return ticker.Price.String(), source, fetchedAt, nil
+	}
+	// backdate makes the cached entry fetchedAt old.
+	backdate := func(age time.Duration) time.Time {
+		t.Helper()
+		value, ok := svc.localCacheSvc.Peek(key)
+		if !ok {
+			t.Fatalf("%s is not cached", key)
+		}
+		entry := value.(cachedValue)
+		entry.fetchedAt = time.Now().Add(-age)
+		svc.localCacheSvc.Set(key, entry, time.Hour)
+		return entry.fetchedAt
+	}
+
+	if got, source, _, err := get(); err != nil || got != "100" || source != datasource.Upstream {
+		t.Fatalf("first get = %s from %q, %v", got, source, err)
+	}
+	fetchedAt := backdate(time.Second)
+	if got, source, at, err := get(); err != nil || got != "100" || source != datasource.Cache || !at.Equal(fetchedAt) {
+		t.Fatalf("cached get = %s from %q at %v, %v", got, source, at, err)
+	}
+
+	// Past the ttl a failed fetch serves the old entry, for at most max staleness.
+	failing.Store(true)
+	fetchedAt = backdate(90 * time.Second)
+	if got, source, at, err := get(); err != nil || got != "100" || source != datasource.Stale || !at.Equal(fetchedAt) {
+		t.Fatalf("stale get = %s from %q at %v, %v", got, source, at, err)
+	}
+	backdate(3 * time.Minute)
+	if _, _, _, err := get(); err == nil {
+		t.Fatal("served data older than max staleness")
+	}
+
+	// Without serve stale on error the failure goes through.
+	serveStale = false
+	svc.policies = newCachePolicies(config.Cache{Default: config.CachePolicy{TTL: time.Minute, ServeStaleOnError: &serveStale}})
+	backdate(90 * time.Second)
+	if _, _, _, err := get(); err == nil {
+		t.Fatal("served stale data with serve stale on error off")
+	}
+
+	// Once upstream is back the entry is refetched.
+	failing.Store(false)
+	price.Store("101")
+	if got, source, _, err := get(); err != nil || got != "101" || source != datasource.Upstream {
+		t.Fatalf("get after recovery = %s from %q, %v", got, source, err)
+	}
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ntdat104/go-finance-dataset/internal/application/dto"
+	"github.com/ntdat104/go-finance-dataset/pkg/config"
+	"github.com/ntdat104/go-finance-dataset/pkg/httpclient"
+)
+
+// Futures markets used in the /api/v1/crypto/futures/{market}/... routes. They
+// double as the cache namespaces, next to "spot".
+const (
+	FuturesUSDM  = "usdm"
+	FuturesCOINM = "coinm"
+)
+
+// Long/short ratio kinds accepted by GetLongShortRatio.
+const (
+	LongShortGlobalAccount = "global"
+	LongShortTopAccount    = "topAccount"
+	LongShortTopPosition   = "topPosition"
+)
+
+const (
+	// defaultFundingInterval is how often perpetuals settle funding unless GetFundingInfo lists them.
+	defaultFundingInterval = 8 * time.Hour
+	// fundingRatePageLimit is the maximum number of fundings Binance returns per request.
+	fundingRatePageLimit = 1000
+	// futuresKlinesPageLimit is the maximum number of candles the futures kline endpoints return.
+	futuresKlinesPageLimit = 1500
+	// longShortRatioPageLimit is the maximum number of periods the ratio endpoints return.
+	longShortRatioPageLimit = 500
+)
+
+var (
+	ErrUnknownFuturesMarket    = errors.New("unknown futures market")
+	ErrUnsupportedPeriod       = errors.New("unsupported period")
+	ErrUnsupportedContractType = errors.New("unsupported contract type")
+	ErrUnsupportedRatio        = errors.New("unsupported long/short ratio")
+)
+
+// futuresMarket holds what differs between the USD-M and COIN-M APIs.
+type futuresMarket struct {
+	baseURL   string
+	apiPrefix string
+	// ratioParam is the query parameter naming the contract on /futures/data endpoints.
+	ratioParam string
+}
+
+var futuresMarkets = map[string]futuresMarket{
+	FuturesUSDM:  {baseURL: "https://fapi.binance.com", apiPrefix: "/fapi/v1", ratioParam: "symbol"},
+	FuturesCOINM: {baseURL: "https://dapi.binance.com", apiPrefix: "/dapi/v1", ratioParam: "pair"},
+}
+
+var longShortRatioPaths = map[string]string{
+	LongShortGlobalAccount: "/futures/data/globalLongShortAccountRatio",
+	LongShortTopAccount:    "/futures/data/topLongShortAccountRatio",
+	LongShortTopPosition:   "/futures/data/topLongShortPositionRatio",
+}
+
+// futuresPeriods are the periods of the /futures/data statistics.
+var futuresPeriods = map[string]bool{
+	"5m": true, "15m": true, "30m": true, "1h": true, "2h": true,
+	"4h": true, "6h": true, "12h": true, "1d": true,
+}
+
+var contractTypes = map[string]bool{
+	"PERPETUAL":       true,
+	"CURRENT_QUARTER": true,
+	"NEXT_QUARTER":    true,
+}
+
+// BinanceFuturesSvc serves the derivatives market data of one Binance futures
+// market. COIN-M contracts are named like BTCUSD_PERP, their pairs like BTCUSD.
+type BinanceFuturesSvc interface {
+	Market() string
+	GetMarkPrice(ctx context.Context, symbol string) ([]dto.MarkPrice, error)
+	GetFundingRateHistory(ctx context.Context, symbol string, startTime, endTime *int64, limit int) ([]dto.FundingRate, error)
+	GetFundingInfo(ctx context.Context) ([]dto.FundingInfo, error)
+	GetOpenInterest(ctx context.Context, symbol string) (*dto.OpenInterest, error)
+	GetLongShortRatio(ctx context.Context, kind, symbol, period string, limit int) ([]dto.LongShortRatio, error)
+	GetPremiumIndexKlines(ctx context.Context, symbol, interval string, limit int) ([]dto.Kline, error)
+	GetContinuousKlines(ctx context.Context, pair, contractType, interval string, limit int) ([]dto.Kline, error)
+}
+
+type binanceFuturesSvc struct {
+	*restClient
+	market string
+	futuresMarket
+}
+
+// NewBinanceFuturesSvc creates the service of market (usdm or coinm). baseURLs
+// are failed over like the spot ones; an empty list defaults to the production
+// host. Its cache keys are namespaced by market.
+func NewBinanceFuturesSvc(market string, baseURLs []string, unhealthyCooldown time.Duration, rateLimiterSvc RateLimiterSvc, httpClient httpclient.Client, localCacheSvc LocalCacheSvc, cacheCfg config.Cache) (BinanceFuturesSvc, error) {
+	m, ok := futuresMarkets[market]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownFuturesMarket, market)
+	}
+	baseURLs = trimBaseURLs(baseURLs, m.baseURL)
+	m.baseURL = baseURLs[0]
+	s := &binanceFuturesSvc{
+		restClient:    newRestClient(market, rateLimiterSvc, httpClient, localCacheSvc, cacheCfg),
+		market:        market,
+		futuresMarket: m,
+	}
+	s.hosts = newHostPool(baseURLs, unhealthyCooldown)
+	return s, nil
+}
+
+func (s *binanceFuturesSvc) Market() string {
+	return s.market
+}
+
+func (s *binanceFuturesSvc) apiURL(endpoint string) string {
+	return s.baseURL + s.apiPrefix + endpoint
+}
+
+// GetMarkPrice returns the mark price and funding of symbol, or of every
+// contract when symbol is empty. COIN-M also accepts a pair, returning all its contracts.
+func (s *binanceFuturesSvc) GetMarkPrice(ctx context.Context, symbol string) ([]dto.MarkPrice, error) {
+	params := map[string]string{}
+	keySuffix := "all"
+	switch {
+	case symbol == "":
+	case s.market == FuturesCOINM && !strings.Contains(symbol, "_"):
+		params["pair"] = symbol
+		keySuffix = symbol
+	default:
+		params["symbol"] = symbol
+		keySuffix = symbol
+	}
+	return getWithCache(ctx, s.restClient, "markprice", keySuffix, s.apiURL("/premiumIndex"), params, parseMarkPrices)
+}
+
+// GetFundingRateHistory returns the settled fundings of a perpetual, oldest first.
+func (s *binanceFuturesSvc) GetFundingRateHistory(ctx context.Context, symbol string, startTime, endTime *int64, limit int) ([]dto.FundingRate, error) {
+	limit = min(limit, fundingRatePageLimit)
+	params := map[string]string{
+		"symbol": symbol,
+		"limit":  fmt.Sprintf("%d", limit),
+	}
+	keySuffix := fmt.Sprintf("%s-%d", symbol, limit)
+	if startTime != nil {
+		params["startTime"] = fmt.Sprintf("%d", *startTime)
+		keySuffix += fmt.Sprintf("-s%d", *startTime)
+	}
+	if endTime != nil {
+		params["endTime"] = fmt.Sprintf("%d", *endTime)
+		keySuffix += fmt.Sprintf("-e%d", *endTime)
+	}
+	return getWithCache(ctx, s.restClient, "fundingrate", keySuffix, s.apiURL("/fundingRate"), params, parseFundingRates)
+}
+
+// GetFundingInfo lists the perpetuals with a non-default funding interval or
+// rate bounds. COIN-M perpetuals all settle every 8 hours, so it is empty there.
+func (s *binanceFuturesSvc) GetFundingInfo(ctx context.Context) ([]dto.FundingInfo, error) {
+	if s.market != FuturesUSDM {
+		return []dto.FundingInfo{}, nil
+	}
+	return getWithCache(ctx, s.restClient, "fundinginfo", "global", s.apiURL("/fundingInfo"), nil, parseFundingInfo)
+}
+
+// GetOpenInterest returns the current open interest of a contract.
+func (s *binanceFuturesSvc) GetOpenInterest(ctx context.Context, symbol string) (*dto.OpenInterest, error) {
+	params := map[string]string{"symbol": symbol}
+	return getWithCache(ctx, s.restClient, "openinterest", symbol, s.apiURL("/openInterest"), params, parseOpenInterest)
+}
+
+// GetLongShortRatio returns the long/short ratio of kind over the latest limit
+// periods, oldest first. On COIN-M symbol is the pair (BTCUSD).
+func (s *binanceFuturesSvc) GetLongShortRatio(ctx context.Context, kind, symbol, period string, limit int) ([]dto.LongShortRatio, error) {
+	path, ok := longShortRatioPaths[kind]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnsupportedRatio, kind)
+	}
+	if !futuresPeriods[period] {
+		return nil, fmt.Errorf("%w %q", ErrUnsupportedPeriod, period)
+	}
+	limit = min(limit, longShortRatioPageLimit)
+	params := map[string]string{
+		s.ratioParam: symbol,
+		"period":     period,
+		"limit":      fmt.Sprintf("%d", limit),
+	}
+	keySuffix := fmt.Sprintf("%s-%s-%s-%d", kind, symbol, period, limit)
+	return getWithCache(ctx, s.restClient, "longshortratio", keySuffix, s.baseURL+path, params, parseLongShortRatios)
+}
+
+// GetPremiumIndexKlines returns the latest candles of the premium index of symbol.
+// Only the prices are set; the volume fields are zero.
+func (s *binanceFuturesSvc) GetPremiumIndexKlines(ctx context.Context, symbol, interval string, limit int) ([]dto.Kline, error) {
+	if err := validateFuturesInterval(interval); err != nil {
+		return nil, err
+	}
+	limit = min(limit, futuresKlinesPageLimit)
+	params := map[string]string{
+		"symbol":   symbol,
+		"interval": interval,
+		"limit":    fmt.Sprintf("%d", limit),
+	}
+	return getWithCache(ctx, s.restClient, "premiumindexklines", fmt.Sprintf("%s-%s-%d", symbol, interval, limit), s.apiURL("/premiumIndexKlines"), params, parseKlines)
+}
+
+// GetContinuousKlines returns the latest candles of the contract of pair with
+// contractType (PERPETUAL, CURRENT_QUARTER or NEXT_QUARTER), rolling over across expiries.
+func (s *binanceFuturesSvc) GetContinuousKlines(ctx context.Context, pair, contractType, interval string, limit int) ([]dto.Kline, error) {
+	contractType = strings.ToUpper(contractType)
+	if !contractTypes[contractType] {
+		return nil, fmt.Errorf("%w %q", ErrUnsupportedContractType, contractType)
+	}
+	if err := validateFuturesInterval(interval); err != nil {
+		return nil, err
+	}
+	limit = min(limit, futuresKlinesPageLimit)
+	params := map[string]string{
+		"pair":         pair,
+		"contractType": contractType,
+		"interval":     interval,
+		"limit":        fmt.Sprintf("%d", limit),
+	}
+	keySuffix := fmt.Sprintf("%s-%s-%s-%d", pair, contractType, interval, limit)
+	return getWithCache(ctx, s.restClient, "continuousklines", keySuffix, s.apiURL("/continuousKlines"), params, parseKlines)
+}
+
+// validateFuturesInterval accepts the spot kline intervals except 1s, which futures lack.
+func validateFuturesInterval(interval string) error {
+	if interval == "1s" || ValidateInterval(interval) != nil {
+		return fmt.Errorf("%w %q", ErrUnsupportedInterval, interval)
+	}
+	return nil
+}
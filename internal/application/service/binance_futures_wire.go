@@ -0,0 +1,225 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ntdat104/go-finance-dataset/internal/application/dto"
+	"github.com/ntdat104/go-finance-dataset/pkg/decimal"
+)
+
+// Wire types mirror the raw Binance USD-M (fapi) and COIN-M (dapi) futures
+// payloads. COIN-M responses name the pair where USD-M ones name the symbol.
+
+type binanceMarkPrice struct {
+	Symbol               string `json:"symbol"`
+	Pair                 string `json:"pair"`
+	MarkPrice            string `json:"markPrice"`
+	IndexPrice           string `json:"indexPrice"`
+	EstimatedSettlePrice string `json:"estimatedSettlePrice"`
+	LastFundingRate      string `json:"lastFundingRate"`
+	InterestRate         string `json:"interestRate"`
+	NextFundingTime      int64  `json:"nextFundingTime"`
+	Time                 int64  `json:"time"`
+}
+
+type binanceFundingRate struct {
+	Symbol      string `json:"symbol"`
+	FundingRate string `json:"fundingRate"`
+	FundingTime int64  `json:"fundingTime"`
+	MarkPrice   string `json:"markPrice"`
+}
+
+type binanceOpenInterest struct {
+	Symbol       string `json:"symbol"`
+	Pair         string `json:"pair"`
+	ContractType string `json:"contractType"`
+	OpenInterest string `json:"openInterest"`
+	Time         int64  `json:"time"`
+}
+
+type binanceFundingInfo struct {
+	Symbol                   string `json:"symbol"`
+	AdjustedFundingRateCap   string `json:"adjustedFundingRateCap"`
+	AdjustedFundingRateFloor string `json:"adjustedFundingRateFloor"`
+	FundingIntervalHours     int    `json:"fundingIntervalHours"`
+}
+
+// binanceLongShortRatio covers the account and position ratio endpoints; the
+// COIN-M position ratio reports longPosition/shortPosition instead of accounts.
+// The timestamp is a number or a numeric string depending on the endpoint.
+type binanceLongShortRatio struct {
+	Symbol         string      `json:"symbol"`
+	Pair           string      `json:"pair"`
+	LongShortRatio string      `json:"longShortRatio"`
+	LongAccount    string      `json:"longAccount"`
+	ShortAccount   string      `json:"shortAccount"`
+	LongPosition   string      `json:"longPosition"`
+	ShortPosition  string      `json:"shortPosition"`
+	Timestamp      json.Number `json:"timestamp"`
+}
+
+// orZero parses a field that delivery contracts leave empty, such as the funding rate.
+func (p *decimalParser) orZero(field, value string) decimal.Decimal {
+	if value == "" {
+		return decimal.Zero
+	}
+	return p.required(field, value)
+}
+
+func (w binanceMarkPrice) toDTO() (dto.MarkPrice, error) {
+	if err := requireSymbol(w.Symbol); err != nil {
+		return dto.MarkPrice{}, err
+	}
+	p := &decimalParser{}
+	out := dto.MarkPrice{
+		Symbol:               w.Symbol,
+		Pair:                 w.Pair,
+		MarkPrice:            p.required("markPrice", w.MarkPrice),
+		IndexPrice:           p.required("indexPrice", w.IndexPrice),
+		EstimatedSettlePrice: p.orZero("estimatedSettlePrice", w.EstimatedSettlePrice),
+		LastFundingRate:      p.orZero("lastFundingRate", w.LastFundingRate),
+		InterestRate:         p.orZero("interestRate", w.InterestRate),
+		NextFundingTime:      w.NextFundingTime,
+		Time:                 w.Time,
+	}
+	return out, p.err
+}
+
+// parseMarkPrices accepts both the single object USD-M returns for one symbol
+// and the list returned for all symbols and by COIN-M.
+func parseMarkPrices(data []byte) ([]dto.MarkPrice, error) {
+	var w []binanceMarkPrice
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var single binanceMarkPrice
+		if err := decodeStrict(data, &single); err != nil {
+			return nil, err
+		}
+		w = append(w, single)
+	} else if err := decodeStrict(data, &w); err != nil {
+		return nil, err
+	}
+	out := make([]dto.MarkPrice, 0, len(w))
+	for i, item := range w {
+		m, err := item.toDTO()
+		if err != nil {
+			return nil, fmt.Errorf("markPrice[%d]: %w", i, err)
+		}
+		out = append(out, m)
+	}
+	return out, nil
+}
+
+func parseFundingRates(data []byte) ([]dto.FundingRate, error) {
+	var w []binanceFundingRate
+	if err := decodeStrict(data, &w); err != nil {
+		return nil, err
+	}
+	out := make([]dto.FundingRate, 0, len(w))
+	for i, item := range w {
+		if err := requireSymbol(item.Symbol); err != nil {
+			return nil, fmt.Errorf("fundingRate[%d]: %w", i, err)
+		}
+		p := &decimalParser{}
+		f := dto.FundingRate{
+			Symbol:      item.Symbol,
+			FundingRate: p.required("fundingRate", item.FundingRate),
+			FundingTime: item.FundingTime,
+			MarkPrice:   p.optional("markPrice", item.MarkPrice),
+		}
+		if p.err != nil {
+			return nil, fmt.Errorf("fundingRate[%d]: %w", i, p.err)
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+func parseFundingInfo(data []byte) ([]dto.FundingInfo, error) {
+	var w []binanceFundingInfo
+	if err := decodeStrict(data, &w); err != nil {
+		return nil, err
+	}
+	out := make([]dto.FundingInfo, 0, len(w))
+	for i, item := range w {
+		if err := requireSymbol(item.Symbol); err != nil {
+			return nil, fmt.Errorf("fundingInfo[%d]: %w", i, err)
+		}
+		if item.FundingIntervalHours <= 0 {
+			return nil, fmt.Errorf("fundingInfo[%d]: field fundingIntervalHours: invalid value %d", i, item.FundingIntervalHours)
+		}
+		p := &decimalParser{}
+		f := dto.FundingInfo{
+			Symbol:                   item.Symbol,
+			FundingIntervalHours:     item.FundingIntervalHours,
+			AdjustedFundingRateCap:   p.required("adjustedFundingRateCap", item.AdjustedFundingRateCap),
+			AdjustedFundingRateFloor: p.required("adjustedFundingRateFloor", item.AdjustedFundingRateFloor),
+		}
+		if p.err != nil {
+			return nil, fmt.Errorf("fundingInfo[%d]: %w", i, p.err)
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+func parseOpenInterest(data []byte) (*dto.OpenInterest, error) {
+	var w binanceOpenInterest
+	if err := decodeStrict(data, &w); err != nil {
+		return nil, err
+	}
+	if err := requireSymbol(w.Symbol); err != nil {
+		return nil, err
+	}
+	p := &decimalParser{}
+	out := &dto.OpenInterest{
+		Symbol:       w.Symbol,
+		Pair:         w.Pair,
+		ContractType: w.ContractType,
+		OpenInterest: p.required("openInterest", w.OpenInterest),
+		Time:         w.Time,
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return out, nil
+}
+
+func parseLongShortRatios(data []byte) ([]dto.LongShortRatio, error) {
+	var w []binanceLongShortRatio
+	if err := decodeStrict(data, &w); err != nil {
+		return nil, err
+	}
+	out := make([]dto.LongShortRatio, 0, len(w))
+	for i, item := range w {
+		symbol := item.Symbol
+		if symbol == "" {
+			symbol = item.Pair
+		}
+		if err := requireSymbol(symbol); err != nil {
+			return nil, fmt.Errorf("longShortRatio[%d]: %w", i, err)
+		}
+		long, short := item.LongAccount, item.ShortAccount
+		if long == "" && short == "" {
+			long, short = item.LongPosition, item.ShortPosition
+		}
+		ts, err := item.Timestamp.Int64()
+		if err != nil {
+			return nil, fmt.Errorf("longShortRatio[%d]: field timestamp: %w", i, err)
+		}
+		p := &decimalParser{}
+		r := dto.LongShortRatio{
+			Symbol:         symbol,
+			LongShortRatio: p.required("longShortRatio", item.LongShortRatio),
+			Long:           p.required("long", long),
+			Short:          p.required("short", short),
+			Timestamp:      ts,
+		}
+		if p.err != nil {
+			return nil, fmt.Errorf("longShortRatio[%d]: %w", i, p.err)
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseMarkPrices(t *testing.T) {
+	const usdm = `{"symbol":"BTCUSDT","markPrice":"67010.10000000","indexPrice":"67020.50","estimatedSettlePrice":"67015.2","lastFundingRate":"0.00010000","interestRate":"0.00010000","nextFundingTime":1746115200000,"time":1746100800000}`
+	// USD-M answers a single symbol with an object, everything else with a list.
+	for _, data := range []string{usdm, "[" + usdm + "]"} {
+		prices, err := parseMarkPrices([]byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(prices) != 1 {
+			t.Fatalf("%d mark prices, want 1", len(prices))
+		}
+		m := prices[0]
+		if m.Symbol != "BTCUSDT" || m.NextFundingTime != 1746115200000 || m.Time != 1746100800000 {
+			t.Errorf("mark price = %+v", m)
+		}
+		assertDecimal(t, "MarkPrice", m.MarkPrice, "67010.1")
+		assertDecimal(t, "LastFundingRate", m.LastFundingRate, "0.0001")
+	}
+
+	// Delivery contracts leave the funding fields empty.
+	prices, err := parseMarkPrices([]byte(`[{"symbol":"BTCUSD_250627","pair":"BTCUSD","markPrice":"68000.1","indexPrice":"67020.5","estimatedSettlePrice":"","lastFundingRate":"","interestRate":"","nextFundingTime":0,"time":1746100800000}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prices[0].Pair != "BTCUSD" || prices[0].LastFundingRate.Sign() != 0 || prices[0].InterestRate.Sign() != 0 {
+		t.Errorf("delivery mark price = %+v", prices[0])
+	}
+}
+
+func TestParseFundingRates(t *testing.T) {
+	rates, err := parseFundingRates([]byte(`[{"symbol":"BTCUSDT","fundingRate":"-0.00002500","fundingTime":1746086400000,"markPrice":"66950.3"},{"symbol":"BTCUSD_PERP","fundingRate":"0.0001","fundingTime":1746115200000}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rates) != 2 {
+		t.Fatalf("%d funding rates, want 2", len(rates))
+	}
+	assertDecimal(t, "FundingRate", rates[0].FundingRate, "-0.000025")
+	if rates[0].MarkPrice == nil || rates[0].FundingTime != 1746086400000 {
+		t.Errorf("funding rate = %+v", rates[0])
+	}
+	// COIN-M fundings carry no mark price.
+	if rates[1].MarkPrice != nil {
+		t.Errorf("MarkPrice = %s, want none", rates[1].MarkPrice)
+	}
+}
+
+func TestParseOpenInterest(t *testing.T) {
+	oi, err := parseOpenInterest([]byte(`{"symbol":"BTCUSD_PERP","pair":"BTCUSD","contractType":"PERPETUAL","openInterest":"912345","time":1746100800000}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if oi.Symbol != "BTCUSD_PERP" || oi.Pair != "BTCUSD" || oi.ContractType != "PERPETUAL" || oi.Time != 1746100800000 {
+		t.Errorf("open interest = %+v", oi)
+	}
+	assertDecimal(t, "OpenInterest", oi.OpenInterest, "912345")
+}
+
+func TestParseLongShortRatios(t *testing.T) {
+	// USD-M account ratios name the symbol and send the timestamp as a number;
+	// COIN-M position ratios name the pair and send it as a string.
+	ratios, err := parseLongShortRatios([]byte(`[
+		{"symbol":"BTCUSDT","longShortRatio":"1.8105","longAccount":"0.6442","shortAccount":"0.3558","timestamp":1746100800000},
+		{"pair":"BTCUSD","longShortRatio":"0.9","longPosition":"0.4737","shortPosition":"0.5263","timestamp":"1746104400000"}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ratios) != 2 {
+		t.Fatalf("%d ratios, want 2", len(ratios))
+	}
+	if ratios[0].Symbol != "BTCUSDT" || ratios[0].Timestamp != 1746100800000 {
+		t.Errorf("ratios[0] = %+v", ratios[0])
+	}
+	assertDecimal(t, "Long", ratios[0].Long, "0.6442")
+	if ratios[1].Symbol != "BTCUSD" || ratios[1].Timestamp != 1746104400000 {
+		t.Errorf("ratios[1] = %+v", ratios[1])
+	}
+	assertDecimal(t, "Short", ratios[1].Short, "0.5263")
+}
+
+func TestParseFuturesWireRejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		parse func([]byte) error
+		data  string
+		want  string
+	}{
+		{"mark price without symbol", func(b []byte) error { _, err := parseMarkPrices(b); return err },
+			`{"markPrice":"1","indexPrice":"1"}`, "field symbol"},
+		{"mark price list entry", func(b []byte) error { _, err := parseMarkPrices(b); return err },
+			`[{"symbol":"BTCUSDT","markPrice":"1e3","indexPrice":"1"}]`, "markPrice[0]"},
+		{"mark price without index", func(b []byte) error { _, err := parseMarkPrices(b); return err },
+			`{"symbol":"BTCUSDT","markPrice":"1"}`, "field indexPrice"},
+		{"funding without rate", func(b []byte) error { _, err := parseFundingRates(b); return err },
+			`[{"symbol":"BTCUSDT","fundingTime":1}]`, "fundingRate[0]: field fundingRate"},
+		{"open interest as number", func(b []byte) error { _, err := parseOpenInterest(b); return err },
+			`{"symbol":"BTCUSDT","openInterest":12.5,"time":1}`, "openInterest"},
+		{"ratio without contract", func(b []byte) error { _, err := parseLongShortRatios(b); return err },
+			`[{"longShortRatio":"1","longAccount":"0.5","shortAccount":"0.5","timestamp":1}]`, "longShortRatio[0]: field symbol"},
+		{"ratio with a fractional timestamp", func(b []byte) error { _, err := parseLongShortRatios(b); return err },
+			`[{"symbol":"BTCUSDT","longShortRatio":"1","longAccount":"0.5","shortAccount":"0.5","timestamp":1.5}]`, "field timestamp"},
+		{"trailing data", func(b []byte) error { _, err := parseOpenInterest(b); return err },
+			`{"symbol":"BTCUSDT","openInterest":"1","time":1} {}`, "trailing data"},
+	}
+	for _, tt := range tests {
+		err := tt.parse([]byte(tt.data))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: err = %v, want it to mention %q", tt.name, err, tt.want)
+		}
+	}
+}
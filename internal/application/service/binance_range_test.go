@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ntdat104/go-finance-dataset/internal/application/dto"
+	"github.com/ntdat104/go-finance-dataset/pkg/config"
+	"github.com/ntdat104/go-finance-dataset/pkg/httpclient"
+)
+
+// klineServer serves one-minute candles from first onwards. Like pages that
+// overlap on their boundary, every page after the first repeats the candle
+// before startTime.
+func klineServer(t *testing.T, first int64) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		q := r.URL.Query()
+		start, _ := strconv.ParseInt(q.Get("startTime"), 10, 64)
+		end, _ := strconv.ParseInt(q.Get("endTime"), 10, 64)
+		limit, _ := strconv.Atoi(q.Get("limit"))
+		if start > first {
+			start -= time.Minute.Milliseconds()
+		}
+		start = max(start, first)
+		var rows []string
+		for open := start; open <= end && len(rows) < limit; open += time.Minute.Milliseconds() {
+			rows = append(rows, fmt.Sprintf(`[%d,"1.0","1.0","1.0","1.0","1",%d,"1",1,"1","1","0"]`, open, open+59999))
+		}
+		fmt.Fprintf(w, "[%s]", strings.Join(rows, ","))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestStreamKlinesRangePaginates(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+	end := start + 2499*time.Minute.Milliseconds()
+	server, requests := klineServer(t, start)
+	svc := newTestBinanceSvc(t, server.URL)
+	ctx := context.Background()
+
+	var pages []int
+	next := start
+	err := svc.StreamKlinesRange(ctx, "BTCUSDT", "1m", start, end, func(page []dto.Kline) error {
+		pages = append(pages, len(page))
+		for _, k := range page {
+			if k.OpenTime != next {
+				return fmt.Errorf("got candle %d, want %d", k.OpenTime, next)
+			}
+			next += time.Minute.Milliseconds()
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != end+time.Minute.Milliseconds() {
+		t.Errorf("stopped at %d, want every candle up to %d", next, end)
+	}
+	// The repeated boundary candle is dropped from every page but the first.
+	if want := []int{1000, 999, 501}; fmt.Sprint(pages) != fmt.Sprint(want) {
+		t.Errorf("pages = %v, want %v", pages, want)
+	}
+	if n := requests.Load(); n != 3 {
+		t.Errorf("%d requests, want 3", n)
+	}
+}
+
+func TestGetKlinesRange(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+	server, requests := klineServer(t, start)
+	svc := newTestBinanceSvc(t, server.URL)
+	ctx := context.Background()
+
+	for range 2 {
+		klines, err := svc.GetKlinesRange(ctx, "BTCUSDT", "1m", start, start+9*time.Minute.Milliseconds())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(klines) != 10 || klines[0].OpenTime != start {
+			t.Errorf("got %d klines from %d", len(klines), klines[0].OpenTime)
+		}
+	}
+	// The second read is served by the kline store.
+	if n := requests.Load(); n != 1 {
+		t.Errorf("%d requests, want 1", n)
+	}
+
+	// A range before the first candle is empty, not nil.
+	klines, err := svc.GetKlinesRange(ctx, "BTCUSDT", "1m", start-time.Hour.Milliseconds(), start-time.Minute.Milliseconds())
+	if err != nil || klines == nil || len(klines) != 0 {
+		t.Errorf("empty range = %v, %v", klines, err)
+	}
+
+	if _, err := svc.GetKlinesRange(ctx, "BTCUSDT", "2m", start, start); err == nil {
+		t.Error("unsupported interval accepted")
+	}
+	if _, err := svc.GetKlinesRange(ctx, "BTCUSDT", "1m", start, start-1); err == nil {
+		t.Error("endTime before startTime accepted")
+	}
+}
+
+// newTestBinanceSvc returns the spot service fetching from baseURL, with a
+// fresh kline store.
+func newTestBinanceSvc(t *testing.T, baseURL string) *binanceSvc {
+	t.Helper()
+	store, err := NewKlineStoreSvc(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	limiter, err := NewRateLimiterSvc(nil, nil, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc, err := NewBinanceSvc(store, limiter, httpclient.New(httpclient.Options{}), NewLocalCacheSvc(0, 0), config.Cache{RefreshWorkers: 1, RefreshQueueSize: 1}, config.BinanceAuth{}, []string{baseURL}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return svc.(*binanceSvc)
+}
@@ -0,0 +1,346 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ntdat104/go-finance-dataset/internal/application/dto"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	streamMinBackoff = time.Second
+	streamMaxBackoff = time.Minute
+	// streamReadTimeout must exceed the interval of Binance's ping frames (3 minutes)
+	// so a quiet but healthy connection is not torn down.
+	streamReadTimeout = 10 * time.Minute
+	// streamStableAfter is how long a connection must stay up before the backoff resets.
+	streamStableAfter = time.Minute
+)
+
+// StreamSvc maintains combined-stream WebSocket connections to Binance and
+// dispatches the decoded events to listeners.
+type StreamSvc interface {
+	Subscribe(streams ...string) error
+	Unsubscribe(streams ...string) error
+	Streams() []string
+	AddListener(fn func(dto.StreamEvent))
+	Close()
+}
+
+type binanceStreamSvc struct {
+	baseURL    string
+	maxStreams int
+	lock       sync.RWMutex
+	conns      []*streamConn
+	listeners  []func(dto.StreamEvent)
+	closed     chan struct{}
+}
+
+// streamConn is one combined-stream connection serving a subset of the streams.
+type streamConn struct {
+	svc     *binanceStreamSvc
+	lock    sync.Mutex
+	streams map[string]bool
+	ws      *websocket.Conn
+	nextID  int64
+	wake    chan struct{}
+}
+
+type streamRequest struct {
+	Method string   `json:"method"`
+	Params []string `json:"params"`
+	ID     int64    `json:"id"`
+}
+
+// NewBinanceStreamSvc creates the stream service. baseURL is the stream host,
+// e.g. "wss://stream.binance.com:9443"; maxStreams caps the streams per connection.
+func NewBinanceStreamSvc(baseURL string, maxStreams int) StreamSvc {
+	return &binanceStreamSvc{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		maxStreams: max(maxStreams, 1),
+		closed:     make(chan struct{}),
+	}
+}
+
+// AddListener registers fn to receive every event. Listeners run on the
+// connection's read goroutine and must not block.
+func (s *binanceStreamSvc) AddListener(fn func(dto.StreamEvent)) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.listeners = append(s.listeners, fn)
+}
+
+// Subscribe adds streams such as "btcusdt@trade" or "ethusdt@kline_1m",
+// opening new connections when the existing ones are full.
+func (s *binanceStreamSvc) Subscribe(streams ...string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	pending := make(map[*streamConn][]string)
+	for _, stream := range streams {
+		if streamEventType(stream) == "" {
+			return fmt.Errorf("unsupported stream %q", stream)
+		}
+		if s.findConnLocked(stream) != nil {
+			continue
+		}
+		conn := s.connWithRoomLocked(pending)
+		pending[conn] = append(pending[conn], stream)
+	}
+	for conn, added := range pending {
+		conn.add(added)
+	}
+	return nil
+}
+
+// Unsubscribe removes streams from their connections.
+func (s *binanceStreamSvc) Unsubscribe(streams ...string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, stream := range streams {
+		if conn := s.findConnLocked(stream); conn != nil {
+			conn.remove(stream)
+		}
+	}
+	return nil
+}
+
+// Streams returns every subscribed stream.
+func (s *binanceStreamSvc) Streams() []string {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	var out []string
+	for _, conn := range s.conns {
+		out = append(out, conn.snapshot()...)
+	}
+	slices.Sort(out)
+	return out
+}
+
+// Close stops every connection.
+func (s *binanceStreamSvc) Close() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	select {
+	case <-s.closed:
+		return
+	default:
+		close(s.closed)
+	}
+	for _, conn := range s.conns {
+		conn.closeWS()
+	}
+}
+
+func (s *binanceStreamSvc) findConnLocked(stream string) *streamConn {
+	for _, conn := range s.conns {
+		if conn.has(stream) {
+			return conn
+		}
+	}
+	return nil
+}
+
+func (s *binanceStreamSvc) connWithRoomLocked(pending map[*streamConn][]string) *streamConn {
+	for _, conn := range s.conns {
+		if conn.size()+len(pending[conn]) < s.maxStreams {
+			return conn
+		}
+	}
+	conn := &streamConn{
+		svc:     s,
+		streams: make(map[string]bool),
+		wake:    make(chan struct{}, 1),
+	}
+	s.conns = append(s.conns, conn)
+	go conn.run()
+	return conn
+}
+
+func (s *binanceStreamSvc) dispatch(data []byte) {
+	event, err := parseStreamMessage(data)
+	if err != nil {
+		log.Printf("Failed to decode stream message: %v", err)
+		return
+	}
+	s.emit(*event)
+}
+
+// dispatchDisconnect tells listeners that streams lost their connection.
+func (s *binanceStreamSvc) dispatchDisconnect(streams []string) {
+	for _, stream := range streams {
+		symbol, _, _ := strings.Cut(stream, "@")
+		s.emit(dto.StreamEvent{Stream: stream, Type: dto.StreamEventDisconnect, Symbol: strings.ToUpper(symbol)})
+	}
+}
+
+func (s *binanceStreamSvc) emit(event dto.StreamEvent) {
+	s.lock.RLock()
+	listeners := s.listeners
+	s.lock.RUnlock()
+	for _, fn := range listeners {
+		fn(event)
+	}
+}
+
+func (c *streamConn) has(stream string) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.streams[stream]
+}
+
+func (c *streamConn) size() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.streams)
+}
+
+func (c *streamConn) snapshot() []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	out := make([]string, 0, len(c.streams))
+	for stream := range c.streams {
+		out = append(out, stream)
+	}
+	return out
+}
+
+func (c *streamConn) add(streams []string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, stream := range streams {
+		c.streams[stream] = true
+	}
+	if c.ws != nil {
+		c.sendLocked("SUBSCRIBE", streams)
+	}
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (c *streamConn) remove(stream string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.streams, stream)
+	if c.ws != nil {
+		c.sendLocked("UNSUBSCRIBE", []string{stream})
+	}
+}
+
+// sendLocked sends a live (un)subscribe request. A failed send is left to the
+// reconnect, which always dials with the full stream set.
+func (c *streamConn) sendLocked(method string, streams []string) {
+	c.nextID++
+	req := streamRequest{Method: method, Params: streams, ID: c.nextID}
+	if err := websocket.JSON.Send(c.ws, req); err != nil {
+		log.Printf("Failed to %s streams %v: %v", strings.ToLower(method), streams, err)
+		c.ws.Close()
+	}
+}
+
+func (c *streamConn) closeWS() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.ws != nil {
+		c.ws.Close()
+	}
+}
+
+// run keeps the connection alive, reconnecting with jittered exponential backoff.
+func (c *streamConn) run() {
+	backoff := streamMinBackoff
+	for {
+		streams := c.snapshot()
+		if len(streams) == 0 {
+			select {
+			case <-c.wake:
+				continue
+			case <-c.svc.closed:
+				return
+			}
+		}
+
+		started := time.Now()
+		err := c.connect(streams)
+		select {
+		case <-c.svc.closed:
+			return
+		default:
+		}
+		if time.Since(started) > streamStableAfter {
+			backoff = streamMinBackoff
+		}
+		wait := backoff/2 + rand.N(backoff/2+1)
+		log.Printf("Binance stream connection lost (%v), reconnecting in %v", err, wait)
+		select {
+		case <-time.After(wait):
+		case <-c.svc.closed:
+			return
+		}
+		backoff = min(backoff*2, streamMaxBackoff)
+	}
+}
+
+// connect dials a combined stream for streams and reads until the connection fails.
+func (c *streamConn) connect(streams []string) error {
+	url := fmt.Sprintf("%s/stream?streams=%s", c.svc.baseURL, strings.Join(streams, "/"))
+	ws, err := websocket.Dial(url, "", "http://localhost/")
+	if err != nil {
+		return err
+	}
+	defer ws.Close()
+
+	c.lock.Lock()
+	c.ws = ws
+	// Streams added while dialing were not part of the URL.
+	var missed []string
+	for stream := range c.streams {
+		if !slices.Contains(streams, stream) {
+			missed = append(missed, stream)
+		}
+	}
+	if len(missed) > 0 {
+		c.sendLocked("SUBSCRIBE", missed)
+	}
+	c.lock.Unlock()
+	defer func() {
+		c.lock.Lock()
+		c.ws = nil
+		c.lock.Unlock()
+		c.svc.dispatchDisconnect(c.snapshot())
+	}()
+
+	for {
+		if err := ws.SetReadDeadline(time.Now().Add(streamReadTimeout)); err != nil {
+			return err
+		}
+		var data []byte
+		if err := websocket.Message.Receive(ws, &data); err != nil {
+			return err
+		}
+		// Replies to (un)subscribe requests carry an id instead of a stream.
+		if !isStreamPayload(data) {
+			continue
+		}
+		c.svc.dispatch(data)
+	}
+}
+
+func isStreamPayload(data []byte) bool {
+	var probe struct {
+		Stream string `json:"stream"`
+	}
+	return json.Unmarshal(data, &probe) == nil && probe.Stream != ""
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ntdat104/go-finance-dataset/internal/application/dto"
+	"github.com/ntdat104/go-finance-dataset/pkg/decimal"
+)
+
+func TestStreamEventType(t *testing.T) {
+	tests := map[string]string{
+		"btcusdt@trade":       dto.StreamEventTrade,
+		"btcusdt@aggTrade":    dto.StreamEventAggTrade,
+		"btcusdt@kline_1m":    dto.StreamEventKline,
+		"btcusdt@bookTicker":  dto.StreamEventBookTicker,
+		"btcusdt@depth":       dto.StreamEventDepthUpdate,
+		"btcusdt@depth@100ms": dto.StreamEventDepthUpdate,
+		"btcusdt@ticker":      "",
+		"btcusdt":             "",
+	}
+	for stream, want := range tests {
+		if got := streamEventType(stream); got != want {
+			t.Errorf("streamEventType(%q) = %q, want %q", stream, got, want)
+		}
+	}
+}
+
+func TestParseStreamMessage(t *testing.T) {
+	trade, err := parseStreamMessage([]byte(`{"stream":"btcusdt@trade","data":{"e":"trade","E":2,"s":"BTCUSDT","t":7,"p":"100.50","q":"0.2","T":1,"m":true,"M":true}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trade.Symbol != "BTCUSDT" || trade.Trade.ID != 7 || trade.Trade.QuoteQty.String() != "20.100" {
+		t.Errorf("trade = %+v %+v", trade, trade.Trade)
+	}
+
+	kline, err := parseStreamMessage([]byte(`{"stream":"btcusdt@kline_1m","data":{"e":"kline","E":2,"s":"BTCUSDT","k":{"t":0,"T":59999,"s":"BTCUSDT","i":"1m","f":1,"L":2,"o":"1","c":"2","h":"3","l":"0.5","v":"10","n":2,"x":true,"q":"15","V":"4","Q":"6","B":"0"}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k := kline.Kline; k.Interval != "1m" || !k.Closed || k.Low.String() != "0.5" || k.CloseTime != 59999 {
+		t.Errorf("kline = %+v", k)
+	}
+
+	depth, err := parseStreamMessage([]byte(`{"stream":"btcusdt@depth@100ms","data":{"e":"depthUpdate","E":2,"s":"BTCUSDT","U":10,"u":12,"b":[["100","1"]],"a":[]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := depth.DepthUpdate; d.FirstUpdateID != 10 || d.FinalUpdateID != 12 || len(d.Bids) != 1 {
+		t.Errorf("depth update = %+v", d)
+	}
+
+	rejected := map[string]string{
+		"no stream":        `{"data":{}}`,
+		"unknown stream":   `{"stream":"btcusdt@ticker","data":{}}`,
+		"bad price":        `{"stream":"btcusdt@trade","data":{"e":"trade","E":2,"s":"BTCUSDT","t":7,"p":"1e2","q":"1","T":1,"m":true,"M":true}}`,
+		"missing symbol":   `{"stream":"btcusdt@bookTicker","data":{"u":1,"b":"1","B":"1","a":"2","A":"1"}}`,
+		"trailing message": `{"stream":"btcusdt@bookTicker","data":{"u":1,"s":"BTCUSDT","b":"1","B":"1","a":"2","A":"1"}}{}`,
+	}
+	for name, data := range rejected {
+		if _, err := parseStreamMessage([]byte(data)); err == nil {
+			t.Errorf("%s: parsed without error", name)
+		}
+	}
+}
+
+// tradeServer answers /api/v3/trades with limit trades and counts the requests.
+func tradeServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		var limit int
+		fmt.Sscan(r.URL.Query().Get("limit"), &limit)
+		rows := make([]string, 0, max(limit, 0))
+		for i := range limit {
+			rows = append(rows, fmt.Sprintf(`{"id":%d,"price":"1","qty":"1","quoteQty":"1","time":1,"isBuyerMaker":false,"isBestMatch":true}`, i))
+		}
+		fmt.Fprintf(w, "[%s]", strings.Join(rows, ","))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestRecentTradesFromStream(t *testing.T) {
+	server, requests := tradeServer(t)
+	svc := newTestBinanceSvc(t, server.URL)
+	ctx := context.Background()
+	for id := range int64(5) {
+		svc.HandleStreamEvent(dto.StreamEvent{
+			Type:   dto.StreamEventTrade,
+			Symbol: "BTCUSDT",
+			Trade:  &dto.Trade{ID: 100 + id, Price: decimal.MustParse("10.5"), Qty: decimal.MustParse("1")},
+		})
+	}
+
+	trades, err := svc.GetRecentTrades(ctx, "BTCUSDT", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trades) != 3 || trades[0].ID != 102 || trades[2].ID != 104 || requests.Load() != 0 {
+		t.Errorf("got %d trades from %d after %d requests, want the last 3 streamed", len(trades), trades[0].ID, requests.Load())
+	}
+	// The streamed trade also updates the cached price.
+	if price, err := svc.GetTickerPrice(ctx, "BTCUSDT"); err != nil || price.Price.String() != "10.5" || requests.Load() != 0 {
+		t.Errorf("ticker price = %+v, %v", price, err)
+	}
+
+	// Limits the buffer cannot serve go upstream instead of failing.
+	for _, limit := range []int{6, 0, -5} {
+		before := requests.Load()
+		trades, err := svc.GetRecentTrades(ctx, "BTCUSDT", limit)
+		if err != nil {
+			t.Fatalf("limit %d: %v", limit, err)
+		}
+		if requests.Load() != before+1 || len(trades) != max(limit, 0) {
+			t.Errorf("limit %d: got %d trades, %d upstream requests", limit, len(trades), requests.Load()-before)
+		}
+	}
+}
+
+func TestStreamedKlinesAreStored(t *testing.T) {
+	server, _ := tradeServer(t)
+	svc := newTestBinanceSvc(t, server.URL)
+	openTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+	for _, closed := range []bool{false, true} {
+		svc.HandleStreamEvent(dto.StreamEvent{
+			Type:   dto.StreamEventKline,
+			Symbol: "BTCUSDT",
+			Kline: &dto.StreamKline{
+				Interval: "1m",
+				Closed:   closed,
+				Kline:    dto.Kline{OpenTime: openTime, CloseTime: openTime + 59999},
+			},
+		})
+	}
+	stored, err := svc.klineStoreSvc.Range("BTCUSDT", "1m", openTime, openTime)
+	if err != nil || len(stored) != 1 {
+		t.Errorf("stored = %v, %v; want only the closed candle", stored, err)
+	}
+}
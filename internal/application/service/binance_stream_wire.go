@@ -0,0 +1,220 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ntdat104/go-finance-dataset/internal/application/dto"
+)
+
+// Wire types of the Binance WebSocket market streams. Keys that differ only in
+// case (e/E, l/L, b/B) must all be declared, since encoding/json falls back to
+// case-insensitive matching for undeclared keys.
+
+type binanceCombinedMessage struct {
+	Stream string          `json:"stream"`
+	Data   json.RawMessage `json:"data"`
+}
+
+type binanceStreamTrade struct {
+	EventType    string `json:"e"`
+	EventTime    int64  `json:"E"`
+	Symbol       string `json:"s"`
+	TradeID      int64  `json:"t"`
+	Price        string `json:"p"`
+	Qty          string `json:"q"`
+	TradeTime    int64  `json:"T"`
+	IsBuyerMaker bool   `json:"m"`
+	IsBestMatch  bool   `json:"M"`
+}
+
+type binanceStreamAggTrade struct {
+	EventType string `json:"e"`
+	EventTime int64  `json:"E"`
+	Symbol    string `json:"s"`
+	binanceAggTrade
+}
+
+type binanceStreamKline struct {
+	EventType string `json:"e"`
+	EventTime int64  `json:"E"`
+	Symbol    string `json:"s"`
+	Kline     struct {
+		OpenTime                 int64  `json:"t"`
+		CloseTime                int64  `json:"T"`
+		Symbol                   string `json:"s"`
+		Interval                 string `json:"i"`
+		FirstTradeID             int64  `json:"f"`
+		LastTradeID              int64  `json:"L"`
+		Open                     string `json:"o"`
+		Close                    string `json:"c"`
+		High                     string `json:"h"`
+		Low                      string `json:"l"`
+		Volume                   string `json:"v"`
+		NumberOfTrades           int64  `json:"n"`
+		Closed                   bool   `json:"x"`
+		QuoteAssetVolume         string `json:"q"`
+		TakerBuyBaseAssetVolume  string `json:"V"`
+		TakerBuyQuoteAssetVolume string `json:"Q"`
+		Ignore                   string `json:"B"`
+	} `json:"k"`
+}
+
+type binanceStreamBookTicker struct {
+	UpdateID int64  `json:"u"`
+	Symbol   string `json:"s"`
+	BidPrice string `json:"b"`
+	BidQty   string `json:"B"`
+	AskPrice string `json:"a"`
+	AskQty   string `json:"A"`
+}
+
+type binanceStreamDepth struct {
+	EventType     string      `json:"e"`
+	EventTime     int64       `json:"E"`
+	Symbol        string      `json:"s"`
+	FirstUpdateID int64       `json:"U"`
+	FinalUpdateID int64       `json:"u"`
+	Bids          [][2]string `json:"b"`
+	Asks          [][2]string `json:"a"`
+}
+
+// streamEventType derives the event type from a stream name such as
+// "btcusdt@kline_1m" or "btcusdt@depth@100ms".
+func streamEventType(stream string) string {
+	parts := strings.Split(stream, "@")
+	if len(parts) < 2 {
+		return ""
+	}
+	switch kind := parts[1]; {
+	case kind == "trade":
+		return dto.StreamEventTrade
+	case kind == "aggTrade":
+		return dto.StreamEventAggTrade
+	case strings.HasPrefix(kind, "kline_"):
+		return dto.StreamEventKline
+	case kind == "bookTicker":
+		return dto.StreamEventBookTicker
+	case strings.HasPrefix(kind, "depth"):
+		return dto.StreamEventDepthUpdate
+	default:
+		return ""
+	}
+}
+
+// parseStreamMessage decodes one combined-stream message into a typed event.
+func parseStreamMessage(data []byte) (*dto.StreamEvent, error) {
+	var msg binanceCombinedMessage
+	if err := decodeStrict(data, &msg); err != nil {
+		return nil, err
+	}
+	if msg.Stream == "" {
+		return nil, fmt.Errorf("message without stream name: %s", data)
+	}
+	event := &dto.StreamEvent{Stream: msg.Stream, Type: streamEventType(msg.Stream)}
+	p := &decimalParser{}
+
+	switch event.Type {
+	case dto.StreamEventTrade:
+		var w binanceStreamTrade
+		if err := decodeStrict(msg.Data, &w); err != nil {
+			return nil, err
+		}
+		event.Symbol = w.Symbol
+		price := p.required("p", w.Price)
+		qty := p.required("q", w.Qty)
+		event.Trade = &dto.Trade{
+			ID:           w.TradeID,
+			Price:        price,
+			Qty:          qty,
+			QuoteQty:     price.Mul(qty),
+			Time:         w.TradeTime,
+			IsBuyerMaker: w.IsBuyerMaker,
+			IsBestMatch:  w.IsBestMatch,
+		}
+	case dto.StreamEventAggTrade:
+		var w binanceStreamAggTrade
+		if err := decodeStrict(msg.Data, &w); err != nil {
+			return nil, err
+		}
+		event.Symbol = w.Symbol
+		event.AggTrade = &dto.AggTrade{
+			AggTradeID:   w.AggTradeID,
+			Price:        p.required("p", w.Price),
+			Qty:          p.required("q", w.Qty),
+			FirstTradeID: w.FirstTradeID,
+			LastTradeID:  w.LastTradeID,
+			Time:         w.Time,
+			IsBuyerMaker: w.IsBuyerMaker,
+			IsBestMatch:  w.IsBestMatch,
+		}
+	case dto.StreamEventKline:
+		var w binanceStreamKline
+		if err := decodeStrict(msg.Data, &w); err != nil {
+			return nil, err
+		}
+		event.Symbol = w.Symbol
+		event.Kline = &dto.StreamKline{
+			Interval: w.Kline.Interval,
+			Closed:   w.Kline.Closed,
+			Kline: dto.Kline{
+				OpenTime:                 w.Kline.OpenTime,
+				Open:                     p.required("o", w.Kline.Open),
+				High:                     p.required("h", w.Kline.High),
+				Low:                      p.required("l", w.Kline.Low),
+				Close:                    p.required("c", w.Kline.Close),
+				Volume:                   p.required("v", w.Kline.Volume),
+				CloseTime:                w.Kline.CloseTime,
+				QuoteAssetVolume:         p.required("q", w.Kline.QuoteAssetVolume),
+				NumberOfTrades:           w.Kline.NumberOfTrades,
+				TakerBuyBaseAssetVolume:  p.required("V", w.Kline.TakerBuyBaseAssetVolume),
+				TakerBuyQuoteAssetVolume: p.required("Q", w.Kline.TakerBuyQuoteAssetVolume),
+			},
+		}
+	case dto.StreamEventBookTicker:
+		var w binanceStreamBookTicker
+		if err := decodeStrict(msg.Data, &w); err != nil {
+			return nil, err
+		}
+		event.Symbol = w.Symbol
+		event.BookTicker = &dto.BookTicker{
+			Symbol:   w.Symbol,
+			BidPrice: p.required("b", w.BidPrice),
+			BidQty:   p.required("B", w.BidQty),
+			AskPrice: p.required("a", w.AskPrice),
+			AskQty:   p.required("A", w.AskQty),
+		}
+	case dto.StreamEventDepthUpdate:
+		var w binanceStreamDepth
+		if err := decodeStrict(msg.Data, &w); err != nil {
+			return nil, err
+		}
+		bids, err := parsePriceLevels("b", w.Bids)
+		if err != nil {
+			return nil, err
+		}
+		asks, err := parsePriceLevels("a", w.Asks)
+		if err != nil {
+			return nil, err
+		}
+		event.Symbol = w.Symbol
+		event.DepthUpdate = &dto.DepthUpdate{
+			EventTime:     w.EventTime,
+			FirstUpdateID: w.FirstUpdateID,
+			FinalUpdateID: w.FinalUpdateID,
+			Bids:          bids,
+			Asks:          asks,
+		}
+	default:
+		return nil, fmt.Errorf("unsupported stream %s", msg.Stream)
+	}
+
+	if p.err != nil {
+		return nil, fmt.Errorf("stream %s: %w", msg.Stream, p.err)
+	}
+	if err := requireSymbol(event.Symbol); err != nil {
+		return nil, fmt.Errorf("stream %s: %w", msg.Stream, err)
+	}
+	return event, nil
+}
@@ -0,0 +1,549 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ntdat104/go-finance-dataset/internal/application/dto"
+	"github.com/ntdat104/go-finance-dataset/pkg/decimal"
+)
+
+// Wire types mirror the raw Binance REST payloads. They are decoded strictly and
+// converted into the dto types, so malformed upstream data never reaches callers.
+
+type binanceRateLimit struct {
+	RateLimitType string `json:"rateLimitType"`
+	Interval      string `json:"interval"`
+	IntervalNum   int    `json:"intervalNum"`
+	Limit         int    `json:"limit"`
+}
+
+type binanceSymbolFilter struct {
+	FilterType        string `json:"filterType"`
+	MinPrice          string `json:"minPrice"`
+	MaxPrice          string `json:"maxPrice"`
+	TickSize          string `json:"tickSize"`
+	MinQty            string `json:"minQty"`
+	MaxQty            string `json:"maxQty"`
+	StepSize          string `json:"stepSize"`
+	MinNotional       string `json:"minNotional"`
+	MaxNotional       string `json:"maxNotional"`
+	MultiplierUp      string `json:"multiplierUp"`
+	MultiplierDown    string `json:"multiplierDown"`
+	BidMultiplierUp   string `json:"bidMultiplierUp"`
+	BidMultiplierDown string `json:"bidMultiplierDown"`
+	AskMultiplierUp   string `json:"askMultiplierUp"`
+	AskMultiplierDown string `json:"askMultiplierDown"`
+	AvgPriceMins      *int   `json:"avgPriceMins"`
+	ApplyToMarket     *bool  `json:"applyToMarket"`
+	Limit             *int   `json:"limit"`
+	MaxNumOrders      *int   `json:"maxNumOrders"`
+	MaxNumAlgoOrders  *int   `json:"maxNumAlgoOrders"`
+}
+
+type binanceSymbolInfo struct {
+	Symbol                 string                `json:"symbol"`
+	Status                 string                `json:"status"`
+	BaseAsset              string                `json:"baseAsset"`
+	BaseAssetPrecision     int                   `json:"baseAssetPrecision"`
+	QuoteAsset             string                `json:"quoteAsset"`
+	QuoteAssetPrecision    int                   `json:"quoteAssetPrecision"`
+	OrderTypes             []string              `json:"orderTypes"`
+	IcebergAllowed         bool                  `json:"icebergAllowed"`
+	OcoAllowed             bool                  `json:"ocoAllowed"`
+	IsSpotTradingAllowed   bool                  `json:"isSpotTradingAllowed"`
+	IsMarginTradingAllowed bool                  `json:"isMarginTradingAllowed"`
+	Filters                []binanceSymbolFilter `json:"filters"`
+	Permissions            []string              `json:"permissions"`
+}
+
+type binanceExchangeInfo struct {
+	Timezone   string              `json:"timezone"`
+	ServerTime int64               `json:"serverTime"`
+	RateLimits []binanceRateLimit  `json:"rateLimits"`
+	Symbols    []binanceSymbolInfo `json:"symbols"`
+}
+
+type binanceTickerPrice struct {
+	Symbol string `json:"symbol"`
+	Price  string `json:"price"`
+}
+
+type binanceBookTicker struct {
+	Symbol   string `json:"symbol"`
+	BidPrice string `json:"bidPrice"`
+	BidQty   string `json:"bidQty"`
+	AskPrice string `json:"askPrice"`
+	AskQty   string `json:"askQty"`
+}
+
+type binanceDepth struct {
+	LastUpdateID int64       `json:"lastUpdateId"`
+	Bids         [][2]string `json:"bids"`
+	Asks         [][2]string `json:"asks"`
+}
+
+type binanceTrade struct {
+	ID           int64  `json:"id"`
+	Price        string `json:"price"`
+	Qty          string `json:"qty"`
+	QuoteQty     string `json:"quoteQty"`
+	Time         int64  `json:"time"`
+	IsBuyerMaker bool   `json:"isBuyerMaker"`
+	IsBestMatch  bool   `json:"isBestMatch"`
+}
+
+type binanceAggTrade struct {
+	AggTradeID   int64  `json:"a"`
+	Price        string `json:"p"`
+	Qty          string `json:"q"`
+	FirstTradeID int64  `json:"f"`
+	LastTradeID  int64  `json:"l"`
+	Time         int64  `json:"T"`
+	IsBuyerMaker bool   `json:"m"`
+	IsBestMatch  bool   `json:"M"`
+}
+
+// binanceKline is the positional array form returned by /api/v3/klines.
+type binanceKline []json.RawMessage
+
+type binanceServerTime struct {
+	ServerTime int64 `json:"serverTime"`
+}
+
+type binanceAvgPrice struct {
+	Mins      int    `json:"mins"`
+	Price     string `json:"price"`
+	CloseTime int64  `json:"closeTime"`
+}
+
+type binanceTicker24h struct {
+	Symbol             string `json:"symbol"`
+	PriceChange        string `json:"priceChange"`
+	PriceChangePercent string `json:"priceChangePercent"`
+	WeightedAvgPrice   string `json:"weightedAvgPrice"`
+	PrevClosePrice     string `json:"prevClosePrice"`
+	LastPrice          string `json:"lastPrice"`
+	LastQty            string `json:"lastQty"`
+	BidPrice           string `json:"bidPrice"`
+	BidQty             string `json:"bidQty"`
+	AskPrice           string `json:"askPrice"`
+	AskQty             string `json:"askQty"`
+	OpenPrice          string `json:"openPrice"`
+	HighPrice          string `json:"highPrice"`
+	LowPrice           string `json:"lowPrice"`
+	Volume             string `json:"volume"`
+	QuoteVolume        string `json:"quoteVolume"`
+	OpenTime           int64  `json:"openTime"`
+	CloseTime          int64  `json:"closeTime"`
+	FirstID            int64  `json:"firstId"`
+	LastID             int64  `json:"lastId"`
+	Count              int64  `json:"count"`
+}
+
+// decodeStrict decodes exactly one JSON value from data into v and rejects
+// trailing content.
+func decodeStrict(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return errors.New("unexpected trailing data")
+	}
+	return nil
+}
+
+// decimalParser collects the first parse error so conversions can stay linear.
+type decimalParser struct {
+	err error
+}
+
+func (p *decimalParser) required(field, value string) decimal.Decimal {
+	d, err := decimal.Parse(value)
+	if err != nil && p.err == nil {
+		p.err = fmt.Errorf("field %s: %w", field, err)
+	}
+	return d
+}
+
+func (p *decimalParser) optional(field, value string) *decimal.Decimal {
+	if value == "" {
+		return nil
+	}
+	d := p.required(field, value)
+	return &d
+}
+
+func requireSymbol(symbol string) error {
+	if symbol == "" {
+		return errors.New("field symbol: missing")
+	}
+	return nil
+}
+
+func parseServerTime(data []byte) (int64, error) {
+	var w binanceServerTime
+	if err := decodeStrict(data, &w); err != nil {
+		return 0, err
+	}
+	if w.ServerTime <= 0 {
+		return 0, errors.New("field serverTime: missing")
+	}
+	return w.ServerTime, nil
+}
+
+func parseExchangeInfo(data []byte) (*dto.ExchangeInfo, error) {
+	var w binanceExchangeInfo
+	if err := decodeStrict(data, &w); err != nil {
+		return nil, err
+	}
+	out := &dto.ExchangeInfo{
+		Timezone:   w.Timezone,
+		ServerTime: w.ServerTime,
+		RateLimits: make([]dto.RateLimit, 0, len(w.RateLimits)),
+		Symbols:    make([]dto.SymbolInfo, 0, len(w.Symbols)),
+	}
+	for _, rl := range w.RateLimits {
+		out.RateLimits = append(out.RateLimits, dto.RateLimit(rl))
+	}
+	for _, sym := range w.Symbols {
+		if err := requireSymbol(sym.Symbol); err != nil {
+			return nil, err
+		}
+		info := dto.SymbolInfo{
+			Symbol:                 sym.Symbol,
+			Status:                 sym.Status,
+			BaseAsset:              sym.BaseAsset,
+			BaseAssetPrecision:     sym.BaseAssetPrecision,
+			QuoteAsset:             sym.QuoteAsset,
+			QuoteAssetPrecision:    sym.QuoteAssetPrecision,
+			OrderTypes:             sym.OrderTypes,
+			IcebergAllowed:         sym.IcebergAllowed,
+			OcoAllowed:             sym.OcoAllowed,
+			IsSpotTradingAllowed:   sym.IsSpotTradingAllowed,
+			IsMarginTradingAllowed: sym.IsMarginTradingAllowed,
+			Filters:                make([]dto.SymbolFilter, 0, len(sym.Filters)),
+			Permissions:            sym.Permissions,
+		}
+		for _, f := range sym.Filters {
+			if f.FilterType == "" {
+				return nil, fmt.Errorf("symbol %s: filter without filterType", sym.Symbol)
+			}
+			p := &decimalParser{}
+			filter := dto.SymbolFilter{
+				FilterType:        f.FilterType,
+				MinPrice:          p.optional("minPrice", f.MinPrice),
+				MaxPrice:          p.optional("maxPrice", f.MaxPrice),
+				TickSize:          p.optional("tickSize", f.TickSize),
+				MinQty:            p.optional("minQty", f.MinQty),
+				MaxQty:            p.optional("maxQty", f.MaxQty),
+				StepSize:          p.optional("stepSize", f.StepSize),
+				MinNotional:       p.optional("minNotional", f.MinNotional),
+				MaxNotional:       p.optional("maxNotional", f.MaxNotional),
+				MultiplierUp:      p.optional("multiplierUp", f.MultiplierUp),
+				MultiplierDown:    p.optional("multiplierDown", f.MultiplierDown),
+				BidMultiplierUp:   p.optional("bidMultiplierUp", f.BidMultiplierUp),
+				BidMultiplierDown: p.optional("bidMultiplierDown", f.BidMultiplierDown),
+				AskMultiplierUp:   p.optional("askMultiplierUp", f.AskMultiplierUp),
+				AskMultiplierDown: p.optional("askMultiplierDown", f.AskMultiplierDown),
+				AvgPriceMins:      f.AvgPriceMins,
+				ApplyToMarket:     f.ApplyToMarket,
+				Limit:             f.Limit,
+				MaxNumOrders:      f.MaxNumOrders,
+				MaxNumAlgoOrders:  f.MaxNumAlgoOrders,
+			}
+			if p.err != nil {
+				return nil, fmt.Errorf("symbol %s filter %s: %w", sym.Symbol, f.FilterType, p.err)
+			}
+			info.Filters = append(info.Filters, filter)
+		}
+		out.Symbols = append(out.Symbols, info)
+	}
+	return out, nil
+}
+
+func (w binanceTickerPrice) toDTO() (dto.TickerPrice, error) {
+	if err := requireSymbol(w.Symbol); err != nil {
+		return dto.TickerPrice{}, err
+	}
+	p := &decimalParser{}
+	out := dto.TickerPrice{
+		Symbol: w.Symbol,
+		Price:  p.required("price", w.Price),
+	}
+	return out, p.err
+}
+
+func parseTickerPrice(data []byte) (*dto.TickerPrice, error) {
+	var w binanceTickerPrice
+	if err := decodeStrict(data, &w); err != nil {
+		return nil, err
+	}
+	out, err := w.toDTO()
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func parseTickerPrices(data []byte) ([]dto.TickerPrice, error) {
+	var w []binanceTickerPrice
+	if err := decodeStrict(data, &w); err != nil {
+		return nil, err
+	}
+	out := make([]dto.TickerPrice, 0, len(w))
+	for _, item := range w {
+		t, err := item.toDTO()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+func (w binanceBookTicker) toDTO() (dto.BookTicker, error) {
+	if err := requireSymbol(w.Symbol); err != nil {
+		return dto.BookTicker{}, err
+	}
+	p := &decimalParser{}
+	out := dto.BookTicker{
+		Symbol:   w.Symbol,
+		BidPrice: p.required("bidPrice", w.BidPrice),
+		BidQty:   p.required("bidQty", w.BidQty),
+		AskPrice: p.required("askPrice", w.AskPrice),
+		AskQty:   p.required("askQty", w.AskQty),
+	}
+	return out, p.err
+}
+
+func parseBookTicker(data []byte) (*dto.BookTicker, error) {
+	var w binanceBookTicker
+	if err := decodeStrict(data, &w); err != nil {
+		return nil, err
+	}
+	out, err := w.toDTO()
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func parseBookTickers(data []byte) ([]dto.BookTicker, error) {
+	var w []binanceBookTicker
+	if err := decodeStrict(data, &w); err != nil {
+		return nil, err
+	}
+	out := make([]dto.BookTicker, 0, len(w))
+	for _, item := range w {
+		t, err := item.toDTO()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+func parsePriceLevels(side string, levels [][2]string) ([]dto.PriceLevel, error) {
+	out := make([]dto.PriceLevel, 0, len(levels))
+	for i, level := range levels {
+		p := &decimalParser{}
+		pl := dto.PriceLevel{
+			Price:    p.required("price", level[0]),
+			Quantity: p.required("quantity", level[1]),
+		}
+		if p.err != nil {
+			return nil, fmt.Errorf("%s[%d]: %w", side, i, p.err)
+		}
+		out = append(out, pl)
+	}
+	return out, nil
+}
+
+func parseDepth(data []byte) (*dto.DepthSnapshot, error) {
+	var w binanceDepth
+	if err := decodeStrict(data, &w); err != nil {
+		return nil, err
+	}
+	if w.LastUpdateID <= 0 {
+		return nil, errors.New("field lastUpdateId: missing")
+	}
+	bids, err := parsePriceLevels("bids", w.Bids)
+	if err != nil {
+		return nil, err
+	}
+	asks, err := parsePriceLevels("asks", w.Asks)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.DepthSnapshot{LastUpdateID: w.LastUpdateID, Bids: bids, Asks: asks}, nil
+}
+
+func parseTrades(data []byte) ([]dto.Trade, error) {
+	var w []binanceTrade
+	if err := decodeStrict(data, &w); err != nil {
+		return nil, err
+	}
+	out := make([]dto.Trade, 0, len(w))
+	for i, item := range w {
+		p := &decimalParser{}
+		t := dto.Trade{
+			ID:           item.ID,
+			Price:        p.required("price", item.Price),
+			Qty:          p.required("qty", item.Qty),
+			QuoteQty:     p.required("quoteQty", item.QuoteQty),
+			Time:         item.Time,
+			IsBuyerMaker: item.IsBuyerMaker,
+			IsBestMatch:  item.IsBestMatch,
+		}
+		if p.err != nil {
+			return nil, fmt.Errorf("trade[%d]: %w", i, p.err)
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+func parseAggTrades(data []byte) ([]dto.AggTrade, error) {
+	var w []binanceAggTrade
+	if err := decodeStrict(data, &w); err != nil {
+		return nil, err
+	}
+	out := make([]dto.AggTrade, 0, len(w))
+	for i, item := range w {
+		p := &decimalParser{}
+		t := dto.AggTrade{
+			AggTradeID:   item.AggTradeID,
+			Price:        p.required("p", item.Price),
+			Qty:          p.required("q", item.Qty),
+			FirstTradeID: item.FirstTradeID,
+			LastTradeID:  item.LastTradeID,
+			Time:         item.Time,
+			IsBuyerMaker: item.IsBuyerMaker,
+			IsBestMatch:  item.IsBestMatch,
+		}
+		if p.err != nil {
+			return nil, fmt.Errorf("aggTrade[%d]: %w", i, p.err)
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+func (w binanceKline) toDTO() (dto.Kline, error) {
+	if len(w) < 11 {
+		return dto.Kline{}, fmt.Errorf("expected at least 11 fields, got %d", len(w))
+	}
+	var (
+		openTime, closeTime, trades int64
+		fields                      [8]string
+	)
+	if err := json.Unmarshal(w[0], &openTime); err != nil {
+		return dto.Kline{}, fmt.Errorf("field openTime: %w", err)
+	}
+	if err := json.Unmarshal(w[6], &closeTime); err != nil {
+		return dto.Kline{}, fmt.Errorf("field closeTime: %w", err)
+	}
+	if err := json.Unmarshal(w[8], &trades); err != nil {
+		return dto.Kline{}, fmt.Errorf("field numberOfTrades: %w", err)
+	}
+	for i, idx := range []int{1, 2, 3, 4, 5, 7, 9, 10} {
+		if err := json.Unmarshal(w[idx], &fields[i]); err != nil {
+			return dto.Kline{}, fmt.Errorf("field %d: %w", idx, err)
+		}
+	}
+	p := &decimalParser{}
+	out := dto.Kline{
+		OpenTime:                 openTime,
+		Open:                     p.required("open", fields[0]),
+		High:                     p.required("high", fields[1]),
+		Low:                      p.required("low", fields[2]),
+		Close:                    p.required("close", fields[3]),
+		Volume:                   p.required("volume", fields[4]),
+		CloseTime:                closeTime,
+		QuoteAssetVolume:         p.required("quoteAssetVolume", fields[5]),
+		NumberOfTrades:           trades,
+		TakerBuyBaseAssetVolume:  p.required("takerBuyBaseAssetVolume", fields[6]),
+		TakerBuyQuoteAssetVolume: p.required("takerBuyQuoteAssetVolume", fields[7]),
+	}
+	if p.err == nil && closeTime < openTime {
+		p.err = fmt.Errorf("closeTime %d before openTime %d", closeTime, openTime)
+	}
+	return out, p.err
+}
+
+func parseKlines(data []byte) ([]dto.Kline, error) {
+	var w []binanceKline
+	if err := decodeStrict(data, &w); err != nil {
+		return nil, err
+	}
+	out := make([]dto.Kline, 0, len(w))
+	for i, item := range w {
+		k, err := item.toDTO()
+		if err != nil {
+			return nil, fmt.Errorf("kline[%d]: %w", i, err)
+		}
+		out = append(out, k)
+	}
+	return out, nil
+}
+
+func parseAvgPrice(data []byte) (*dto.AvgPrice, error) {
+	var w binanceAvgPrice
+	if err := decodeStrict(data, &w); err != nil {
+		return nil, err
+	}
+	p := &decimalParser{}
+	out := &dto.AvgPrice{
+		Mins:      w.Mins,
+		Price:     p.required("price", w.Price),
+		CloseTime: w.CloseTime,
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return out, nil
+}
+
+func parseTicker24h(data []byte) (*dto.Ticker24h, error) {
+	var w binanceTicker24h
+	if err := decodeStrict(data, &w); err != nil {
+		return nil, err
+	}
+	if err := requireSymbol(w.Symbol); err != nil {
+		return nil, err
+	}
+	p := &decimalParser{}
+	out := &dto.Ticker24h{
+		Symbol:             w.Symbol,
+		PriceChange:        p.required("priceChange", w.PriceChange),
+		PriceChangePercent: p.required("priceChangePercent", w.PriceChangePercent),
+		WeightedAvgPrice:   p.required("weightedAvgPrice", w.WeightedAvgPrice),
+		PrevClosePrice:     p.required("prevClosePrice", w.PrevClosePrice),
+		LastPrice:          p.required("lastPrice", w.LastPrice),
+		LastQty:            p.required("lastQty", w.LastQty),
+		BidPrice:           p.required("bidPrice", w.BidPrice),
+		BidQty:             p.required("bidQty", w.BidQty),
+		AskPrice:           p.required("askPrice", w.AskPrice),
+		AskQty:             p.required("askQty", w.AskQty),
+		OpenPrice:          p.required("openPrice", w.OpenPrice),
+		HighPrice:          p.required("highPrice", w.HighPrice),
+		LowPrice:           p.required("lowPrice", w.LowPrice),
+		Volume:             p.required("volume", w.Volume),
+		QuoteVolume:        p.required("quoteVolume", w.QuoteVolume),
+		OpenTime:           w.OpenTime,
+		CloseTime:          w.CloseTime,
+		FirstID:            w.FirstID,
+		LastID:             w.LastID,
+		Count:              w.Count,
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return out, nil
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+const wireKline = `[1700000000000,"37000.10","37100.00","36950.5","37050.00","12.345",1700000059999,"457123.45",321,"6.1","225000.1","0"]`
+
+func TestParseKlines(t *testing.T) {
+	klines, err := parseKlines([]byte("[" + wireKline + "]"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	k := klines[0]
+	if k.OpenTime != 1700000000000 || k.CloseTime != 1700000059999 || k.NumberOfTrades != 321 {
+		t.Errorf("times and trades = %d %d %d", k.OpenTime, k.CloseTime, k.NumberOfTrades)
+	}
+	// Decimals keep the exact text Binance sent.
+	if k.Open.String() != "37000.10" || k.Low.String() != "36950.5" || k.TakerBuyQuoteAssetVolume.String() != "225000.1" {
+		t.Errorf("kline = %+v", k)
+	}
+}
+
+func TestParseWireRejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		parse func([]byte) error
+		data  string
+		want  string
+	}{
+		{"kline price in hex", parseKlinesErr, strings.Replace("["+wireKline+"]", `"37000.10"`, `"0x1F"`, 1), "field open"},
+		{"kline price as number", parseKlinesErr, strings.Replace("["+wireKline+"]", `"37000.10"`, `37000.10`, 1), "field 1"},
+		{"kline too short", parseKlinesErr, `[[1700000000000,"1"]]`, "expected at least 11 fields"},
+		{"kline closes before it opens", parseKlinesErr, strings.Replace("["+wireKline+"]", "1700000059999", "1699999999999", 1), "before openTime"},
+		{"trailing data", parseKlinesErr, "[" + wireKline + "] []", "trailing data"},
+		{"ticker without symbol", func(b []byte) error { _, err := parseTickerPrice(b); return err }, `{"price":"1.0"}`, "field symbol"},
+		{"ticker with underscores", func(b []byte) error { _, err := parseTickerPrice(b); return err }, `{"symbol":"BTCUSDT","price":"1_000"}`, "field price"},
+		{"depth without update id", func(b []byte) error { _, err := parseDepth(b); return err }, `{"bids":[],"asks":[]}`, "lastUpdateId"},
+		{"depth level without digits", func(b []byte) error { _, err := parseDepth(b); return err }, `{"lastUpdateId":1,"bids":[["5.","1"]],"asks":[]}`, "bids[0]"},
+		{"trade with a signed quantity", func(b []byte) error { _, err := parseTrades(b); return err },
+			`[{"id":1,"price":"1","qty":"+1","quoteQty":"1","time":1}]`, "trade[0]: field qty"},
+	}
+	for _, tt := range tests {
+		err := tt.parse([]byte(tt.data))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: err = %v, want it to mention %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestParseDepth(t *testing.T) {
+	depth, err := parseDepth([]byte(`{"lastUpdateId":42,"bids":[["100.10","2.000"]],"asks":[["100.20","0.5"],["100.30","1"]]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if depth.LastUpdateID != 42 || len(depth.Bids) != 1 || len(depth.Asks) != 2 {
+		t.Fatalf("depth = %+v", depth)
+	}
+	if depth.Bids[0].Price.String() != "100.10" || depth.Asks[1].Quantity.String() != "1" {
+		t.Errorf("levels = %+v %+v", depth.Bids, depth.Asks)
+	}
+}
+
+func parseKlinesErr(data []byte) error {
+	_, err := parseKlines(data)
+	return err
+}
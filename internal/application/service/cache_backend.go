@@ -0,0 +1,324 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/ntdat104/go-finance-dataset/internal/application/dto"
+	"github.com/ntdat104/go-finance-dataset/pkg/config"
+	"github.com/ntdat104/go-finance-dataset/pkg/resp"
+)
+
+// Cache backends selectable with cache.backend.
+const (
+	CacheBackendMemory = "memory"
+	CacheBackendRedis  = "redis"
+	// CacheBackendTiered keeps a per-replica memory tier in front of a shared Redis tier.
+	CacheBackendTiered = "tiered"
+)
+
+// redisScanCount is the COUNT hint used when scanning keys.
+const redisScanCount = 1000
+
+// NewCacheSvc creates the cache backend selected by cfg.Backend.
+func NewCacheSvc(cfg config.Cache) (LocalCacheSvc, error) {
+	switch cfg.Backend {
+	case "", CacheBackendMemory:
+		return NewLocalCacheSvc(cfg.MaxEntries, cfg.MaxBytes), nil
+	case CacheBackendRedis:
+		return NewRedisCacheSvc(newRedisClient(cfg.Redis), cfg.Redis.KeyPrefix), nil
+	case CacheBackendTiered:
+		l1 := NewLocalCacheSvc(cfg.MaxEntries, cfg.MaxBytes)
+		l2 := &redisCacheSvc{client: newRedisClient(cfg.Redis), prefix: cfg.Redis.KeyPrefix}
+		return newTieredCacheSvc(l1, l2), nil
+	}
+	return nil, fmt.Errorf("unknown cache backend %q", cfg.Backend)
+}
+
+func newRedisClient(cfg config.Redis) *resp.Client {
+	return resp.NewClient(resp.Options{
+		Addr:        cfg.Addr,
+		Password:    cfg.Password,
+		DB:          cfg.DB,
+		PoolSize:    cfg.PoolSize,
+		DialTimeout: cfg.DialTimeout,
+		IOTimeout:   cfg.IOTimeout,
+	})
+}
+
+// cacheEnvelope is how values are serialized for shared backends. FetchedAt
+// is only set for cachedValue entries; ExpiresAt lets a reader compute the
+// remaining ttl without another round trip.
+type cacheEnvelope struct {
+	Data      json.RawMessage `json:"data"`
+	FetchedAt int64           `json:"fetched_at,omitempty"`
+	ExpiresAt int64           `json:"expires_at"`
+}
+
+func encodeCacheValue(value any, expireTime time.Time) ([]byte, error) {
+	env := cacheEnvelope{ExpiresAt: expireTime.UnixMilli()}
+	if cached, ok := value.(cachedValue); ok {
+		env.FetchedAt = cached.fetchedAt.UnixMilli()
+		value = cached.data
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	env.Data = data
+	return json.Marshal(env)
+}
+
+// decodeCacheValue reverses encodeCacheValue. Data comes back as
+// json.RawMessage, which getWithCache decodes into the type it expects.
+func decodeCacheValue(payload []byte) (any, time.Time, error) {
+	var env cacheEnvelope
+	if err := json.Unmarshal(payload, &env); err != nil {
+		return nil, time.Time{}, err
+	}
+	expireTime := time.UnixMilli(env.ExpiresAt)
+	if env.FetchedAt != 0 {
+		return cachedValue{data: env.Data, fetchedAt: time.UnixMilli(env.FetchedAt)}, expireTime, nil
+	}
+	return env.Data, expireTime, nil
+}
+
+// redisCacheSvc stores serialized values in Redis, or anything speaking RESP,
+// so replicas share one cache. Backend errors are logged and treated as misses,
+// the cache must never fail a request.
+type redisCacheSvc struct {
+	client *resp.Client
+	prefix string
+	hits   atomic.Int64
+	misses atomic.Int64
+	errors atomic.Int64
+}
+
+// NewRedisCacheSvc creates a Redis backed cache. prefix namespaces the keys.
+func NewRedisCacheSvc(client *resp.Client, prefix string) LocalCacheSvc {
+	return &redisCacheSvc{client: client, prefix: prefix}
+}
+
+func (c *redisCacheSvc) fail(op, key string, err error) {
+	c.errors.Add(1)
+	log.Printf("Failed to %s cache key %s: %v", op, key, err)
+}
+
+func (c *redisCacheSvc) Set(key string, value any, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	payload, err := encodeCacheValue(value, time.Now().Add(ttl))
+	if err != nil {
+		c.fail("encode", key, err)
+		return
+	}
+	ms := max(ttl.Milliseconds(), 1)
+	if _, err := c.client.Do("SET", c.prefix+key, string(payload), "PX", strconv.FormatInt(ms, 10)); err != nil {
+		c.fail("set", key, err)
+	}
+}
+
+// getWithExpiry reads and decodes an entry.
+func (c *redisCacheSvc) getWithExpiry(key string) (any, time.Time, bool) {
+	payload, err := c.client.Bytes("GET", c.prefix+key)
+	if err != nil {
+		if !errors.Is(err, resp.ErrNil) {
+			c.fail("get", key, err)
+		}
+		return nil, time.Time{}, false
+	}
+	value, expireTime, err := decodeCacheValue(payload)
+	if err != nil {
+		c.fail("decode", key, err)
+		return nil, time.Time{}, false
+	}
+	return value, expireTime, true
+}
+
+func (c *redisCacheSvc) Get(key string) (any, bool) {
+	value, _, ok := c.getWithExpiry(key)
+	if ok {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+	return value, ok
+}
+
+func (c *redisCacheSvc) Peek(key string) (any, bool) {
+	value, _, ok := c.getWithExpiry(key)
+	return value, ok
+}
+
+func (c *redisCacheSvc) GetExpireTime(key string) (*time.Time, bool) {
+	_, expireTime, ok := c.getWithExpiry(key)
+	if !ok {
+		return nil, false
+	}
+	return &expireTime, true
+}
+
+func (c *redisCacheSvc) Del(key string) {
+	if _, err := c.client.Do("DEL", c.prefix+key); err != nil {
+		c.fail("delete", key, err)
+	}
+}
+
+func (c *redisCacheSvc) Has(key string) bool {
+	n, err := c.client.Int("EXISTS", c.prefix+key)
+	if err != nil {
+		c.fail("check", key, err)
+		return false
+	}
+	return n > 0
+}
+
+func (c *redisCacheSvc) Keys(prefix string) []string {
+	pattern := resp.EscapeGlob(c.prefix+prefix) + "*"
+	keys := make([]string, 0)
+	cursor := "0"
+	for {
+		reply, err := c.client.Do("SCAN", cursor, "MATCH", pattern, "COUNT", strconv.Itoa(redisScanCount))
+		if err != nil {
+			c.fail("scan", prefix+"*", err)
+			break
+		}
+		items, ok := reply.([]any)
+		if !ok || len(items) != 2 {
+			c.fail("scan", prefix+"*", errors.New("unexpected SCAN reply"))
+			break
+		}
+		next, _ := items[0].([]byte)
+		batch, _ := items[1].([]any)
+		for _, item := range batch {
+			if key, ok := item.([]byte); ok {
+				keys = append(keys, string(key[len(c.prefix):]))
+			}
+		}
+		cursor = string(next)
+		if cursor == "0" || cursor == "" {
+			break
+		}
+	}
+	slices.Sort(keys)
+	return slices.Compact(keys)
+}
+
+func (c *redisCacheSvc) DelPrefix(prefix string) int {
+	keys := c.Keys(prefix)
+	deleted := 0
+	for batch := range slices.Chunk(keys, redisScanCount) {
+		args := make([]string, 0, len(batch)+1)
+		args = append(args, "DEL")
+		for _, key := range batch {
+			args = append(args, c.prefix+key)
+		}
+		n, err := c.client.Int(args...)
+		if err != nil {
+			c.fail("delete", prefix+"*", err)
+			continue
+		}
+		deleted += int(n)
+	}
+	return deleted
+}
+
+// Hydrate is a no-op, Redis only holds serialized values.
+func (c *redisCacheSvc) Hydrate(key string, value any) {}
+
+func (c *redisCacheSvc) Stats() dto.LocalCacheStats {
+	return dto.LocalCacheStats{
+		Backend: CacheBackendRedis,
+		Hits:    c.hits.Load(),
+		Misses:  c.misses.Load(),
+		Errors:  c.errors.Load(),
+	}
+}
+
+// tieredCacheSvc reads through a per-replica memory tier (L1) to a shared
+// tier (L2), and writes to both. Deletes reach L2 and the L1 of this replica
+// only; other replicas keep their L1 copy until it expires.
+type tieredCacheSvc struct {
+	l1 LocalCacheSvc
+	l2 *redisCacheSvc
+}
+
+func newTieredCacheSvc(l1 LocalCacheSvc, l2 *redisCacheSvc) LocalCacheSvc {
+	return &tieredCacheSvc{l1: l1, l2: l2}
+}
+
+func (c *tieredCacheSvc) Set(key string, value any, ttl time.Duration) {
+	c.l1.Set(key, value, ttl)
+	c.l2.Set(key, value, ttl)
+}
+
+func (c *tieredCacheSvc) Get(key string) (any, bool) {
+	if value, ok := c.l1.Get(key); ok {
+		return value, true
+	}
+	value, expireTime, ok := c.l2.getWithExpiry(key)
+	if !ok {
+		c.l2.misses.Add(1)
+		return nil, false
+	}
+	c.l2.hits.Add(1)
+	// Promote with the remaining ttl so both tiers expire together.
+	if ttl := time.Until(expireTime); ttl > 0 {
+		c.l1.Set(key, value, ttl)
+	}
+	return value, true
+}
+
+func (c *tieredCacheSvc) Peek(key string) (any, bool) {
+	if value, ok := c.l1.Peek(key); ok {
+		return value, true
+	}
+	return c.l2.Peek(key)
+}
+
+func (c *tieredCacheSvc) GetExpireTime(key string) (*time.Time, bool) {
+	if expireTime, ok := c.l1.GetExpireTime(key); ok {
+		return expireTime, true
+	}
+	return c.l2.GetExpireTime(key)
+}
+
+func (c *tieredCacheSvc) Del(key string) {
+	c.l1.Del(key)
+	c.l2.Del(key)
+}
+
+func (c *tieredCacheSvc) Has(key string) bool {
+	_, ok := c.Get(key)
+	return ok
+}
+
+func (c *tieredCacheSvc) Keys(prefix string) []string {
+	keys := append(c.l1.Keys(prefix), c.l2.Keys(prefix)...)
+	slices.Sort(keys)
+	return slices.Compact(keys)
+}
+
+func (c *tieredCacheSvc) DelPrefix(prefix string) int {
+	local := c.l1.DelPrefix(prefix)
+	return max(local, c.l2.DelPrefix(prefix))
+}
+
+func (c *tieredCacheSvc) Hydrate(key string, value any) {
+	c.l1.Hydrate(key, value)
+}
+
+func (c *tieredCacheSvc) Stats() dto.LocalCacheStats {
+	stats := c.l1.Stats()
+	stats.Backend = CacheBackendTiered
+	l2 := c.l2.Stats()
+	stats.L2 = &l2
+	return stats
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ntdat104/go-finance-dataset/internal/application/dto"
+	"github.com/ntdat104/go-finance-dataset/pkg/config"
+	"github.com/ntdat104/go-finance-dataset/pkg/resp"
+)
+
+// startRedis serves an in-process RESP server and returns a client of it.
+func startRedis(t *testing.T) *resp.Client {
+	t.Helper()
+	srv := resp.NewServer()
+	addr, err := srv.ListenAndServe("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newRedisClient(config.Redis{Addr: addr, DialTimeout: time.Second, IOTimeout: time.Second})
+	t.Cleanup(func() {
+		client.Close()
+		srv.Close()
+	})
+	return client
+}
+
+func TestRedisCacheSetGetDel(t *testing.T) {
+	c := NewRedisCacheSvc(startRedis(t), "test:")
+	c.Set("spot_depth:BTCUSDT", map[string]int{"a": 1}, time.Minute)
+	c.Set("spot_depth:ETHUSDT", 2, time.Minute)
+	c.Set("spot_klines:BTCUSDT", 3, time.Minute)
+
+	value, ok := c.Get("spot_depth:BTCUSDT")
+	if !ok || string(value.(json.RawMessage)) != `{"a":1}` {
+		t.Fatalf("Get = %v, %v; want the raw JSON", value, ok)
+	}
+	if !c.Has("spot_depth:ETHUSDT") {
+		t.Fatal("Has = false for a set key")
+	}
+	if got := c.Keys("spot_depth:"); len(got) != 2 || got[0] != "spot_depth:BTCUSDT" || got[1] != "spot_depth:ETHUSDT" {
+		t.Fatalf("Keys = %v, want the two depth keys without the backend prefix", got)
+	}
+
+	c.Del("spot_depth:BTCUSDT")
+	if _, ok := c.Get("spot_depth:BTCUSDT"); ok {
+		t.Fatal("Get found a deleted key")
+	}
+	if n := c.DelPrefix("spot_"); n != 2 {
+		t.Fatalf("DelPrefix = %d, want 2", n)
+	}
+	if keys := c.Keys(""); len(keys) != 0 {
+		t.Fatalf("Keys after DelPrefix = %v, want none", keys)
+	}
+
+	stats := c.Stats()
+	if stats.Hits != 1 || stats.Misses != 1 || stats.Errors != 0 {
+		t.Fatalf("stats = %+v, want 1 hit, 1 miss and no errors", stats)
+	}
+}
+
+func TestRedisCacheExpiry(t *testing.T) {
+	c := NewRedisCacheSvc(startRedis(t), "")
+	c.Set("short", 1, 30*time.Millisecond)
+	expireTime, ok := c.GetExpireTime("short")
+	if !ok || time.Until(*expireTime) > 30*time.Millisecond {
+		t.Fatalf("GetExpireTime = %v, %v; want within the ttl", expireTime, ok)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if _, ok := c.Get("short"); ok {
+		t.Fatal("Get found an expired key")
+	}
+	// A non-positive ttl is not stored at all.
+	c.Set("none", 1, 0)
+	if c.Has("none") {
+		t.Fatal("Has = true for a key set without ttl")
+	}
+}
+
+func TestRedisCacheFetchedAtRoundTrip(t *testing.T) {
+	c := NewRedisCacheSvc(startRedis(t), "")
+	fetchedAt := time.UnixMilli(time.Now().UnixMilli())
+	klines := []dto.Kline{{OpenTime: 60000, CloseTime: 119999, NumberOfTrades: 7}}
+	c.Set("spot_klines:BTCUSDT-1m-1", cachedValue{data: klines, fetchedAt: fetchedAt}, time.Minute)
+
+	value, ok := c.Get("spot_klines:BTCUSDT-1m-1")
+	if !ok {
+		t.Fatal("Get missed")
+	}
+	entry, ok := value.(cachedValue)
+	if !ok {
+		t.Fatalf("Get = %T, want cachedValue", value)
+	}
+	if !entry.fetchedAt.Equal(fetchedAt) {
+		t.Fatalf("fetchedAt = %v, want %v", entry.fetchedAt, fetchedAt)
+	}
+	var got []dto.Kline
+	if err := json.Unmarshal(entry.data.(json.RawMessage), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].NumberOfTrades != 7 {
+		t.Fatalf("data = %+v, want the stored klines", got)
+	}
+}
+
+func TestTieredCacheHydratesL1OnL2Hit(t *testing.T) {
+	client := startRedis(t)
+	l1 := NewLocalCacheSvc(0, 0)
+	l2 := &redisCacheSvc{client: client}
+	tiered := newTieredCacheSvc(l1, l2)
+
+	// Another replica wrote the entry, so only the shared tier has it.
+	fetchedAt := time.UnixMilli(time.Now().UnixMilli())
+	NewRedisCacheSvc(client, "").Set("spot_avgprice:BTCUSDT", cachedValue{data: []int{1, 2}, fetchedAt: fetchedAt}, time.Minute)
+	if _, ok := l1.Peek("spot_avgprice:BTCUSDT"); ok {
+		t.Fatal("L1 has the entry before any read")
+	}
+
+	value, ok := tiered.Get("spot_avgprice:BTCUSDT")
+	if !ok {
+		t.Fatal("tiered Get missed an L2 entry")
+	}
+	promoted, ok := l1.Peek("spot_avgprice:BTCUSDT")
+	if !ok {
+		t.Fatal("L2 hit was not promoted to L1")
+	}
+	expireTime, _ := l1.GetExpireTime("spot_avgprice:BTCUSDT")
+	if remaining := time.Until(*expireTime); remaining <= 0 || remaining > time.Minute {
+		t.Fatalf("L1 expires in %v, want the remaining L2 ttl", remaining)
+	}
+
+	// Decoding the raw entry swaps the typed value into L1 only.
+	c := &restClient{namespace: "spot", localCacheSvc: tiered}
+	data, ok := decodeCached[[]int](c, "spot_avgprice:BTCUSDT", value.(cachedValue))
+	if !ok || len(data) != 2 {
+		t.Fatalf("decodeCached = %v, %v", data, ok)
+	}
+	promoted, _ = l1.Peek("spot_avgprice:BTCUSDT")
+	entry := promoted.(cachedValue)
+	if _, ok := entry.data.([]int); !ok || !entry.fetchedAt.Equal(fetchedAt) {
+		t.Fatalf("L1 holds %T fetched at %v, want the decoded []int fetched at %v", entry.data, entry.fetchedAt, fetchedAt)
+	}
+	if raw, _ := l2.Peek("spot_avgprice:BTCUSDT"); raw.(cachedValue).data.(json.RawMessage) == nil {
+		t.Fatal("L2 entry was changed by hydration")
+	}
+
+	tiered.Del("spot_avgprice:BTCUSDT")
+	if tiered.Has("spot_avgprice:BTCUSDT") {
+		t.Fatal("tiered Has = true after Del")
+	}
+}
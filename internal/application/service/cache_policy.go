@@ -0,0 +1,88 @@
+package service
+
+import (
+	"time"
+
+	"github.com/ntdat104/go-finance-dataset/pkg/config"
+)
+
+const (
+	defaultCacheTTL             = 1 * time.Minute
+	defaultCacheRefreshInterval = 500 * time.Millisecond
+)
+
+// cachePolicy is a resolved config.CachePolicy.
+type cachePolicy struct {
+	ttl               time.Duration
+	refreshInterval   time.Duration
+	maxStaleness      time.Duration
+	serveStaleOnError bool
+}
+
+// retention is how long an entry is kept: its ttl, plus the window in which it
+// may still be served when upstream fails.
+func (p cachePolicy) retention() time.Duration {
+	if p.serveStaleOnError {
+		return p.ttl + p.maxStaleness
+	}
+	return p.ttl
+}
+
+// cachePolicies resolves the policy of a cache name, and of a kline interval.
+type cachePolicies struct {
+	def        cachePolicy
+	byName     map[string]cachePolicy
+	byInterval map[string]map[string]cachePolicy
+}
+
+func newCachePolicies(cfg config.Cache) cachePolicies {
+	def := mergeCachePolicy(cachePolicy{
+		ttl:             defaultCacheTTL,
+		refreshInterval: defaultCacheRefreshInterval,
+	}, cfg.Default)
+
+	p := cachePolicies{
+		def:        def,
+		byName:     make(map[string]cachePolicy),
+		byInterval: make(map[string]map[string]cachePolicy),
+	}
+	for name, named := range cfg.Policies {
+		policy := mergeCachePolicy(def, named)
+		p.byName[name] = policy
+		for interval, override := range named.Intervals {
+			if p.byInterval[name] == nil {
+				p.byInterval[name] = make(map[string]cachePolicy)
+			}
+			p.byInterval[name][interval] = mergeCachePolicy(policy, override)
+		}
+	}
+	return p
+}
+
+func mergeCachePolicy(base cachePolicy, override config.CachePolicy) cachePolicy {
+	if override.TTL > 0 {
+		base.ttl = override.TTL
+	}
+	if override.RefreshInterval > 0 {
+		base.refreshInterval = override.RefreshInterval
+	}
+	if override.MaxStaleness > 0 {
+		base.maxStaleness = override.MaxStaleness
+	}
+	if override.ServeStaleOnError != nil {
+		base.serveStaleOnError = *override.ServeStaleOnError
+	}
+	return base
+}
+
+func (p cachePolicies) get(cacheName, interval string) cachePolicy {
+	if interval != "" {
+		if policy, ok := p.byInterval[cacheName][intervalConfigKey(interval)]; ok {
+			return policy
+		}
+	}
+	if policy, ok := p.byName[cacheName]; ok {
+		return policy
+	}
+	return p.def
+}
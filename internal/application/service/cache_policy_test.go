@@ -0,0 +1,62 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ntdat104/go-finance-dataset/pkg/config"
+)
+
+func TestCachePoliciesResolve(t *testing.T) {
+	yes, no := true, false
+	policies := newCachePolicies(config.Cache{
+		Default: config.CachePolicy{RefreshInterval: time.Second, MaxStaleness: time.Hour},
+		Policies: map[string]config.CachePolicy{
+			"ticker": {TTL: 5 * time.Second, ServeStaleOnError: &yes},
+			"klines": {
+				TTL:               30 * time.Second,
+				ServeStaleOnError: &yes,
+				Intervals: map[string]config.CachePolicy{
+					"1d":  {TTL: time.Hour, ServeStaleOnError: &no},
+					"1mo": {TTL: 24 * time.Hour, MaxStaleness: 7 * 24 * time.Hour},
+				},
+			},
+		},
+	})
+
+	tests := []struct {
+		name, cacheName, interval string
+		want                      cachePolicy
+	}{
+		{"unknown name uses the default", "depth", "", cachePolicy{
+			ttl: defaultCacheTTL, refreshInterval: time.Second, maxStaleness: time.Hour}},
+		{"named policy inherits the default", "ticker", "", cachePolicy{
+			ttl: 5 * time.Second, refreshInterval: time.Second, maxStaleness: time.Hour, serveStaleOnError: true}},
+		{"interval is ignored without overrides", "ticker", "1d", cachePolicy{
+			ttl: 5 * time.Second, refreshInterval: time.Second, maxStaleness: time.Hour, serveStaleOnError: true}},
+		{"interval without an override uses the name", "klines", "1m", cachePolicy{
+			ttl: 30 * time.Second, refreshInterval: time.Second, maxStaleness: time.Hour, serveStaleOnError: true}},
+		{"interval override turns stale serving off", "klines", "1d", cachePolicy{
+			ttl: time.Hour, refreshInterval: time.Second, maxStaleness: time.Hour}},
+		{"monthly interval is keyed 1mo", "klines", "1M", cachePolicy{
+			ttl: 24 * time.Hour, refreshInterval: time.Second, maxStaleness: 7 * 24 * time.Hour, serveStaleOnError: true}},
+		{"1m is not the monthly override", "klines", "1m", cachePolicy{
+			ttl: 30 * time.Second, refreshInterval: time.Second, maxStaleness: time.Hour, serveStaleOnError: true}},
+	}
+	for _, tt := range tests {
+		if got := policies.get(tt.cacheName, tt.interval); got != tt.want {
+			t.Errorf("%s: get(%q, %q) = %+v, want %+v", tt.name, tt.cacheName, tt.interval, got, tt.want)
+		}
+	}
+}
+
+func TestCachePolicyRetention(t *testing.T) {
+	policy := cachePolicy{ttl: time.Minute, maxStaleness: time.Hour}
+	if got := policy.retention(); got != time.Minute {
+		t.Errorf("retention without stale serving = %v, want the ttl", got)
+	}
+	policy.serveStaleOnError = true
+	if got := policy.retention(); got != time.Minute+time.Hour {
+		t.Errorf("retention with stale serving = %v, want ttl plus max staleness", got)
+	}
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ntdat104/go-finance-dataset/internal/application/dto"
+	"github.com/ntdat104/go-finance-dataset/pkg/config"
+	"github.com/ntdat104/go-finance-dataset/pkg/datasource"
+	"github.com/ntdat104/go-finance-dataset/pkg/decimal"
+	"github.com/ntdat104/go-finance-dataset/pkg/httpclient"
+)
+
+const (
+	ExchangeCoinbase = "coinbase"
+
+	coinbaseBaseURL = "https://api.exchange.coinbase.com"
+	// coinbaseMaxTrades and coinbaseMaxCandles are the most rows Coinbase returns per request.
+	coinbaseMaxTrades  = 1000
+	coinbaseMaxCandles = 300
+)
+
+// coinbaseProductID matches a Coinbase product id such as BTC-USD.
+var coinbaseProductID = regexp.MustCompile(`^[A-Z0-9]+-[A-Z0-9]+$`)
+
+// coinbaseGranularities maps the kline intervals Coinbase supports to its candle granularity.
+var coinbaseGranularities = map[string]time.Duration{
+	"1m":  time.Minute,
+	"5m":  5 * time.Minute,
+	"15m": 15 * time.Minute,
+	"1h":  time.Hour,
+	"6h":  6 * time.Hour,
+	"1d":  24 * time.Hour,
+}
+
+// coinbaseSvc serves market data from the Coinbase Exchange public REST API.
+// Products are addressed either by their Coinbase id (BTC-USD) or by the
+// exchange-independent symbol (BTCUSD).
+type coinbaseSvc struct {
+	*restClient
+	baseURL string
+}
+
+// NewCoinbaseProvider creates the Coinbase provider. An empty baseURL uses the production API.
+func NewCoinbaseProvider(baseURL string, httpClient httpclient.Client, localCacheSvc LocalCacheSvc, cacheCfg config.Cache) MarketDataProvider {
+	if baseURL == "" {
+		baseURL = coinbaseBaseURL
+	}
+	return &coinbaseSvc{
+		restClient: newRestClient(ExchangeCoinbase, nil, httpClient, localCacheSvc, cacheCfg),
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+func (s *coinbaseSvc) Exchange() string {
+	return ExchangeCoinbase
+}
+
+// GetSymbols lists the Coinbase products.
+func (s *coinbaseSvc) GetSymbols(ctx context.Context) ([]dto.MarketSymbol, error) {
+	return getWithCache(ctx, s.restClient, "products", "global", s.baseURL+"/products", nil, parseCoinbaseProducts)
+}
+
+// productID maps symbol to a Coinbase product id.
+func (s *coinbaseSvc) productID(ctx context.Context, symbol string) (string, error) {
+	if strings.Contains(symbol, "-") {
+		id := strings.ToUpper(symbol)
+		if !coinbaseProductID.MatchString(id) {
+			return "", fmt.Errorf("%w %q", ErrInvalidSymbol, symbol)
+		}
+		return id, nil
+	}
+	products, err := s.GetSymbols(datasource.Detach(ctx))
+	if err != nil {
+		return "", err
+	}
+	symbol = strings.ToUpper(symbol)
+	for _, p := range products {
+		if p.Symbol == symbol {
+			return p.ExchangeSymbol, nil
+		}
+	}
+	return "", fmt.Errorf("%w %q on %s", ErrUnknownSymbol, symbol, ExchangeCoinbase)
+}
+
+// GetTicker combines the product ticker with its 24h stats.
+func (s *coinbaseSvc) GetTicker(ctx context.Context, symbol string) (*dto.MarketTicker, error) {
+	id, err := s.productID(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	ticker, err := getWithCache(ctx, s.restClient, "tickerprice", id, fmt.Sprintf("%s/products/%s/ticker", s.baseURL, id), nil, parseCoinbaseTicker)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := getWithCache(ctx, s.restClient, "ticker24hr", id, fmt.Sprintf("%s/products/%s/stats", s.baseURL, id), nil, parseCoinbaseStats)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &decimalParser{}
+	out := &dto.MarketTicker{
+		Symbol:      coinbaseSymbol(id),
+		LastPrice:   p.required("price", ticker.Price),
+		BidPrice:    p.required("bid", ticker.Bid),
+		AskPrice:    p.required("ask", ticker.Ask),
+		OpenPrice:   p.required("open", stats.Open),
+		HighPrice:   p.required("high", stats.High),
+		LowPrice:    p.required("low", stats.Low),
+		Volume:      p.required("volume", stats.Volume),
+		QuoteVolume: decimal.Zero,
+	}
+	if p.err != nil {
+		return nil, fmt.Errorf("error decoding ticker of %s: %w", id, p.err)
+	}
+	if out.Time, err = parseCoinbaseTime("time", ticker.Time); err != nil {
+		return nil, fmt.Errorf("error decoding ticker of %s: %w", id, err)
+	}
+	return out, nil
+}
+
+// GetBook returns the best bid and ask from the level 1 book.
+func (s *coinbaseSvc) GetBook(ctx context.Context, symbol string) (*dto.BookTicker, error) {
+	id, err := s.productID(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	params := map[string]string{"level": "1"}
+	book, err := getWithCache(ctx, s.restClient, "bookticker", id, fmt.Sprintf("%s/products/%s/book", s.baseURL, id), params, parseCoinbaseBook)
+	if err != nil {
+		return nil, err
+	}
+	out := &dto.BookTicker{Symbol: coinbaseSymbol(id)}
+	if len(book.Bids) > 0 {
+		out.BidPrice, out.BidQty = book.Bids[0].Price, book.Bids[0].Quantity
+	}
+	if len(book.Asks) > 0 {
+		out.AskPrice, out.AskQty = book.Asks[0].Price, book.Asks[0].Quantity
+	}
+	return out, nil
+}
+
+// GetDepth returns the top limit levels of the aggregated level 2 book.
+// The full book is cached once per product and cut per request.
+func (s *coinbaseSvc) GetDepth(ctx context.Context, symbol string, limit int) (*dto.DepthSnapshot, error) {
+	id, err := s.productID(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	limit = max(limit, 1)
+	params := map[string]string{"level": "2"}
+	book, err := getWithCache(ctx, s.restClient, "depth", id, fmt.Sprintf("%s/products/%s/book", s.baseURL, id), params, parseCoinbaseBook)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.DepthSnapshot{
+		LastUpdateID: book.LastUpdateID,
+		Bids:         book.Bids[:min(limit, len(book.Bids))],
+		Asks:         book.Asks[:min(limit, len(book.Asks))],
+	}, nil
+}
+
+// GetTrades returns the latest trades.
+func (s *coinbaseSvc) GetTrades(ctx context.Context, symbol string, limit int) ([]dto.Trade, error) {
+	id, err := s.productID(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	limit = min(max(limit, 1), coinbaseMaxTrades)
+	params := map[string]string{"limit": strconv.Itoa(limit)}
+	return getWithCache(ctx, s.restClient, "recenttrades", fmt.Sprintf("%s-%d", id, limit), fmt.Sprintf("%s/products/%s/trades", s.baseURL, id), params, parseCoinbaseTrades)
+}
+
+// GetKlines returns the latest limit candles, oldest first. Coinbase always
+// answers with its maximum of 300 candles, so they are cached once per interval.
+func (s *coinbaseSvc) GetKlines(ctx context.Context, symbol, interval string, limit int) ([]dto.Kline, error) {
+	granularity, ok := coinbaseGranularities[interval]
+	if !ok {
+		return nil, fmt.Errorf("%w %q on %s", ErrUnsupportedInterval, interval, ExchangeCoinbase)
+	}
+	id, err := s.productID(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	params := map[string]string{"granularity": strconv.FormatInt(int64(granularity.Seconds()), 10)}
+	klines, err := getWithCache(ctx, s.restClient, "klines", fmt.Sprintf("%s-%s", id, interval), fmt.Sprintf("%s/products/%s/candles", s.baseURL, id), params, parseCoinbaseCandles(granularity))
+	if err != nil {
+		return nil, err
+	}
+	return klines[len(klines)-min(max(limit, 1), coinbaseMaxCandles, len(klines)):], nil
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ntdat104/go-finance-dataset/internal/application/dto"
+	"github.com/ntdat104/go-finance-dataset/pkg/config"
+	"github.com/ntdat104/go-finance-dataset/pkg/decimal"
+	"github.com/ntdat104/go-finance-dataset/pkg/fixture"
+)
+
+// startFixtures serves the committed fixtures of exchange and returns their base URL.
+func startFixtures(t *testing.T, exchange string) string {
+	t.Helper()
+	srv := fixture.NewServer(filepath.Join("..", "..", "..", "fixtures", exchange), "")
+	baseURL, err := srv.ListenAndServe("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { srv.Close() })
+	return baseURL
+}
+
+func newFixtureProvider(t *testing.T, exchange string, newProvider func(string, *http.Client, LocalCacheSvc, config.Cache) MarketDataProvider) MarketDataProvider {
+	t.Helper()
+	return newProvider(startFixtures(t, exchange), &http.Client{Timeout: 5 * time.Second}, NewLocalCacheSvc(0, 0), config.Cache{})
+}
+
+func assertDecimal(t *testing.T, field string, got decimal.Decimal, want string) {
+	t.Helper()
+	if got.Cmp(decimal.MustParse(want)) != 0 {
+		t.Errorf("%s = %s, want %s", field, got, want)
+	}
+}
+
+func newCoinbaseFixtureProvider(t *testing.T) MarketDataProvider {
+	return newFixtureProvider(t, ExchangeCoinbase, func(baseURL string, c *http.Client, cache LocalCacheSvc, cfg config.Cache) MarketDataProvider {
+		return NewCoinbaseProvider(baseURL, c, cache, cfg)
+	})
+}
+
+func TestCoinbaseSymbols(t *testing.T) {
+	p := newCoinbaseFixtureProvider(t)
+	symbols, err := p.GetSymbols(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(symbols) != 5 {
+		t.Fatalf("%d symbols, want 5", len(symbols))
+	}
+	want := dto.MarketSymbol{Symbol: "BTCUSD", ExchangeSymbol: "BTC-USD", BaseAsset: "BTC", QuoteAsset: "USD", Status: "online", Active: true}
+	if symbols[0] != want {
+		t.Fatalf("symbols[0] = %+v, want %+v", symbols[0], want)
+	}
+}
+
+func TestCoinbaseTicker(t *testing.T) {
+	p := newCoinbaseFixtureProvider(t)
+	// Both the exchange-independent symbol and the product id resolve.
+	for _, symbol := range []string{"BTCUSD", "btc-usd"} {
+		ticker, err := p.GetTicker(context.Background(), symbol)
+		if err != nil {
+			t.Fatalf("GetTicker(%q): %v", symbol, err)
+		}
+		if ticker.Symbol != "BTCUSD" {
+			t.Errorf("Symbol = %q, want BTCUSD", ticker.Symbol)
+		}
+		assertDecimal(t, "LastPrice", ticker.LastPrice, "67000.50")
+		assertDecimal(t, "BidPrice", ticker.BidPrice, "67000.50")
+		assertDecimal(t, "AskPrice", ticker.AskPrice, "67000.51")
+		assertDecimal(t, "OpenPrice", ticker.OpenPrice, "65995.49")
+		assertDecimal(t, "HighPrice", ticker.HighPrice, "67804.51")
+		assertDecimal(t, "LowPrice", ticker.LowPrice, "65526.49")
+		assertDecimal(t, "Volume", ticker.Volume, "9876.54321")
+		if want := time.Date(2025, 5, 1, 12, 0, 0, 123456000, time.UTC).UnixMilli(); ticker.Time != want {
+			t.Errorf("Time = %d, want %d", ticker.Time, want)
+		}
+	}
+	if _, err := p.GetTicker(context.Background(), "DOGEUSD"); !errors.Is(err, ErrUnknownSymbol) {
+		t.Fatalf("GetTicker(DOGEUSD) error = %v, want ErrUnknownSymbol", err)
+	}
+	// Product ids end up in the request path, so anything but BASE-QUOTE is rejected.
+	for _, symbol := range []string{"BTC-USD/../../products", "BTC-USD?level=3", "-USD", "BTC-", "BTC-USD-X"} {
+		if _, err := p.GetTicker(context.Background(), symbol); !errors.Is(err, ErrInvalidSymbol) {
+			t.Errorf("GetTicker(%q) error = %v, want ErrInvalidSymbol", symbol, err)
+		}
+	}
+}
+
+func TestCoinbaseBookAndDepth(t *testing.T) {
+	p := newCoinbaseFixtureProvider(t)
+	book, err := p.GetBook(context.Background(), "BTCUSD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertDecimal(t, "BidPrice", book.BidPrice, "67000.50")
+	assertDecimal(t, "BidQty", book.BidQty, "0.42118")
+	assertDecimal(t, "AskPrice", book.AskPrice, "67000.51")
+	assertDecimal(t, "AskQty", book.AskQty, "0.095")
+
+	depth, err := p.GetDepth(context.Background(), "BTCUSD", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if depth.LastUpdateID != 98765432101 {
+		t.Errorf("LastUpdateID = %d, want the sequence 98765432101", depth.LastUpdateID)
+	}
+	if len(depth.Bids) != 5 || len(depth.Asks) != 5 {
+		t.Fatalf("depth has %d bids and %d asks, want 5 each", len(depth.Bids), len(depth.Asks))
+	}
+	assertDecimal(t, "Bids[0].Price", depth.Bids[0].Price, "67000.50")
+	assertDecimal(t, "Bids[0].Quantity", depth.Bids[0].Quantity, "0.6483417")
+	assertDecimal(t, "Bids[2].Price", depth.Bids[2].Price, "67000.48")
+
+	// A non-positive limit still returns the top level.
+	for _, limit := range []int{0, -1} {
+		depth, err := p.GetDepth(context.Background(), "BTCUSD", limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(depth.Bids) != 1 || len(depth.Asks) != 1 {
+			t.Errorf("GetDepth(limit %d) has %d bids and %d asks, want 1 each", limit, len(depth.Bids), len(depth.Asks))
+		}
+	}
+}
+
+func TestCoinbaseTrades(t *testing.T) {
+	p := newCoinbaseFixtureProvider(t)
+	trades, err := p.GetTrades(context.Background(), "BTCUSD", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trades) != 100 {
+		t.Fatalf("%d trades, want 100", len(trades))
+	}
+	// Coinbase sends trades newest first; they are returned oldest first.
+	first, last := trades[0], trades[len(trades)-1]
+	if first.ID != 734215764 || last.ID != 734215863 {
+		t.Fatalf("trade ids run %d..%d, want 734215764..734215863", first.ID, last.ID)
+	}
+	// The maker of the newest trade sold, so the taker bought.
+	if last.IsBuyerMaker {
+		t.Error("IsBuyerMaker of a sell-side maker = true, want false")
+	}
+	if !first.IsBuyerMaker {
+		t.Error("IsBuyerMaker of a buy-side maker = false, want true")
+	}
+	assertDecimal(t, "Price", last.Price, "67000.50")
+	assertDecimal(t, "Qty", last.Qty, "0.22366912")
+	assertDecimal(t, "QuoteQty", last.QuoteQty, decimal.MustParse("67000.50").Mul(decimal.MustParse("0.22366912")).String())
+	if want := time.Date(2025, 5, 1, 11, 59, 59, 0, time.UTC).UnixMilli(); last.Time != want {
+		t.Errorf("Time = %d, want %d", last.Time, want)
+	}
+}
+
+func TestCoinbaseKlines(t *testing.T) {
+	p := newCoinbaseFixtureProvider(t)
+	tests := []struct {
+		interval  string
+		openTime  int64
+		closeTime int64
+		open      string
+		close     string
+	}{
+		{"1m", 1746100740000, 1746100799999, "67004.31", "67000.5"},
+		{"1h", 1746097200000, 1746100799999, "67272.45", "67000.5"},
+	}
+	for _, tt := range tests {
+		klines, err := p.GetKlines(context.Background(), "BTCUSD", tt.interval, 10)
+		if err != nil {
+			t.Fatalf("GetKlines(%s): %v", tt.interval, err)
+		}
+		if len(klines) != 10 {
+			t.Fatalf("GetKlines(%s) = %d klines, want 10", tt.interval, len(klines))
+		}
+		k := klines[len(klines)-1]
+		if k.OpenTime != tt.openTime || k.CloseTime != tt.closeTime {
+			t.Errorf("%s: newest candle spans %d..%d, want %d..%d", tt.interval, k.OpenTime, k.CloseTime, tt.openTime, tt.closeTime)
+		}
+		assertDecimal(t, tt.interval+" Open", k.Open, tt.open)
+		assertDecimal(t, tt.interval+" Close", k.Close, tt.close)
+		if klines[0].OpenTime >= k.OpenTime {
+			t.Errorf("%s: klines are not oldest first", tt.interval)
+		}
+	}
+	for _, limit := range []int{0, -1} {
+		klines, err := p.GetKlines(context.Background(), "BTCUSD", "1m", limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(klines) != 1 {
+			t.Errorf("GetKlines(limit %d) = %d klines, want 1", limit, len(klines))
+		}
+	}
+	if _, err := p.GetKlines(context.Background(), "BTCUSD", "3m", 10); !errors.Is(err, ErrUnsupportedInterval) {
+		t.Fatalf("GetKlines(3m) error = %v, want ErrUnsupportedInterval", err)
+	}
+}
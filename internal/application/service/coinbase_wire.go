@@ -0,0 +1,257 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ntdat104/go-finance-dataset/internal/application/dto"
+	"github.com/ntdat104/go-finance-dataset/pkg/decimal"
+)
+
+// Wire types mirror the raw Coinbase Exchange REST payloads.
+
+type coinbaseProduct struct {
+	ID              string `json:"id"`
+	BaseCurrency    string `json:"base_currency"`
+	QuoteCurrency   string `json:"quote_currency"`
+	Status          string `json:"status"`
+	TradingDisabled bool   `json:"trading_disabled"`
+}
+
+type coinbaseTicker struct {
+	Ask     string `json:"ask"`
+	Bid     string `json:"bid"`
+	Volume  string `json:"volume"`
+	TradeID int64  `json:"trade_id"`
+	Price   string `json:"price"`
+	Size    string `json:"size"`
+	Time    string `json:"time"`
+}
+
+type coinbaseStats struct {
+	Open   string `json:"open"`
+	High   string `json:"high"`
+	Low    string `json:"low"`
+	Last   string `json:"last"`
+	Volume string `json:"volume"`
+}
+
+// coinbaseBookLevel is [price, size, num_orders].
+type coinbaseBookLevel []json.RawMessage
+
+type coinbaseBook struct {
+	Sequence int64               `json:"sequence"`
+	Bids     []coinbaseBookLevel `json:"bids"`
+	Asks     []coinbaseBookLevel `json:"asks"`
+}
+
+type coinbaseTrade struct {
+	Time    string `json:"time"`
+	TradeID int64  `json:"trade_id"`
+	Price   string `json:"price"`
+	Size    string `json:"size"`
+	Side    string `json:"side"` // side of the maker order
+}
+
+// coinbaseCandle is [time, low, high, open, close, volume] with time in seconds.
+type coinbaseCandle []json.Number
+
+// number parses a JSON number, which unlike the string fields may use exponent notation.
+func (p *decimalParser) number(field string, value json.Number) decimal.Decimal {
+	s := value.String()
+	if strings.ContainsAny(s, "eE") {
+		r, ok := new(big.Rat).SetString(s)
+		if !ok {
+			if p.err == nil {
+				p.err = fmt.Errorf("field %s: invalid number %q", field, s)
+			}
+			return decimal.Decimal{}
+		}
+		return decimal.NewFromRat(r, 12)
+	}
+	return p.required(field, s)
+}
+
+// coinbaseSymbol is the exchange-independent name of a product, e.g. BTCUSD for BTC-USD.
+func coinbaseSymbol(productID string) string {
+	return strings.ReplaceAll(strings.ToUpper(productID), "-", "")
+}
+
+func parseCoinbaseTime(field, value string) (int64, error) {
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return 0, fmt.Errorf("field %s: %w", field, err)
+	}
+	return t.UnixMilli(), nil
+}
+
+func parseCoinbaseProducts(data []byte) ([]dto.MarketSymbol, error) {
+	var w []coinbaseProduct
+	if err := decodeStrict(data, &w); err != nil {
+		return nil, err
+	}
+	out := make([]dto.MarketSymbol, 0, len(w))
+	for i, item := range w {
+		if item.ID == "" || item.BaseCurrency == "" || item.QuoteCurrency == "" {
+			return nil, fmt.Errorf("product[%d]: field id, base_currency or quote_currency missing", i)
+		}
+		out = append(out, dto.MarketSymbol{
+			Symbol:         coinbaseSymbol(item.ID),
+			ExchangeSymbol: item.ID,
+			BaseAsset:      item.BaseCurrency,
+			QuoteAsset:     item.QuoteCurrency,
+			Status:         item.Status,
+			Active:         item.Status == "online" && !item.TradingDisabled,
+		})
+	}
+	return out, nil
+}
+
+func parseCoinbaseTicker(data []byte) (*coinbaseTicker, error) {
+	var w coinbaseTicker
+	if err := decodeStrict(data, &w); err != nil {
+		return nil, err
+	}
+	if w.Price == "" {
+		return nil, errors.New("field price: missing")
+	}
+	return &w, nil
+}
+
+func parseCoinbaseStats(data []byte) (*coinbaseStats, error) {
+	var w coinbaseStats
+	if err := decodeStrict(data, &w); err != nil {
+		return nil, err
+	}
+	if w.Last == "" {
+		return nil, errors.New("field last: missing")
+	}
+	return &w, nil
+}
+
+func (w coinbaseBookLevel) toDTO(p *decimalParser) dto.PriceLevel {
+	if len(w) < 2 {
+		if p.err == nil {
+			p.err = fmt.Errorf("expected at least 2 fields, got %d", len(w))
+		}
+		return dto.PriceLevel{}
+	}
+	var price, size string
+	if err := json.Unmarshal(w[0], &price); err != nil && p.err == nil {
+		p.err = fmt.Errorf("field price: %w", err)
+	}
+	if err := json.Unmarshal(w[1], &size); err != nil && p.err == nil {
+		p.err = fmt.Errorf("field size: %w", err)
+	}
+	return dto.PriceLevel{
+		Price:    p.required("price", price),
+		Quantity: p.required("size", size),
+	}
+}
+
+func parseCoinbaseLevels(side string, levels []coinbaseBookLevel) ([]dto.PriceLevel, error) {
+	out := make([]dto.PriceLevel, 0, len(levels))
+	for i, level := range levels {
+		p := &decimalParser{}
+		pl := level.toDTO(p)
+		if p.err != nil {
+			return nil, fmt.Errorf("%s[%d]: %w", side, i, p.err)
+		}
+		out = append(out, pl)
+	}
+	return out, nil
+}
+
+func parseCoinbaseBook(data []byte) (*dto.DepthSnapshot, error) {
+	var w coinbaseBook
+	if err := decodeStrict(data, &w); err != nil {
+		return nil, err
+	}
+	if w.Sequence <= 0 {
+		return nil, errors.New("field sequence: missing")
+	}
+	bids, err := parseCoinbaseLevels("bids", w.Bids)
+	if err != nil {
+		return nil, err
+	}
+	asks, err := parseCoinbaseLevels("asks", w.Asks)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.DepthSnapshot{LastUpdateID: w.Sequence, Bids: bids, Asks: asks}, nil
+}
+
+// parseCoinbaseTrades returns the trades oldest first; Coinbase sends them newest first.
+func parseCoinbaseTrades(data []byte) ([]dto.Trade, error) {
+	var w []coinbaseTrade
+	if err := decodeStrict(data, &w); err != nil {
+		return nil, err
+	}
+	out := make([]dto.Trade, len(w))
+	for i, item := range w {
+		p := &decimalParser{}
+		t := dto.Trade{
+			ID:    item.TradeID,
+			Price: p.required("price", item.Price),
+			Qty:   p.required("size", item.Size),
+			// A maker on the buy side means the taker sold into the bid.
+			IsBuyerMaker: item.Side == "buy",
+		}
+		if p.err == nil {
+			t.QuoteQty = t.Price.Mul(t.Qty)
+		}
+		ts, err := parseCoinbaseTime("time", item.Time)
+		if err != nil && p.err == nil {
+			p.err = err
+		}
+		t.Time = ts
+		if p.err != nil {
+			return nil, fmt.Errorf("trade[%d]: %w", i, p.err)
+		}
+		out[len(w)-1-i] = t
+	}
+	return out, nil
+}
+
+// parseCoinbaseCandles returns the candles oldest first; Coinbase sends them newest first.
+func parseCoinbaseCandles(granularity time.Duration) func([]byte) ([]dto.Kline, error) {
+	return func(data []byte) ([]dto.Kline, error) {
+		var w []coinbaseCandle
+		if err := decodeStrict(data, &w); err != nil {
+			return nil, err
+		}
+		out := make([]dto.Kline, len(w))
+		for i, item := range w {
+			if len(item) < 6 {
+				return nil, fmt.Errorf("candle[%d]: expected 6 fields, got %d", i, len(item))
+			}
+			openTime, err := item[0].Int64()
+			if err != nil {
+				return nil, fmt.Errorf("candle[%d]: field time: %w", i, err)
+			}
+			p := &decimalParser{}
+			k := dto.Kline{
+				OpenTime:  openTime * 1000,
+				Low:       p.number("low", item[1]),
+				High:      p.number("high", item[2]),
+				Open:      p.number("open", item[3]),
+				Close:     p.number("close", item[4]),
+				Volume:    p.number("volume", item[5]),
+				CloseTime: openTime*1000 + granularity.Milliseconds() - 1,
+				// Coinbase candles carry no quote volume, trade count or taker split.
+				QuoteAssetVolume:         decimal.Zero,
+				TakerBuyBaseAssetVolume:  decimal.Zero,
+				TakerBuyQuoteAssetVolume: decimal.Zero,
+			}
+			if p.err != nil {
+				return nil, fmt.Errorf("candle[%d]: %w", i, p.err)
+			}
+			out[len(w)-1-i] = k
+		}
+		return out, nil
+	}
+}
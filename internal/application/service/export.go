@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ntdat104/go-finance-dataset/internal/application/dto"
+	"github.com/ntdat104/go-finance-dataset/pkg/export"
+)
+
+// Export datasets.
+const (
+	DatasetKlines           = "klines"
+	DatasetAggTrades        = "aggTrades"
+	DatasetHistoricalTrades = "historicalTrades"
+)
+
+// Stable export schemas. Prices and quantities are exported as decimal strings
+// so no precision is lost; columns are only ever appended, never reordered.
+var (
+	klineExportSchema = []export.Column{
+		{Name: "open_time", Type: export.Int64},
+		{Name: "open", Type: export.String},
+		{Name: "high", Type: export.String},
+		{Name: "low", Type: export.String},
+		{Name: "close", Type: export.String},
+		{Name: "volume", Type: export.String},
+		{Name: "close_time", Type: export.Int64},
+		{Name: "quote_asset_volume", Type: export.String},
+		{Name: "number_of_trades", Type: export.Int64},
+		{Name: "taker_buy_base_asset_volume", Type: export.String},
+		{Name: "taker_buy_quote_asset_volume", Type: export.String},
+	}
+	aggTradeExportSchema = []export.Column{
+		{Name: "agg_trade_id", Type: export.Int64},
+		{Name: "price", Type: export.String},
+		{Name: "qty", Type: export.String},
+		{Name: "first_trade_id", Type: export.Int64},
+		{Name: "last_trade_id", Type: export.Int64},
+		{Name: "time", Type: export.Int64},
+		{Name: "is_buyer_maker", Type: export.Bool},
+		{Name: "is_best_match", Type: export.Bool},
+	}
+	tradeExportSchema = []export.Column{
+		{Name: "id", Type: export.Int64},
+		{Name: "price", Type: export.String},
+		{Name: "qty", Type: export.String},
+		{Name: "quote_qty", Type: export.String},
+		{Name: "time", Type: export.Int64},
+		{Name: "is_buyer_maker", Type: export.Bool},
+		{Name: "is_best_match", Type: export.Bool},
+	}
+)
+
+type ExportSvc interface {
+	Schema(dataset string) ([]export.Column, error)
+	Export(ctx context.Context, w export.Writer, dataset, symbol, interval string, startTime, endTime int64) error
+}
+
+type exportSvc struct {
+	binanceSvc BinanceSvc
+}
+
+func NewExportSvc(binanceSvc BinanceSvc) ExportSvc {
+	return &exportSvc{
+		binanceSvc: binanceSvc,
+	}
+}
+
+// Schema returns the column schema of a dataset.
+func (s *exportSvc) Schema(dataset string) ([]export.Column, error) {
+	switch dataset {
+	case DatasetKlines:
+		return klineExportSchema, nil
+	case DatasetAggTrades:
+		return aggTradeExportSchema, nil
+	case DatasetHistoricalTrades:
+		return tradeExportSchema, nil
+	default:
+		return nil, fmt.Errorf("unsupported dataset %q", dataset)
+	}
+}
+
+// Export streams a dataset for the time range into w page by page, flushing
+// after every page so large ranges are never held in memory.
+func (s *exportSvc) Export(ctx context.Context, w export.Writer, dataset, symbol, interval string, startTime, endTime int64) error {
+	var err error
+	switch dataset {
+	case DatasetKlines:
+		err = s.binanceSvc.StreamKlinesRange(ctx, symbol, interval, startTime, endTime, func(page []dto.Kline) error {
+			rows := make([][]any, 0, len(page))
+			for _, k := range page {
+				rows = append(rows, []any{
+					k.OpenTime, k.Open.String(), k.High.String(), k.Low.String(), k.Close.String(), k.Volume.String(),
+					k.CloseTime, k.QuoteAssetVolume.String(), k.NumberOfTrades,
+					k.TakerBuyBaseAssetVolume.String(), k.TakerBuyQuoteAssetVolume.String(),
+				})
+			}
+			return writePage(w, rows)
+		})
+	case DatasetAggTrades:
+		err = s.binanceSvc.StreamAggTradesRange(ctx, symbol, startTime, endTime, func(page []dto.AggTrade) error {
+			rows := make([][]any, 0, len(page))
+			for _, t := range page {
+				rows = append(rows, []any{
+					t.AggTradeID, t.Price.String(), t.Qty.String(), t.FirstTradeID, t.LastTradeID, t.Time,
+					t.IsBuyerMaker, t.IsBestMatch,
+				})
+			}
+			return writePage(w, rows)
+		})
+	case DatasetHistoricalTrades:
+		err = s.binanceSvc.StreamHistoricalTradesRange(ctx, symbol, startTime, endTime, func(page []dto.Trade) error {
+			rows := make([][]any, 0, len(page))
+			for _, t := range page {
+				rows = append(rows, []any{
+					t.ID, t.Price.String(), t.Qty.String(), t.QuoteQty.String(), t.Time, t.IsBuyerMaker, t.IsBestMatch,
+				})
+			}
+			return writePage(w, rows)
+		})
+	default:
+		err = fmt.Errorf("unsupported dataset %q", dataset)
+	}
+	if err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+func writePage(w export.Writer, rows [][]any) error {
+	if err := w.Write(rows); err != nil {
+		return err
+	}
+	return w.Flush()
+}
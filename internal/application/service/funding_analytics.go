@@ -0,0 +1,300 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ntdat104/go-finance-dataset/internal/application/dto"
+	"github.com/ntdat104/go-finance-dataset/pkg/decimal"
+)
+
+// Rankings accepted by GetRanking.
+const (
+	RankByFunding = "funding"
+	RankByBasis   = "basis"
+)
+
+// analyticsRatePrecision is the number of fractional digits of computed rates.
+const analyticsRatePrecision = 8
+
+var (
+	ErrUnsupportedRanking = errors.New("unsupported ranking")
+	ErrInvalidWindow      = errors.New("invalid window")
+)
+
+// defaultFundingWindows are the rolling windows used when none are configured.
+var defaultFundingWindows = []string{"1d", "7d", "30d"}
+
+// FundingAnalyticsSvc derives carry metrics from USD-M perpetual funding and
+// the spot market of the same symbol.
+type FundingAnalyticsSvc interface {
+	GetFunding(ctx context.Context, symbol string) (*dto.FundingSummary, error)
+	GetBasis(ctx context.Context, symbol string) (*dto.Basis, error)
+	GetRollingFunding(ctx context.Context, symbol string, windows []string) (*dto.RollingFunding, error)
+	GetRanking(ctx context.Context, by string, symbols []string, limit int, ascending bool) ([]dto.FundingRank, error)
+}
+
+type fundingAnalyticsSvc struct {
+	binanceSvc BinanceSvc
+	futuresSvc BinanceFuturesSvc
+	windows    []string
+}
+
+// NewFundingAnalyticsSvc creates the analytics over futuresSvc, which should be
+// the USD-M market as its symbols match spot. windows are the default rolling
+// windows such as "1d" or "7d".
+func NewFundingAnalyticsSvc(binanceSvc BinanceSvc, futuresSvc BinanceFuturesSvc, windows []string) (FundingAnalyticsSvc, error) {
+	if len(windows) == 0 {
+		windows = defaultFundingWindows
+	}
+	for _, window := range windows {
+		if _, err := parseWindow(window); err != nil {
+			return nil, err
+		}
+	}
+	return &fundingAnalyticsSvc{
+		binanceSvc: binanceSvc,
+		futuresSvc: futuresSvc,
+		windows:    windows,
+	}, nil
+}
+
+// parseWindow parses "<n><unit>" where unit is s, m, h or d.
+func parseWindow(window string) (time.Duration, error) {
+	d, err := parseLimitInterval(window)
+	if err != nil {
+		return 0, fmt.Errorf("%w %q", ErrInvalidWindow, window)
+	}
+	return d, nil
+}
+
+// GetFunding returns the current funding of a perpetual, annualised.
+func (s *fundingAnalyticsSvc) GetFunding(ctx context.Context, symbol string) (*dto.FundingSummary, error) {
+	mark, err := s.markPrice(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	intervals, err := s.fundingIntervals(ctx)
+	if err != nil {
+		return nil, err
+	}
+	interval := fundingInterval(intervals, mark.Symbol)
+	return &dto.FundingSummary{
+		Symbol:               mark.Symbol,
+		MarkPrice:            mark.MarkPrice,
+		FundingRate:          mark.LastFundingRate,
+		AnnualizedRate:       annualize(mark.LastFundingRate, interval),
+		FundingIntervalHours: int(interval.Hours()),
+		NextFundingTime:      mark.NextFundingTime,
+		Time:                 mark.Time,
+	}, nil
+}
+
+// GetBasis compares the perpetual's mark price with the spot price and its index.
+func (s *fundingAnalyticsSvc) GetBasis(ctx context.Context, symbol string) (*dto.Basis, error) {
+	mark, err := s.markPrice(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	spot, err := s.binanceSvc.GetTickerPrice(ctx, mark.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	out := &dto.Basis{
+		Symbol:     mark.Symbol,
+		SpotPrice:  spot.Price,
+		MarkPrice:  mark.MarkPrice,
+		IndexPrice: mark.IndexPrice,
+		Basis:      mark.MarkPrice.Sub(spot.Price),
+		Premium:    mark.MarkPrice.Sub(mark.IndexPrice),
+		Time:       mark.Time,
+	}
+	if out.BasisRate, err = out.Basis.Div(spot.Price, analyticsRatePrecision); err != nil {
+		return nil, fmt.Errorf("basis rate of %s: %w", mark.Symbol, err)
+	}
+	if out.PremiumRate, err = out.Premium.Div(mark.IndexPrice, analyticsRatePrecision); err != nil {
+		return nil, fmt.Errorf("premium rate of %s: %w", mark.Symbol, err)
+	}
+	return out, nil
+}
+
+// GetRollingFunding averages the fundings settled in each window ending now.
+// Without windows the configured ones are used. History is limited to the
+// latest 1000 fundings, which covers about 41 days of hourly funding.
+func (s *fundingAnalyticsSvc) GetRollingFunding(ctx context.Context, symbol string, windows []string) (*dto.RollingFunding, error) {
+	if len(windows) == 0 {
+		windows = s.windows
+	}
+	lengths := make([]time.Duration, len(windows))
+	for i, window := range windows {
+		d, err := parseWindow(window)
+		if err != nil {
+			return nil, err
+		}
+		lengths[i] = d
+	}
+	symbol = strings.ToUpper(symbol)
+	history, err := s.futuresSvc.GetFundingRateHistory(ctx, symbol, nil, nil, fundingRatePageLimit)
+	if err != nil {
+		return nil, err
+	}
+	intervals, err := s.fundingIntervals(ctx)
+	if err != nil {
+		return nil, err
+	}
+	interval := fundingInterval(intervals, symbol)
+
+	now := time.Now().UnixMilli()
+	out := &dto.RollingFunding{
+		Symbol:               symbol,
+		FundingIntervalHours: int(interval.Hours()),
+		Windows:              make([]dto.FundingWindow, 0, len(windows)),
+	}
+	for i, window := range windows {
+		from := now - lengths[i].Milliseconds()
+		sum := new(big.Rat)
+		count := 0
+		for _, f := range history {
+			if f.FundingTime > from && f.FundingTime <= now {
+				sum.Add(sum, f.FundingRate.Rat())
+				count++
+			}
+		}
+		w := dto.FundingWindow{
+			Window:         window,
+			From:           from,
+			To:             now,
+			Count:          count,
+			AverageRate:    decimal.Zero,
+			AnnualizedRate: decimal.Zero,
+			CumulativeRate: decimal.NewFromRat(sum, analyticsRatePrecision),
+			Complete:       len(history) > 0 && history[0].FundingTime <= from,
+		}
+		if count > 0 {
+			w.AverageRate = decimal.NewFromRat(sum.Quo(sum, big.NewRat(int64(count), 1)), analyticsRatePrecision)
+			w.AnnualizedRate = annualize(w.AverageRate, interval)
+		}
+		out.Windows = append(out.Windows, w)
+	}
+	return out, nil
+}
+
+// GetRanking ranks the perpetuals, or only symbols when given, by annualised
+// funding or by basis rate, highest first unless ascending. Perpetuals without
+// a spot market are left out of the basis ranking.
+func (s *fundingAnalyticsSvc) GetRanking(ctx context.Context, by string, symbols []string, limit int, ascending bool) ([]dto.FundingRank, error) {
+	if by != RankByFunding && by != RankByBasis {
+		return nil, fmt.Errorf("%w %q", ErrUnsupportedRanking, by)
+	}
+	marks, err := s.futuresSvc.GetMarkPrice(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	intervals, err := s.fundingIntervals(ctx)
+	if err != nil {
+		return nil, err
+	}
+	spotPrices, err := s.binanceSvc.GetAllTickerPrices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	spot := make(map[string]decimal.Decimal, len(spotPrices))
+	for _, p := range spotPrices {
+		spot[p.Symbol] = p.Price
+	}
+	wanted := make(map[string]bool, len(symbols))
+	for _, symbol := range symbols {
+		wanted[strings.ToUpper(symbol)] = true
+	}
+
+	ranks := make([]dto.FundingRank, 0, len(marks))
+	for _, mark := range marks {
+		// Delivery contracts carry an expiry suffix and never fund.
+		if strings.Contains(mark.Symbol, "_") || mark.NextFundingTime == 0 {
+			continue
+		}
+		if len(wanted) > 0 && !wanted[mark.Symbol] {
+			continue
+		}
+		interval := fundingInterval(intervals, mark.Symbol)
+		r := dto.FundingRank{
+			Symbol:               mark.Symbol,
+			MarkPrice:            mark.MarkPrice,
+			FundingRate:          mark.LastFundingRate,
+			AnnualizedRate:       annualize(mark.LastFundingRate, interval),
+			FundingIntervalHours: int(interval.Hours()),
+		}
+		if price, ok := spot[mark.Symbol]; ok && price.Sign() != 0 {
+			basisRate, _ := mark.MarkPrice.Sub(price).Div(price, analyticsRatePrecision)
+			r.SpotPrice, r.BasisRate = &price, &basisRate
+		}
+		if by == RankByBasis && r.BasisRate == nil {
+			continue
+		}
+		ranks = append(ranks, r)
+	}
+
+	key := func(r dto.FundingRank) decimal.Decimal {
+		if by == RankByBasis {
+			return *r.BasisRate
+		}
+		return r.AnnualizedRate
+	}
+	sort.Slice(ranks, func(i, j int) bool {
+		if c := key(ranks[i]).Cmp(key(ranks[j])); c != 0 {
+			return (c < 0) == ascending
+		}
+		return ranks[i].Symbol < ranks[j].Symbol
+	})
+	ranks = ranks[:min(limit, len(ranks))]
+	for i := range ranks {
+		ranks[i].Rank = i + 1
+	}
+	return ranks, nil
+}
+
+// markPrice returns the mark price entry of a single perpetual.
+func (s *fundingAnalyticsSvc) markPrice(ctx context.Context, symbol string) (*dto.MarkPrice, error) {
+	symbol = strings.ToUpper(symbol)
+	marks, err := s.futuresSvc.GetMarkPrice(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	for i := range marks {
+		if marks[i].Symbol == symbol {
+			return &marks[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w %q on %s", ErrUnknownSymbol, symbol, s.futuresSvc.Market())
+}
+
+// fundingIntervals returns the funding interval of the perpetuals not settling every 8 hours.
+func (s *fundingAnalyticsSvc) fundingIntervals(ctx context.Context) (map[string]time.Duration, error) {
+	infos, err := s.futuresSvc.GetFundingInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	intervals := make(map[string]time.Duration, len(infos))
+	for _, info := range infos {
+		intervals[info.Symbol] = time.Duration(info.FundingIntervalHours) * time.Hour
+	}
+	return intervals, nil
+}
+
+func fundingInterval(intervals map[string]time.Duration, symbol string) time.Duration {
+	if interval, ok := intervals[symbol]; ok {
+		return interval
+	}
+	return defaultFundingInterval
+}
+
+// annualize scales a per-period funding rate to 365 days of periods.
+func annualize(rate decimal.Decimal, interval time.Duration) decimal.Decimal {
+	periods := big.NewRat(int64(365*24*time.Hour), int64(interval))
+	return decimal.NewFromRat(new(big.Rat).Mul(rate.Rat(), periods), analyticsRatePrecision)
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ntdat104/go-finance-dataset/internal/application/dto"
+	"github.com/ntdat104/go-finance-dataset/pkg/decimal"
+)
+
+// fundingFuturesSvc serves canned funding data; the other methods are not used.
+type fundingFuturesSvc struct {
+	BinanceFuturesSvc
+	history []dto.FundingRate
+	infos   []dto.FundingInfo
+}
+
+func (s *fundingFuturesSvc) GetFundingRateHistory(_ context.Context, symbol string, _, _ *int64, _ int) ([]dto.FundingRate, error) {
+	out := make([]dto.FundingRate, 0, len(s.history))
+	for _, f := range s.history {
+		if f.Symbol == symbol {
+			out = append(out, f)
+		}
+	}
+	return out, nil
+}
+
+func (s *fundingFuturesSvc) GetFundingInfo(context.Context) ([]dto.FundingInfo, error) {
+	return s.infos, nil
+}
+
+func TestAnnualize(t *testing.T) {
+	tests := []struct {
+		rate     string
+		interval time.Duration
+		want     string
+	}{
+		{"0.0001", 8 * time.Hour, "0.1095"},
+		{"0.0001", 4 * time.Hour, "0.219"},
+		{"0.0001", time.Hour, "0.876"},
+		{"-0.00025", 8 * time.Hour, "-0.27375"},
+		{"0", 8 * time.Hour, "0"},
+		// 1251.428571... periods a year, rounded to the rate precision.
+		{"0.0001", 7 * time.Hour, "0.12514286"},
+	}
+	for _, tt := range tests {
+		got := annualize(decimal.MustParse(tt.rate), tt.interval)
+		assertDecimal(t, "annualize("+tt.rate+", "+tt.interval.String()+")", got, tt.want)
+	}
+}
+
+func TestRollingFunding(t *testing.T) {
+	now := time.Now()
+	at := func(symbol string, ago time.Duration, rate string) dto.FundingRate {
+		return dto.FundingRate{Symbol: symbol, FundingTime: now.Add(-ago).UnixMilli(), FundingRate: decimal.MustParse(rate)}
+	}
+	futures := &fundingFuturesSvc{
+		history: []dto.FundingRate{
+			at("BTCUSDT", 9*24*time.Hour, "0.0005"),
+			at("BTCUSDT", 3*24*time.Hour, "0.0004"),
+			at("BTCUSDT", 17*time.Hour, "0.0002"),
+			at("BTCUSDT", 9*time.Hour, "0.0001"),
+			at("BTCUSDT", time.Hour, "0.0003"),
+			at("ETHUSDT", time.Hour, "0.0001"),
+		},
+		infos: []dto.FundingInfo{{Symbol: "ETHUSDT", FundingIntervalHours: 4}},
+	}
+	svc, err := NewFundingAnalyticsSvc(nil, futures, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rolling, err := svc.GetRollingFunding(context.Background(), "btcusdt", []string{"1m", "1d", "7d", "30d"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rolling.Symbol != "BTCUSDT" || rolling.FundingIntervalHours != 8 || len(rolling.Windows) != 4 {
+		t.Fatalf("rolling = %+v", rolling)
+	}
+	tests := []struct {
+		count                           int
+		cumulative, average, annualized string
+		complete                        bool
+	}{
+		{0, "0", "0", "0", true},
+		{3, "0.0006", "0.0002", "0.219", true},
+		{4, "0.001", "0.00025", "0.27375", true},
+		// The history does not reach back 30 days.
+		{5, "0.0015", "0.0003", "0.3285", false},
+	}
+	for i, tt := range tests {
+		w := rolling.Windows[i]
+		if w.Count != tt.count || w.Complete != tt.complete {
+			t.Errorf("%s: count %d complete %v, want %d %v", w.Window, w.Count, w.Complete, tt.count, tt.complete)
+		}
+		assertDecimal(t, w.Window+" cumulative", w.CumulativeRate, tt.cumulative)
+		assertDecimal(t, w.Window+" average", w.AverageRate, tt.average)
+		assertDecimal(t, w.Window+" annualized", w.AnnualizedRate, tt.annualized)
+		if w.To-w.From != mustParseWindow(t, w.Window).Milliseconds() {
+			t.Errorf("%s: window spans %dms", w.Window, w.To-w.From)
+		}
+	}
+
+	// Without windows the configured defaults apply, at the symbol's own funding interval.
+	rolling, err = svc.GetRollingFunding(context.Background(), "ETHUSDT", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rolling.FundingIntervalHours != 4 || len(rolling.Windows) != len(defaultFundingWindows) {
+		t.Fatalf("rolling = %+v", rolling)
+	}
+	assertDecimal(t, "ETHUSDT 1d annualized", rolling.Windows[0].AnnualizedRate, "0.219")
+
+	if _, err := svc.GetRollingFunding(context.Background(), "BTCUSDT", []string{"1w"}); !errors.Is(err, ErrInvalidWindow) {
+		t.Errorf("err = %v, want ErrInvalidWindow", err)
+	}
+	if _, err := NewFundingAnalyticsSvc(nil, futures, []string{"0d"}); !errors.Is(err, ErrInvalidWindow) {
+		t.Errorf("NewFundingAnalyticsSvc err = %v, want ErrInvalidWindow", err)
+	}
+}
+
+func mustParseWindow(t *testing.T, window string) time.Duration {
+	t.Helper()
+	d, err := parseWindow(window)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ntdat104/go-finance-dataset/pkg/httpclient"
+)
+
+// defaultUnhealthyCooldown is how long a failing host is skipped when none is configured.
+const defaultUnhealthyCooldown = 30 * time.Second
+
+// hostPool holds equivalent base URLs of one API, such as api.binance.com and
+// api1-api4.binance.com, and tracks which are failing.
+type hostPool struct {
+	hosts    []string
+	cooldown time.Duration
+
+	lock      sync.Mutex
+	downUntil map[string]time.Time
+}
+
+// newHostPool returns nil for fewer than two hosts, as there is nothing to fail over to.
+func newHostPool(hosts []string, cooldown time.Duration) *hostPool {
+	if len(hosts) < 2 {
+		return nil
+	}
+	if cooldown <= 0 {
+		cooldown = defaultUnhealthyCooldown
+	}
+	return &hostPool{
+		hosts:     hosts,
+		cooldown:  cooldown,
+		downUntil: make(map[string]time.Time),
+	}
+}
+
+// trimBaseURLs drops trailing slashes and empty entries, defaulting to def.
+func trimBaseURLs(baseURLs []string, def string) []string {
+	out := make([]string, 0, len(baseURLs))
+	for _, baseURL := range baseURLs {
+		if baseURL = strings.TrimSuffix(baseURL, "/"); baseURL != "" {
+			out = append(out, baseURL)
+		}
+	}
+	if len(out) == 0 {
+		return []string{def}
+	}
+	return out
+}
+
+// primary is the host services build their URLs with.
+func (p *hostPool) primary() string {
+	return p.hosts[0]
+}
+
+// candidates returns the healthy hosts in configured order, followed by the
+// unhealthy ones soonest to recover first, so a request is still attempted
+// when every host is down.
+func (p *hostPool) candidates() []string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	now := time.Now()
+	healthy := make([]string, 0, len(p.hosts))
+	var down []string
+	for _, host := range p.hosts {
+		if now.Before(p.downUntil[host]) {
+			down = append(down, host)
+			continue
+		}
+		healthy = append(healthy, host)
+	}
+	sort.SliceStable(down, func(i, j int) bool {
+		return p.downUntil[down[i]].Before(p.downUntil[down[j]])
+	})
+	return append(healthy, down...)
+}
+
+func (p *hostPool) markDown(host string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.downUntil[host] = time.Now().Add(p.cooldown)
+}
+
+func (p *hostPool) markUp(host string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	delete(p.downUntil, host)
+}
+
+// split returns the path and query of apiURL when it is built on the primary host.
+func (p *hostPool) split(apiURL string) (string, bool) {
+	if !strings.HasPrefix(apiURL, p.primary()) {
+		return "", false
+	}
+	return strings.TrimPrefix(apiURL, p.primary()), true
+}
+
+// isHostFailure reports whether err says the host is unhealthy, as opposed to
+// the request being refused or the caller giving up. Rate limits apply to every
+// host alike, so they do not fail over; the circuit breaker is kept per host,
+// so an open one does.
+func isHostFailure(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if errors.Is(err, httpclient.ErrCircuitOpen) {
+		return true
+	}
+	var rateLimitErr *RateLimitError
+	if errors.As(err, &rateLimitErr) || errors.Is(err, ErrMissingCredentials) {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 500
+	}
+	return true
+}
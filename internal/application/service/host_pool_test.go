@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ntdat104/go-finance-dataset/pkg/config"
+	"github.com/ntdat104/go-finance-dataset/pkg/httpclient"
+)
+
+func TestTrimBaseURLs(t *testing.T) {
+	tests := []struct {
+		in   []string
+		want []string
+	}{
+		{nil, []string{"https://default"}},
+		{[]string{"", "/"}, []string{"https://default"}},
+		{[]string{"https://a/", "", "https://b"}, []string{"https://a", "https://b"}},
+	}
+	for _, tt := range tests {
+		got := trimBaseURLs(tt.in, "https://default")
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("trimBaseURLs(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestHostPoolCandidates(t *testing.T) {
+	p := newHostPool([]string{"a", "b", "c"}, time.Minute)
+	if got := fmt.Sprint(p.candidates()); got != "[a b c]" {
+		t.Fatalf("candidates = %s, want [a b c]", got)
+	}
+	p.markDown("a")
+	p.markDown("b")
+	if got := fmt.Sprint(p.candidates()); got != "[c a b]" {
+		t.Fatalf("candidates = %s, want [c a b]", got)
+	}
+	p.markUp("a")
+	if got := fmt.Sprint(p.candidates()); got != "[a c b]" {
+		t.Fatalf("candidates = %s, want [a c b]", got)
+	}
+	if newHostPool([]string{"a"}, time.Minute) != nil {
+		t.Fatal("newHostPool with one host is not nil")
+	}
+}
+
+func TestIsHostFailure(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want bool
+	}{
+		{"network", context.Background(), errors.New("connection refused"), true},
+		{"server error", context.Background(), &APIError{StatusCode: 503}, true},
+		{"client error", context.Background(), &APIError{StatusCode: 400, Code: -1121}, false},
+		{"rate limit", context.Background(), &RateLimitError{RetryAfter: time.Second}, false},
+		{"breaker open", context.Background(), fmt.Errorf("fetching: %w", httpclient.ErrCircuitOpen), true},
+		{"missing credentials", context.Background(), ErrMissingCredentials, false},
+		{"caller gone", cancelled, errors.New("context canceled"), false},
+	}
+	for _, tt := range tests {
+		if got := isHostFailure(tt.ctx, tt.err); got != tt.want {
+			t.Errorf("%s: isHostFailure = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFetchDataFailsOver(t *testing.T) {
+	var primaryCalls atomic.Int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		primaryCalls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer primary.Close()
+	secondary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.URL.Path)
+	}))
+	defer secondary.Close()
+
+	// The primary is retried as soon as its cooldown passes, so it is only
+	// skipped once its own circuit breaker opens.
+	httpClient := httpclient.New(httpclient.Options{BreakerThreshold: 1, BreakerCooldown: time.Minute})
+	c := newRestClient("test", nil, httpClient, nil, config.Cache{})
+	c.hosts = newHostPool([]string{primary.URL, secondary.URL}, time.Nanosecond)
+
+	for i := range 3 {
+		body, err := c.fetchData(context.Background(), primary.URL+"/api/v3/ping", nil)
+		if err != nil || string(body) != "/api/v3/ping" {
+			t.Fatalf("call %d: fetchData = %q, %v; want the secondary's response", i, body, err)
+		}
+	}
+	if n := primaryCalls.Load(); n != 1 {
+		t.Fatalf("primary called %d times, want 1 before its breaker opened", n)
+	}
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"fmt"
+	"time"
+)
+
+// klineIntervals maps the Binance kline interval names to their fixed length.
+// Monthly candles ("1M") have no fixed length and are handled separately.
+var klineIntervals = map[string]time.Duration{
+	"1s":  time.Second,
+	"1m":  time.Minute,
+	"3m":  3 * time.Minute,
+	"5m":  5 * time.Minute,
+	"15m": 15 * time.Minute,
+	"30m": 30 * time.Minute,
+	"1h":  time.Hour,
+	"2h":  2 * time.Hour,
+	"4h":  4 * time.Hour,
+	"6h":  6 * time.Hour,
+	"8h":  8 * time.Hour,
+	"12h": 12 * time.Hour,
+	"1d":  24 * time.Hour,
+	"3d":  3 * 24 * time.Hour,
+	"1w":  7 * 24 * time.Hour,
+}
+
+const monthlyInterval = "1M"
+
+// ValidateInterval returns an error if interval is not a supported kline interval.
+func ValidateInterval(interval string) error {
+	if _, ok := klineIntervals[interval]; ok || interval == monthlyInterval {
+		return nil
+	}
+	return fmt.Errorf("unsupported interval %q", interval)
+}
+
+// NextOpenTime returns the open time of the candle following the one opened at openTime.
+func NextOpenTime(interval string, openTime int64) int64 {
+	if interval == monthlyInterval {
+		return time.UnixMilli(openTime).UTC().AddDate(0, 1, 0).UnixMilli()
+	}
+	return openTime + klineIntervals[interval].Milliseconds()
+}
+
+// AlignOpenTime returns the open time of the candle containing t.
+func AlignOpenTime(interval string, t int64) int64 {
+	if interval == monthlyInterval {
+		ts := time.UnixMilli(t).UTC()
+		return time.Date(ts.Year(), ts.Month(), 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+	}
+	if interval == "1w" {
+		// Binance weekly candles open on Monday 00:00 UTC; the Unix epoch was a Thursday.
+		offset := (4 * 24 * time.Hour).Milliseconds()
+		step := klineIntervals[interval].Milliseconds()
+		return (t-offset)/step*step + offset
+	}
+	step := klineIntervals[interval].Milliseconds()
+	return t / step * step
+}
+
+// intervalConfigKey is the key of an interval in config, where keys are lower
+// cased and "1M" (month) would collide with "1m" (minute).
+func intervalConfigKey(interval string) string {
+	if interval == monthlyInterval {
+		return "1mo"
+	}
+	return interval
+}
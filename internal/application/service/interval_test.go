@@ -0,0 +1,67 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func ms(year int, month time.Month, day, hour, minute int) int64 {
+	return time.Date(year, month, day, hour, minute, 0, 0, time.UTC).UnixMilli()
+}
+
+func TestValidateInterval(t *testing.T) {
+	for _, interval := range []string{"1s", "1m", "15m", "4h", "1d", "1w", "1M"} {
+		if err := ValidateInterval(interval); err != nil {
+			t.Errorf("ValidateInterval(%q) = %v", interval, err)
+		}
+	}
+	for _, interval := range []string{"", "2m", "1mo", "1W", "60"} {
+		if err := ValidateInterval(interval); err == nil {
+			t.Errorf("ValidateInterval(%q) = nil, want an error", interval)
+		}
+	}
+}
+
+func TestAlignOpenTime(t *testing.T) {
+	tests := []struct {
+		interval string
+		t        int64
+		want     int64
+	}{
+		{"1m", ms(2025, 5, 1, 12, 0) + 59999, ms(2025, 5, 1, 12, 0)},
+		{"15m", ms(2025, 5, 1, 12, 44), ms(2025, 5, 1, 12, 30)},
+		{"4h", ms(2025, 5, 1, 23, 59), ms(2025, 5, 1, 20, 0)},
+		{"1d", ms(2025, 5, 1, 23, 59), ms(2025, 5, 1, 0, 0)},
+		// Weekly candles open on Monday; 2025-05-01 was a Thursday.
+		{"1w", ms(2025, 5, 1, 12, 0), ms(2025, 4, 28, 0, 0)},
+		{"1w", ms(2025, 4, 28, 0, 0), ms(2025, 4, 28, 0, 0)},
+		{"1w", ms(2025, 4, 27, 23, 59), ms(2025, 4, 21, 0, 0)},
+		{"1M", ms(2025, 2, 28, 23, 59), ms(2025, 2, 1, 0, 0)},
+	}
+	for _, tt := range tests {
+		if got := AlignOpenTime(tt.interval, tt.t); got != tt.want {
+			t.Errorf("AlignOpenTime(%s, %s) = %s, want %s", tt.interval,
+				time.UnixMilli(tt.t).UTC(), time.UnixMilli(got).UTC(), time.UnixMilli(tt.want).UTC())
+		}
+	}
+}
+
+func TestNextOpenTime(t *testing.T) {
+	tests := []struct {
+		interval string
+		t        int64
+		want     int64
+	}{
+		{"1m", ms(2025, 5, 1, 12, 59), ms(2025, 5, 1, 13, 0)},
+		{"1w", ms(2025, 4, 28, 0, 0), ms(2025, 5, 5, 0, 0)},
+		// Months have no fixed length.
+		{"1M", ms(2025, 1, 1, 0, 0), ms(2025, 2, 1, 0, 0)},
+		{"1M", ms(2025, 2, 1, 0, 0), ms(2025, 3, 1, 0, 0)},
+		{"1M", ms(2024, 12, 1, 0, 0), ms(2025, 1, 1, 0, 0)},
+	}
+	for _, tt := range tests {
+		if got := NextOpenTime(tt.interval, tt.t); got != tt.want {
+			t.Errorf("NextOpenTime(%s, %d) = %d, want %d", tt.interval, tt.t, got, tt.want)
+		}
+	}
+}
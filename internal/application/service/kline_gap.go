@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ntdat104/go-finance-dataset/internal/application/dto"
+)
+
+// maxReportedRows caps the open time lists in a gap report.
+const maxReportedRows = 100
+
+// KlineGapSvc scans stored kline series for missing, duplicate and out-of-order
+// candles and re-fetches the missing windows from Binance.
+type KlineGapSvc interface {
+	Scan(symbol, interval string) (*dto.KlineGapReport, error)
+	ScanAll() ([]dto.KlineGapReport, error)
+	Repair(ctx context.Context, symbol, interval string) (*dto.KlineGapReport, error)
+	LastReports() []dto.KlineGapReport
+}
+
+type klineGapSvc struct {
+	klineStoreSvc KlineStoreSvc
+	binanceSvc    BinanceSvc
+	autoRepair    bool
+	lock          sync.RWMutex
+	reports       map[string]dto.KlineGapReport
+}
+
+// NewKlineGapSvc creates the gap service. When scanInterval is positive every
+// stored series is scanned on that schedule, and repaired if autoRepair is set.
+func NewKlineGapSvc(klineStoreSvc KlineStoreSvc, binanceSvc BinanceSvc, scanInterval time.Duration, autoRepair bool) KlineGapSvc {
+	s := &klineGapSvc{
+		klineStoreSvc: klineStoreSvc,
+		binanceSvc:    binanceSvc,
+		autoRepair:    autoRepair,
+		reports:       make(map[string]dto.KlineGapReport),
+	}
+	if scanInterval > 0 {
+		go func() {
+			ticker := time.NewTicker(scanInterval)
+			defer ticker.Stop()
+			for range ticker.C {
+				s.runJob()
+			}
+		}()
+	}
+	return s
+}
+
+func (s *klineGapSvc) runJob() {
+	reports, err := s.ScanAll()
+	if err != nil {
+		log.Printf("Failed to scan kline store: %v", err)
+		return
+	}
+	if !s.autoRepair {
+		return
+	}
+	for _, report := range reports {
+		if report.Healthy() {
+			continue
+		}
+		if _, err := s.Repair(context.Background(), report.Symbol, report.Interval); err != nil {
+			log.Printf("Failed to repair klines for %s %s: %v", report.Symbol, report.Interval, err)
+		}
+	}
+}
+
+// Scan inspects one series and records the report.
+func (s *klineGapSvc) Scan(symbol, interval string) (*dto.KlineGapReport, error) {
+	report, err := s.scan(symbol, interval, maxReportedRows)
+	if err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	s.reports[symbol+":"+interval] = *report
+	s.lock.Unlock()
+	return report, nil
+}
+
+// scan builds a report whose open time lists hold at most maxRows entries; a negative maxRows means no cap.
+func (s *klineGapSvc) scan(symbol, interval string, maxRows int) (*dto.KlineGapReport, error) {
+	report := &dto.KlineGapReport{
+		Symbol:     symbol,
+		Interval:   interval,
+		ScannedAt:  time.Now().UnixMilli(),
+		Missing:    []dto.TimeWindow{},
+		Duplicates: []int64{},
+		OutOfOrder: []int64{},
+		Misaligned: []int64{},
+	}
+
+	seen := make(map[int64]int)
+	maxOpenTime := int64(-1)
+	err := s.klineStoreSvc.Scan(symbol, interval, func(k dto.Kline) error {
+		report.Records++
+		seen[k.OpenTime]++
+		switch {
+		case seen[k.OpenTime] > 1:
+			report.DuplicateCount++
+			if seen[k.OpenTime] == 2 && withinCap(len(report.Duplicates), maxRows) {
+				report.Duplicates = append(report.Duplicates, k.OpenTime)
+			}
+		case k.OpenTime < maxOpenTime:
+			report.OutOfOrderCount++
+			if withinCap(len(report.OutOfOrder), maxRows) {
+				report.OutOfOrder = append(report.OutOfOrder, k.OpenTime)
+			}
+		}
+		if AlignOpenTime(interval, k.OpenTime) != k.OpenTime {
+			report.MisalignedCount++
+			if withinCap(len(report.Misaligned), maxRows) {
+				report.Misaligned = append(report.Misaligned, k.OpenTime)
+			}
+		}
+		maxOpenTime = max(maxOpenTime, k.OpenTime)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(seen) > 0 {
+		times := make([]int64, 0, len(seen))
+		for t := range seen {
+			times = append(times, t)
+		}
+		sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+		report.FirstOpenTime = times[0]
+		report.LastOpenTime = times[len(times)-1]
+
+		// The series is expected to run up to the last closed candle.
+		lastClosed := AlignOpenTime(interval, time.Now().UnixMilli())
+		times = append(times, lastClosed)
+		for i := 0; i+1 < len(times); i++ {
+			if window, ok := missingWindow(interval, times[i], times[i+1]); ok {
+				report.MissingCount += window.Count
+				if withinCap(len(report.Missing), maxRows) {
+					report.Missing = append(report.Missing, window)
+				}
+			}
+		}
+	}
+
+	return report, nil
+}
+
+func withinCap(n, maxRows int) bool {
+	return maxRows < 0 || n < maxRows
+}
+
+// missingWindow returns the open times strictly between two present candles.
+func missingWindow(interval string, prev, next int64) (dto.TimeWindow, bool) {
+	start := NextOpenTime(interval, prev)
+	if start >= next {
+		return dto.TimeWindow{}, false
+	}
+	window := dto.TimeWindow{StartTime: start}
+	if interval == monthlyInterval {
+		for t := start; t < next; t = NextOpenTime(interval, t) {
+			window.EndTime = t
+			window.Count++
+		}
+		return window, true
+	}
+	step := klineIntervals[interval].Milliseconds()
+	window.Count = (next - start + step - 1) / step
+	window.EndTime = start + (window.Count-1)*step
+	return window, true
+}
+
+// ScanAll scans every series held by the kline store.
+func (s *klineGapSvc) ScanAll() ([]dto.KlineGapReport, error) {
+	series, err := s.klineStoreSvc.Series()
+	if err != nil {
+		return nil, err
+	}
+	reports := make([]dto.KlineGapReport, 0, len(series))
+	for _, ks := range series {
+		report, err := s.Scan(ks.Symbol, ks.Interval)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning %s %s: %w", ks.Symbol, ks.Interval, err)
+		}
+		reports = append(reports, *report)
+	}
+	return reports, nil
+}
+
+// Repair re-fetches the missing windows of a series, compacts away duplicates
+// and out-of-order rows, and returns the report of a fresh scan. Windows the
+// exchange has no data for (e.g. maintenance) remain in the report.
+func (s *klineGapSvc) Repair(ctx context.Context, symbol, interval string) (*dto.KlineGapReport, error) {
+	report, err := s.scan(symbol, interval, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, window := range report.Missing {
+		err := s.binanceSvc.StreamKlinesRange(ctx, symbol, interval, window.StartTime, window.EndTime, func([]dto.Kline) error {
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error fetching %s %s window %d-%d: %w", symbol, interval, window.StartTime, window.EndTime, err)
+		}
+	}
+
+	if err := s.klineStoreSvc.Compact(symbol, interval); err != nil {
+		return nil, err
+	}
+	return s.Scan(symbol, interval)
+}
+
+// LastReports returns the most recent report of every scanned series.
+func (s *klineGapSvc) LastReports() []dto.KlineGapReport {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	reports := make([]dto.KlineGapReport, 0, len(s.reports))
+	for _, report := range s.reports {
+		reports = append(reports, report)
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		if reports[i].Symbol != reports[j].Symbol {
+			return reports[i].Symbol < reports[j].Symbol
+		}
+		return reports[i].Interval < reports[j].Interval
+	})
+	return reports
+}
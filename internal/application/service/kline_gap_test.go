@@ -0,0 +1,80 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ntdat104/go-finance-dataset/internal/application/dto"
+)
+
+func TestMissingWindow(t *testing.T) {
+	minute := int64(60000)
+	tests := []struct {
+		name       string
+		interval   string
+		prev, next int64
+		want       dto.TimeWindow
+		ok         bool
+	}{
+		{"adjacent", "1m", 0, minute, dto.TimeWindow{}, false},
+		{"one missing", "1m", 0, 2 * minute, dto.TimeWindow{StartTime: minute, EndTime: minute, Count: 1}, true},
+		{"several missing", "1m", 0, 5 * minute, dto.TimeWindow{StartTime: minute, EndTime: 4 * minute, Count: 4}, true},
+		// A misaligned next candle still leaves the aligned ones before it missing.
+		{"misaligned next", "1m", 0, 3*minute + 1, dto.TimeWindow{StartTime: minute, EndTime: 3 * minute, Count: 3}, true},
+		{"months", "1M", ms(2025, 1, 1, 0, 0), ms(2025, 4, 1, 0, 0),
+			dto.TimeWindow{StartTime: ms(2025, 2, 1, 0, 0), EndTime: ms(2025, 3, 1, 0, 0), Count: 2}, true},
+	}
+	for _, tt := range tests {
+		got, ok := missingWindow(tt.interval, tt.prev, tt.next)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("%s: missingWindow = %+v, %v; want %+v, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestKlineGapScan(t *testing.T) {
+	store, err := NewKlineStoreSvc(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := (24 * time.Hour).Milliseconds()
+	today := AlignOpenTime("1d", time.Now().UnixMilli())
+	at := func(daysAgo int64) dto.Kline {
+		return dto.Kline{OpenTime: today - daysAgo*day, CloseTime: today - (daysAgo-1)*day - 1}
+	}
+	// Days 10 to 1 ago with 7 and 6 missing, 4 duplicated, 8 written after 3,
+	// one misaligned row, and nothing after yesterday missing.
+	klines := []dto.Kline{at(10), at(9), at(5), at(4), at(4), at(3), at(8), at(2), at(1)}
+	klines = append(klines, dto.Kline{OpenTime: today - 3*day + 1})
+	if err := store.Append("BTCUSDT", "1d", klines); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := NewKlineGapSvc(store, nil, 0, false)
+	report, err := svc.Scan("BTCUSDT", "1d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Records != len(klines) || report.FirstOpenTime != today-10*day || report.LastOpenTime != today-day {
+		t.Fatalf("report covers %d records %d..%d", report.Records, report.FirstOpenTime, report.LastOpenTime)
+	}
+	wantMissing := dto.TimeWindow{StartTime: today - 7*day, EndTime: today - 6*day, Count: 2}
+	if report.MissingCount != 2 || len(report.Missing) != 1 || report.Missing[0] != wantMissing {
+		t.Errorf("missing = %d %+v, want %+v", report.MissingCount, report.Missing, wantMissing)
+	}
+	if report.DuplicateCount != 1 || report.Duplicates[0] != today-4*day {
+		t.Errorf("duplicates = %d %v", report.DuplicateCount, report.Duplicates)
+	}
+	if report.OutOfOrderCount != 2 || report.OutOfOrder[0] != today-8*day {
+		t.Errorf("out of order = %d %v, want day 8 and the misaligned row", report.OutOfOrderCount, report.OutOfOrder)
+	}
+	if report.MisalignedCount != 1 || report.Misaligned[0] != today-3*day+1 {
+		t.Errorf("misaligned = %d %v", report.MisalignedCount, report.Misaligned)
+	}
+	if report.Healthy() {
+		t.Error("Healthy = true")
+	}
+	if reports := svc.LastReports(); len(reports) != 1 || reports[0].Symbol != "BTCUSDT" {
+		t.Errorf("LastReports = %+v", reports)
+	}
+}
@@ -0,0 +1,474 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ntdat104/go-finance-dataset/internal/application/dto"
+	"github.com/ntdat104/go-finance-dataset/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// KlineStoreSvc is an embedded append-only time-series store for candles,
+// keyed by symbol and interval. Each series lives in its own file of
+// newline-delimited JSON records; a later record for the same open time
+// supersedes earlier ones until the series is compacted.
+type KlineStoreSvc interface {
+	Append(symbol, interval string, klines []dto.Kline) error
+	Range(symbol, interval string, startTime, endTime int64) ([]dto.Kline, error)
+	Last(symbol, interval string, n int) ([]dto.Kline, error)
+	Scan(symbol, interval string, fn func(dto.Kline) error) error
+	Compact(symbol, interval string) error
+	Series() ([]dto.KlineSeries, error)
+}
+
+const (
+	klineFileExt = ".ndjson"
+	// compactMinRecords and compactGarbageRatio decide when Append compacts a series on its own.
+	compactMinRecords   = 10000
+	compactGarbageRatio = 0.5
+	// maxOpenKlineSeries caps the series files kept open; the least recently
+	// used idle one is closed when it is exceeded.
+	maxOpenKlineSeries = 128
+)
+
+// ErrInvalidSymbol is returned for symbols that are not made of letters and digits.
+var ErrInvalidSymbol = errors.New("invalid symbol")
+
+type klineIndexEntry struct {
+	offset int64
+	length int64
+}
+
+// klineSeries is the open file and in-memory index of one symbol/interval series.
+type klineSeries struct {
+	mu       sync.RWMutex
+	path     string
+	file     *os.File
+	size     int64
+	records  int
+	times    []int64
+	index    map[int64]klineIndexEntry
+	symbol   string
+	interval string
+	// closed is set once the series is evicted; holders must look it up again.
+	closed   bool
+	lastUsed uint64
+}
+
+type klineStoreSvc struct {
+	dir    string
+	mu     sync.Mutex
+	series map[string]*klineSeries
+	clock  uint64
+}
+
+func NewKlineStoreSvc(dir string) (KlineStoreSvc, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating kline store directory %s: %w", dir, err)
+	}
+	return &klineStoreSvc{
+		dir:    dir,
+		series: make(map[string]*klineSeries),
+	}, nil
+}
+
+// intervalFileName maps an interval to a file name. "1M" (month) and "1m"
+// (minute) would collide on case-insensitive file systems.
+func intervalFileName(interval string) string {
+	if interval == monthlyInterval {
+		return "1mo"
+	}
+	return interval
+}
+
+func intervalFromFileName(name string) string {
+	if name == "1mo" {
+		return monthlyInterval
+	}
+	return name
+}
+
+// normalizeSymbol upper-cases symbol and rejects anything but letters
+// and digits, so a symbol is always safe to use as a directory name.
+func normalizeSymbol(symbol string) (string, error) {
+	symbol = strings.ToUpper(symbol)
+	if symbol == "" {
+		return "", fmt.Errorf("%w %q", ErrInvalidSymbol, symbol)
+	}
+	for _, r := range symbol {
+		if (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return "", fmt.Errorf("%w %q", ErrInvalidSymbol, symbol)
+		}
+	}
+	return symbol, nil
+}
+
+// getSeries returns the open series, opening its file if needed. Only create
+// makes a missing series; otherwise a missing one is reported as nil.
+func (s *klineStoreSvc) getSeries(symbol, interval string, create bool) (*klineSeries, error) {
+	symbol, err := normalizeSymbol(symbol)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateInterval(interval); err != nil {
+		return nil, err
+	}
+	key := symbol + ":" + interval
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clock++
+	if ks, ok := s.series[key]; ok {
+		ks.lastUsed = s.clock
+		return ks, nil
+	}
+
+	dir := filepath.Join(s.dir, symbol)
+	path := filepath.Join(dir, intervalFileName(interval)+klineFileExt)
+	if !create {
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+	} else if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating series directory %s: %w", dir, err)
+	}
+	ks := &klineSeries{
+		path:     path,
+		symbol:   symbol,
+		interval: interval,
+		lastUsed: s.clock,
+	}
+	if err := ks.open(); err != nil {
+		if ks.file != nil {
+			ks.file.Close()
+		}
+		return nil, err
+	}
+	s.series[key] = ks
+	s.evictIdleLocked()
+	return ks, nil
+}
+
+// evictIdleLocked closes least recently used series until at most
+// maxOpenKlineSeries stay open. Series currently in use are skipped.
+// Nobody holding a series lock takes s.mu, so locking one here cannot deadlock.
+func (s *klineStoreSvc) evictIdleLocked() {
+	for len(s.series) > maxOpenKlineSeries {
+		var victimKey string
+		var victim *klineSeries
+		for key, ks := range s.series {
+			if ks.lastUsed == s.clock {
+				continue
+			}
+			if victim == nil || ks.lastUsed < victim.lastUsed {
+				victimKey, victim = key, ks
+			}
+		}
+		if victim == nil || !victim.mu.TryLock() {
+			return
+		}
+		victim.closed = true
+		if err := victim.file.Close(); err != nil {
+			logger.Warn("Error closing kline series", zap.String("path", victim.path), zap.Error(err))
+		}
+		victim.mu.Unlock()
+		delete(s.series, victimKey)
+	}
+}
+
+// acquire returns the series locked for writing or reading, looking it up
+// again if it was evicted in between. A missing series is nil unless create.
+func (s *klineStoreSvc) acquire(symbol, interval string, create, write bool) (*klineSeries, error) {
+	for {
+		ks, err := s.getSeries(symbol, interval, create)
+		if err != nil || ks == nil {
+			return nil, err
+		}
+		if write {
+			ks.mu.Lock()
+		} else {
+			ks.mu.RLock()
+		}
+		if !ks.closed {
+			return ks, nil
+		}
+		if write {
+			ks.mu.Unlock()
+		} else {
+			ks.mu.RUnlock()
+		}
+	}
+}
+
+// open opens the series file and rebuilds the index. A partially written
+// trailing record, e.g. from a crash mid-append, is truncated away. Any other
+// undecodable record fails the open and leaves the file untouched, so the
+// records after it are never lost.
+func (ks *klineSeries) open() error {
+	file, err := os.OpenFile(ks.path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("error opening kline series %s: %w", ks.path, err)
+	}
+	ks.file = file
+	ks.index = make(map[int64]klineIndexEntry)
+	ks.times = ks.times[:0]
+	ks.records = 0
+
+	var offset int64
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			if len(line) > 0 {
+				logger.Warn("Truncating incomplete kline record", zap.String("path", ks.path), zap.Int64("offset", offset))
+				if err := file.Truncate(offset); err != nil {
+					return fmt.Errorf("error truncating kline series %s: %w", ks.path, err)
+				}
+			}
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("error reading kline series %s: %w", ks.path, err)
+		}
+		var k dto.Kline
+		if err := json.Unmarshal(line, &k); err != nil {
+			return fmt.Errorf("corrupt kline record at offset %d of %s: %w", offset, ks.path, err)
+		}
+		ks.put(k.OpenTime, klineIndexEntry{offset: offset, length: int64(len(line))})
+		offset += int64(len(line))
+	}
+	ks.size = offset
+	return nil
+}
+
+// put records the location of the newest record for openTime.
+func (ks *klineSeries) put(openTime int64, entry klineIndexEntry) {
+	ks.records++
+	if _, exists := ks.index[openTime]; !exists {
+		i := sort.Search(len(ks.times), func(i int) bool { return ks.times[i] >= openTime })
+		ks.times = append(ks.times, 0)
+		copy(ks.times[i+1:], ks.times[i:])
+		ks.times[i] = openTime
+	}
+	ks.index[openTime] = entry
+}
+
+func (ks *klineSeries) read(openTimes []int64) ([]dto.Kline, error) {
+	out := make([]dto.Kline, 0, len(openTimes))
+	for _, t := range openTimes {
+		entry := ks.index[t]
+		buf := make([]byte, entry.length)
+		if _, err := ks.file.ReadAt(buf, entry.offset); err != nil {
+			return nil, fmt.Errorf("error reading kline series %s: %w", ks.path, err)
+		}
+		var k dto.Kline
+		if err := json.Unmarshal(buf, &k); err != nil {
+			return nil, fmt.Errorf("error decoding kline at offset %d of %s: %w", entry.offset, ks.path, err)
+		}
+		out = append(out, k)
+	}
+	return out, nil
+}
+
+func (ks *klineSeries) garbageRatio() float64 {
+	if ks.records == 0 {
+		return 0
+	}
+	return float64(ks.records-len(ks.times)) / float64(ks.records)
+}
+
+// Append writes klines to the end of the series.
+func (s *klineStoreSvc) Append(symbol, interval string, klines []dto.Kline) error {
+	if len(klines) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	lengths := make([]int64, 0, len(klines))
+	for _, k := range klines {
+		before := buf.Len()
+		line, err := json.Marshal(k)
+		if err != nil {
+			return fmt.Errorf("error encoding kline %d: %w", k.OpenTime, err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+		lengths = append(lengths, int64(buf.Len()-before))
+	}
+
+	ks, err := s.acquire(symbol, interval, true, true)
+	if err != nil {
+		return err
+	}
+	if _, err := ks.file.WriteAt(buf.Bytes(), ks.size); err != nil {
+		ks.mu.Unlock()
+		return fmt.Errorf("error appending to kline series %s: %w", ks.path, err)
+	}
+	offset := ks.size
+	for i, k := range klines {
+		ks.put(k.OpenTime, klineIndexEntry{offset: offset, length: lengths[i]})
+		offset += lengths[i]
+	}
+	ks.size = offset
+	needsCompaction := ks.records >= compactMinRecords && ks.garbageRatio() >= compactGarbageRatio
+	ks.mu.Unlock()
+
+	if needsCompaction {
+		return s.Compact(symbol, interval)
+	}
+	return nil
+}
+
+// Range returns the stored klines with an open time between startTime and endTime inclusive.
+func (s *klineStoreSvc) Range(symbol, interval string, startTime, endTime int64) ([]dto.Kline, error) {
+	ks, err := s.acquire(symbol, interval, false, false)
+	if err != nil || ks == nil {
+		return nil, err
+	}
+	defer ks.mu.RUnlock()
+
+	from := sort.Search(len(ks.times), func(i int) bool { return ks.times[i] >= startTime })
+	to := sort.Search(len(ks.times), func(i int) bool { return ks.times[i] > endTime })
+	return ks.read(ks.times[from:to])
+}
+
+// Last returns up to n of the most recent stored klines.
+func (s *klineStoreSvc) Last(symbol, interval string, n int) ([]dto.Kline, error) {
+	ks, err := s.acquire(symbol, interval, false, false)
+	if err != nil || ks == nil {
+		return nil, err
+	}
+	defer ks.mu.RUnlock()
+
+	from := max(len(ks.times)-n, 0)
+	return ks.read(ks.times[from:])
+}
+
+// Scan calls fn for every record in the series in physical (write) order,
+// including superseded duplicates, which is what gap detection needs to see.
+func (s *klineStoreSvc) Scan(symbol, interval string, fn func(dto.Kline) error) error {
+	ks, err := s.acquire(symbol, interval, false, false)
+	if err != nil || ks == nil {
+		return err
+	}
+	defer ks.mu.RUnlock()
+
+	reader := bufio.NewReader(io.NewSectionReader(ks.file, 0, ks.size))
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error reading kline series %s: %w", ks.path, err)
+		}
+		var k dto.Kline
+		if err := json.Unmarshal(line, &k); err != nil {
+			return fmt.Errorf("error decoding kline series %s: %w", ks.path, err)
+		}
+		if err := fn(k); err != nil {
+			return err
+		}
+	}
+}
+
+// Compact rewrites the series keeping only the newest record per open time, in order.
+func (s *klineStoreSvc) Compact(symbol, interval string) error {
+	ks, err := s.acquire(symbol, interval, false, true)
+	if err != nil || ks == nil {
+		return err
+	}
+	defer ks.mu.Unlock()
+
+	tmpPath := ks.path + ".compact"
+	tmp, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("error creating %s: %w", tmpPath, err)
+	}
+	defer os.Remove(tmpPath)
+
+	writer := bufio.NewWriter(tmp)
+	for _, t := range ks.times {
+		entry := ks.index[t]
+		buf := make([]byte, entry.length)
+		if _, err := ks.file.ReadAt(buf, entry.offset); err != nil {
+			tmp.Close()
+			return fmt.Errorf("error reading kline series %s: %w", ks.path, err)
+		}
+		if _, err := writer.Write(buf); err != nil {
+			tmp.Close()
+			return fmt.Errorf("error writing %s: %w", tmpPath, err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing %s: %w", tmpPath, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error syncing %s: %w", tmpPath, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error closing %s: %w", tmpPath, err)
+	}
+
+	if err := ks.file.Close(); err != nil {
+		return fmt.Errorf("error closing kline series %s: %w", ks.path, err)
+	}
+	if err := os.Rename(tmpPath, ks.path); err != nil {
+		return errors.Join(fmt.Errorf("error replacing kline series %s: %w", ks.path, err), ks.open())
+	}
+	return ks.open()
+}
+
+// Series lists every symbol/interval series present on disk.
+func (s *klineStoreSvc) Series() ([]dto.KlineSeries, error) {
+	symbols, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("error listing kline store %s: %w", s.dir, err)
+	}
+	out := []dto.KlineSeries{}
+	for _, symbolDir := range symbols {
+		if !symbolDir.IsDir() {
+			continue
+		}
+		files, err := os.ReadDir(filepath.Join(s.dir, symbolDir.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("error listing kline store %s: %w", symbolDir.Name(), err)
+		}
+		for _, f := range files {
+			if f.IsDir() || !strings.HasSuffix(f.Name(), klineFileExt) {
+				continue
+			}
+			interval := intervalFromFileName(strings.TrimSuffix(f.Name(), klineFileExt))
+			ks, err := s.acquire(symbolDir.Name(), interval, false, false)
+			if err != nil || ks == nil {
+				continue
+			}
+			series := dto.KlineSeries{
+				Symbol:   ks.symbol,
+				Interval: ks.interval,
+				Count:    len(ks.times),
+				Records:  ks.records,
+				Bytes:    ks.size,
+			}
+			if len(ks.times) > 0 {
+				series.FirstOpenTime = ks.times[0]
+				series.LastOpenTime = ks.times[len(ks.times)-1]
+			}
+			ks.mu.RUnlock()
+			out = append(out, series)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ntdat104/go-finance-dataset/internal/application/dto"
+)
+
+func minuteKlines(from int64, n int) []dto.Kline {
+	out := make([]dto.Kline, n)
+	for i := range out {
+		openTime := from + int64(i)*60000
+		out[i] = dto.Kline{OpenTime: openTime, CloseTime: openTime + 59999}
+	}
+	return out
+}
+
+func TestKlineStoreSymbolValidation(t *testing.T) {
+	store, err := NewKlineStoreSvc(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, symbol := range []string{"", "../etc", "BTC/USDT", "BTC.USDT", "BTC-USDT", "btc usdt"} {
+		if err := store.Append(symbol, "1m", minuteKlines(0, 1)); !errors.Is(err, ErrInvalidSymbol) {
+			t.Errorf("Append(%q) error = %v, want ErrInvalidSymbol", symbol, err)
+		}
+		if _, err := store.Last(symbol, "1m", 1); !errors.Is(err, ErrInvalidSymbol) {
+			t.Errorf("Last(%q) error = %v, want ErrInvalidSymbol", symbol, err)
+		}
+	}
+}
+
+func TestKlineStoreReadsDoNotCreateSeries(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewKlineStoreSvc(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	klines, err := store.Last("NOSUCHSYMBOL", "1m", 10)
+	if err != nil || len(klines) != 0 {
+		t.Fatalf("Last = %v, %v; want empty", klines, err)
+	}
+	klines, err = store.Range("NOSUCHSYMBOL", "1m", 0, 1<<40)
+	if err != nil || len(klines) != 0 {
+		t.Fatalf("Range = %v, %v; want empty", klines, err)
+	}
+	if err := store.Scan("NOSUCHSYMBOL", "1m", func(dto.Kline) error { return nil }); err != nil {
+		t.Fatalf("Scan error = %v", err)
+	}
+	if err := store.Compact("NOSUCHSYMBOL", "1m"); err != nil {
+		t.Fatalf("Compact error = %v", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("store dir has %d entries after reads, want 0", len(entries))
+	}
+}
+
+func TestKlineStoreNormalizesSymbolCase(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewKlineStoreSvc(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Append("btcusdt", "1m", minuteKlines(0, 3)); err != nil {
+		t.Fatal(err)
+	}
+	klines, err := store.Last("BTCUSDT", "1m", 10)
+	if err != nil || len(klines) != 3 {
+		t.Fatalf("Last = %d klines, %v; want 3", len(klines), err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "BTCUSDT", "1m"+klineFileExt)); err != nil {
+		t.Fatalf("series file: %v", err)
+	}
+}
+
+func TestKlineStoreEvictsIdleSeries(t *testing.T) {
+	store, err := NewKlineStoreSvc(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := maxOpenKlineSeries + 10
+	for i := range n {
+		if err := store.Append(fmt.Sprintf("SYM%d", i), "1m", minuteKlines(0, 2)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if open := len(store.(*klineStoreSvc).series); open > maxOpenKlineSeries {
+		t.Fatalf("%d series open, want at most %d", open, maxOpenKlineSeries)
+	}
+	// Evicted series are reopened from disk on demand.
+	klines, err := store.Last("SYM0", "1m", 10)
+	if err != nil || len(klines) != 2 {
+		t.Fatalf("Last(SYM0) = %d klines, %v; want 2", len(klines), err)
+	}
+	series, err := store.Series()
+	if err != nil || len(series) != n {
+		t.Fatalf("Series = %d, %v; want %d", len(series), err, n)
+	}
+}
+
+func TestKlineStoreSupersedesAndCompacts(t *testing.T) {
+	store, err := NewKlineStoreSvc(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	klines := minuteKlines(0, 3)
+	if err := store.Append("ETHUSDT", "1m", klines); err != nil {
+		t.Fatal(err)
+	}
+	updated := klines[1]
+	updated.NumberOfTrades = 42
+	if err := store.Append("ETHUSDT", "1m", []dto.Kline{updated}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Compact("ETHUSDT", "1m"); err != nil {
+		t.Fatal(err)
+	}
+	got, err := store.Range("ETHUSDT", "1m", 60000, 60000)
+	if err != nil || len(got) != 1 || got[0].NumberOfTrades != 42 {
+		t.Fatalf("Range = %+v, %v; want the superseding record", got, err)
+	}
+	series, err := store.Series()
+	if err != nil || len(series) != 1 || series[0].Records != 3 {
+		t.Fatalf("Series = %+v, %v; want 3 records after compaction", series, err)
+	}
+}
+
+func TestKlineStoreRecovery(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "BTCUSDT", "1m"+klineFileExt)
+	store, err := NewKlineStoreSvc(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Append("BTCUSDT", "1m", minuteKlines(0, 3)); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		content []byte
+		want    int // klines served, or -1 when opening must fail
+		size    int
+	}{
+		{"partial final record", append(append([]byte{}, data...), `{"open_time":180000,"op`...), 3, len(data)},
+		{"corrupt middle record", append([]byte("{garbage}\n"), data...), -1, len(data) + 10},
+		{"corrupt final record", append(append([]byte{}, data...), "{garbage}\n"...), -1, len(data) + 10},
+	}
+	for _, tt := range tests {
+		if err := os.WriteFile(path, tt.content, 0644); err != nil {
+			t.Fatal(err)
+		}
+		// A fresh store reopens the series from disk.
+		store, err := NewKlineStoreSvc(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		klines, err := store.Last("BTCUSDT", "1m", 10)
+		if tt.want < 0 {
+			if err == nil {
+				t.Errorf("%s: Last = %d klines, want an error", tt.name, len(klines))
+			}
+		} else if err != nil || len(klines) != tt.want {
+			t.Errorf("%s: Last = %d klines, %v; want %d", tt.name, len(klines), err, tt.want)
+		}
+		if info, err := os.Stat(path); err != nil || info.Size() != int64(tt.size) {
+			t.Errorf("%s: file is %d bytes, want %d", tt.name, info.Size(), tt.size)
+		}
+	}
+}
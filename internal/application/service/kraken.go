@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ntdat104/go-finance-dataset/internal/application/dto"
+	"github.com/ntdat104/go-finance-dataset/pkg/config"
+	"github.com/ntdat104/go-finance-dataset/pkg/datasource"
+	"github.com/ntdat104/go-finance-dataset/pkg/httpclient"
+)
+
+const (
+	ExchangeKraken = "kraken"
+
+	krakenBaseURL = "https://api.kraken.com"
+	// krakenMaxDepth, krakenMaxTrades and krakenMaxCandles are the most rows Kraken returns per request.
+	krakenMaxDepth   = 500
+	krakenMaxTrades  = 1000
+	krakenMaxCandles = 720
+)
+
+// krakenIntervals maps the kline intervals Kraken supports to its OHLC interval.
+var krakenIntervals = map[string]time.Duration{
+	"1m":  time.Minute,
+	"5m":  5 * time.Minute,
+	"15m": 15 * time.Minute,
+	"30m": 30 * time.Minute,
+	"1h":  time.Hour,
+	"4h":  4 * time.Hour,
+	"1d":  24 * time.Hour,
+	"1w":  7 * 24 * time.Hour,
+}
+
+// krakenSvc serves market data from the Kraken public REST API. Pairs are
+// addressed by the exchange-independent symbol (BTCEUR) or by their Kraken
+// name (XXBTZEUR) or altname (XBTEUR).
+type krakenSvc struct {
+	*restClient
+	baseURL string
+}
+
+// NewKrakenProvider creates the Kraken provider. An empty baseURL uses the production API.
+func NewKrakenProvider(baseURL string, httpClient httpclient.Client, localCacheSvc LocalCacheSvc, cacheCfg config.Cache) MarketDataProvider {
+	if baseURL == "" {
+		baseURL = krakenBaseURL
+	}
+	return &krakenSvc{
+		restClient: newRestClient(ExchangeKraken, nil, httpClient, localCacheSvc, cacheCfg),
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+func (s *krakenSvc) Exchange() string {
+	return ExchangeKraken
+}
+
+// GetSymbols lists the Kraken asset pairs with their asset codes mapped to the common ones.
+func (s *krakenSvc) GetSymbols(ctx context.Context) ([]dto.MarketSymbol, error) {
+	return getWithCache(ctx, s.restClient, "products", "global", s.baseURL+"/0/public/AssetPairs", nil, parseKrakenAssetPairs)
+}
+
+// pair maps symbol to a Kraken pair name.
+func (s *krakenSvc) pair(ctx context.Context, symbol string) (dto.MarketSymbol, error) {
+	pairs, err := s.GetSymbols(datasource.Detach(ctx))
+	if err != nil {
+		return dto.MarketSymbol{}, err
+	}
+	symbol = strings.ToUpper(symbol)
+	for _, p := range pairs {
+		if p.Symbol == symbol || p.ExchangeSymbol == symbol {
+			return p, nil
+		}
+	}
+	// Altnames use the short codes, e.g. XBTEUR for XXBTZEUR.
+	for _, p := range pairs {
+		if strings.ReplaceAll(symbol, "XBT", "BTC") == p.Symbol {
+			return p, nil
+		}
+	}
+	return dto.MarketSymbol{}, fmt.Errorf("%w %q on %s", ErrUnknownSymbol, symbol, ExchangeKraken)
+}
+
+func (s *krakenSvc) getTicker(ctx context.Context, pair string) (*krakenTicker, error) {
+	params := map[string]string{"pair": pair}
+	return getWithCache(ctx, s.restClient, "tickerprice", pair, s.baseURL+"/0/public/Ticker", params, parseKrakenTicker)
+}
+
+// GetTicker returns the rolling 24h ticker. Kraken's open is today's (UTC) open.
+func (s *krakenSvc) GetTicker(ctx context.Context, symbol string) (*dto.MarketTicker, error) {
+	pair, err := s.pair(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	t, err := s.getTicker(ctx, pair.ExchangeSymbol)
+	if err != nil {
+		return nil, err
+	}
+	p := &decimalParser{}
+	out := &dto.MarketTicker{
+		Symbol:    pair.Symbol,
+		LastPrice: p.required("c", krakenIndex(t.C, 0)),
+		BidPrice:  p.required("b", krakenIndex(t.B, 0)),
+		AskPrice:  p.required("a", krakenIndex(t.A, 0)),
+		OpenPrice: p.required("o", t.O),
+		HighPrice: p.required("h", krakenIndex(t.H, 1)),
+		LowPrice:  p.required("l", krakenIndex(t.L, 1)),
+		Volume:    p.required("v", krakenIndex(t.V, 1)),
+		Time:      t.FetchedAt,
+	}
+	vwap := p.required("p", krakenIndex(t.P, 1))
+	if p.err != nil {
+		return nil, fmt.Errorf("error decoding ticker of %s: %w", pair.ExchangeSymbol, p.err)
+	}
+	out.QuoteVolume = vwap.Mul(out.Volume)
+	return out, nil
+}
+
+// GetBook returns the best bid and ask from the ticker, which Kraken keeps with lot volumes.
+func (s *krakenSvc) GetBook(ctx context.Context, symbol string) (*dto.BookTicker, error) {
+	pair, err := s.pair(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	t, err := s.getTicker(ctx, pair.ExchangeSymbol)
+	if err != nil {
+		return nil, err
+	}
+	p := &decimalParser{}
+	out := &dto.BookTicker{
+		Symbol:   pair.Symbol,
+		BidPrice: p.required("b", krakenIndex(t.B, 0)),
+		BidQty:   p.required("b", krakenIndex(t.B, 2)),
+		AskPrice: p.required("a", krakenIndex(t.A, 0)),
+		AskQty:   p.required("a", krakenIndex(t.A, 2)),
+	}
+	if p.err != nil {
+		return nil, fmt.Errorf("error decoding ticker of %s: %w", pair.ExchangeSymbol, p.err)
+	}
+	return out, nil
+}
+
+// GetDepth returns the top limit levels of the book.
+func (s *krakenSvc) GetDepth(ctx context.Context, symbol string, limit int) (*dto.DepthSnapshot, error) {
+	pair, err := s.pair(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	limit = min(max(limit, 1), krakenMaxDepth)
+	params := map[string]string{
+		"pair":  pair.ExchangeSymbol,
+		"count": strconv.Itoa(limit),
+	}
+	book, err := getWithCache(ctx, s.restClient, "depth", fmt.Sprintf("%s-%d", pair.ExchangeSymbol, limit), s.baseURL+"/0/public/Depth", params, parseKrakenDepth)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.DepthSnapshot{
+		LastUpdateID: book.LastUpdateID,
+		Bids:         book.Bids[:min(limit, len(book.Bids))],
+		Asks:         book.Asks[:min(limit, len(book.Asks))],
+	}, nil
+}
+
+// GetTrades returns the latest trades.
+func (s *krakenSvc) GetTrades(ctx context.Context, symbol string, limit int) ([]dto.Trade, error) {
+	pair, err := s.pair(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	limit = min(max(limit, 1), krakenMaxTrades)
+	params := map[string]string{
+		"pair":  pair.ExchangeSymbol,
+		"count": strconv.Itoa(limit),
+	}
+	trades, err := getWithCache(ctx, s.restClient, "recenttrades", fmt.Sprintf("%s-%d", pair.ExchangeSymbol, limit), s.baseURL+"/0/public/Trades", params, parseKrakenTrades)
+	if err != nil {
+		return nil, err
+	}
+	return trades[len(trades)-min(limit, len(trades)):], nil
+}
+
+// GetKlines returns the latest limit candles. Kraken always answers with up to
+// 720 candles, so they are cached once per interval.
+func (s *krakenSvc) GetKlines(ctx context.Context, symbol, interval string, limit int) ([]dto.Kline, error) {
+	length, ok := krakenIntervals[interval]
+	if !ok {
+		return nil, fmt.Errorf("%w %q on %s", ErrUnsupportedInterval, interval, ExchangeKraken)
+	}
+	pair, err := s.pair(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	params := map[string]string{
+		"pair":     pair.ExchangeSymbol,
+		"interval": strconv.FormatInt(int64(length.Minutes()), 10),
+	}
+	klines, err := getWithCache(ctx, s.restClient, "klines", fmt.Sprintf("%s-%s", pair.ExchangeSymbol, interval), s.baseURL+"/0/public/OHLC", params, parseKrakenOHLC(length))
+	if err != nil {
+		return nil, err
+	}
+	return klines[len(klines)-min(max(limit, 1), krakenMaxCandles, len(klines)):], nil
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/ntdat104/go-finance-dataset/internal/application/dto"
+	"github.com/ntdat104/go-finance-dataset/pkg/config"
+)
+
+func newKrakenFixtureProvider(t *testing.T) MarketDataProvider {
+	return newFixtureProvider(t, ExchangeKraken, func(baseURL string, c *http.Client, cache LocalCacheSvc, cfg config.Cache) MarketDataProvider {
+		return NewKrakenProvider(baseURL, c, cache, cfg)
+	})
+}
+
+func TestKrakenAsset(t *testing.T) {
+	tests := map[string]string{
+		"XXBT": "BTC",
+		"XBT":  "BTC",
+		"XXDG": "DOGE",
+		"XETH": "ETH",
+		"ZEUR": "EUR",
+		"ZUSD": "USD",
+		"SOL":  "SOL",
+		"USDT": "USDT",
+	}
+	for code, want := range tests {
+		if got := krakenAsset(code); got != want {
+			t.Errorf("krakenAsset(%q) = %q, want %q", code, got, want)
+		}
+	}
+}
+
+func TestKrakenSymbols(t *testing.T) {
+	p := newKrakenFixtureProvider(t)
+	symbols, err := p.GetSymbols(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []dto.MarketSymbol{
+		{Symbol: "SOLEUR", ExchangeSymbol: "SOLEUR", BaseAsset: "SOL", QuoteAsset: "EUR", Status: "cancel_only"},
+		{Symbol: "ETHEUR", ExchangeSymbol: "XETHZEUR", BaseAsset: "ETH", QuoteAsset: "EUR", Status: "online", Active: true},
+		{Symbol: "BTCEUR", ExchangeSymbol: "XXBTZEUR", BaseAsset: "BTC", QuoteAsset: "EUR", Status: "online", Active: true},
+		{Symbol: "BTCUSD", ExchangeSymbol: "XXBTZUSD", BaseAsset: "BTC", QuoteAsset: "USD", Status: "online", Active: true},
+		{Symbol: "DOGEEUR", ExchangeSymbol: "XXDGZEUR", BaseAsset: "DOGE", QuoteAsset: "EUR", Status: "online", Active: true},
+	}
+	if len(symbols) != len(want) {
+		t.Fatalf("%d symbols, want %d", len(symbols), len(want))
+	}
+	for i := range want {
+		if symbols[i] != want[i] {
+			t.Errorf("symbols[%d] = %+v, want %+v", i, symbols[i], want[i])
+		}
+	}
+}
+
+func TestKrakenTickerAndBook(t *testing.T) {
+	p := newKrakenFixtureProvider(t)
+	// The common symbol, the pair name and the altname all resolve.
+	for _, symbol := range []string{"BTCEUR", "XXBTZEUR", "xbteur"} {
+		ticker, err := p.GetTicker(context.Background(), symbol)
+		if err != nil {
+			t.Fatalf("GetTicker(%q): %v", symbol, err)
+		}
+		if ticker.Symbol != "BTCEUR" {
+			t.Errorf("Symbol = %q, want BTCEUR", ticker.Symbol)
+		}
+		assertDecimal(t, "LastPrice", ticker.LastPrice, "59210.5")
+		assertDecimal(t, "BidPrice", ticker.BidPrice, "59210.4")
+		assertDecimal(t, "AskPrice", ticker.AskPrice, "59210.5")
+		assertDecimal(t, "OpenPrice", ticker.OpenPrice, "58677.5064")
+		assertDecimal(t, "HighPrice", ticker.HighPrice, "59861.7144")
+		assertDecimal(t, "LowPrice", ticker.LowPrice, "57848.5608")
+		assertDecimal(t, "Volume", ticker.Volume, "1803.2")
+		assertDecimal(t, "QuoteVolume", ticker.QuoteVolume, "106127584.12032")
+	}
+
+	book, err := p.GetBook(context.Background(), "BTCEUR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertDecimal(t, "BidQty", book.BidQty, "3")
+	assertDecimal(t, "AskQty", book.AskQty, "1")
+
+	if _, err := p.GetTicker(context.Background(), "BTCJPY"); !errors.Is(err, ErrUnknownSymbol) {
+		t.Fatalf("GetTicker(BTCJPY) error = %v, want ErrUnknownSymbol", err)
+	}
+}
+
+func TestKrakenDepth(t *testing.T) {
+	p := newKrakenFixtureProvider(t)
+	depth, err := p.GetDepth(context.Background(), "BTCEUR", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(depth.Bids) != 10 || len(depth.Asks) != 10 {
+		t.Fatalf("depth has %d bids and %d asks, want 10 each", len(depth.Bids), len(depth.Asks))
+	}
+	// Kraken has no sequence number; the newest level timestamp stands in.
+	if depth.LastUpdateID != 1746100800000 {
+		t.Errorf("LastUpdateID = %d, want 1746100800000", depth.LastUpdateID)
+	}
+	assertDecimal(t, "Bids[0].Price", depth.Bids[0].Price, "59210.4")
+	assertDecimal(t, "Bids[0].Quantity", depth.Bids[0].Quantity, "2.309")
+	assertDecimal(t, "Asks[0].Price", depth.Asks[0].Price, "59210.5")
+	assertDecimal(t, "Asks[0].Quantity", depth.Asks[0].Quantity, "1.869")
+
+	// A non-positive limit still returns the top level.
+	for _, limit := range []int{0, -1} {
+		depth, err := p.GetDepth(context.Background(), "BTCEUR", limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(depth.Bids) != 1 || len(depth.Asks) != 1 {
+			t.Errorf("GetDepth(limit %d) has %d bids and %d asks, want 1 each", limit, len(depth.Bids), len(depth.Asks))
+		}
+	}
+}
+
+func TestKrakenTrades(t *testing.T) {
+	p := newKrakenFixtureProvider(t)
+	trades, err := p.GetTrades(context.Background(), "BTCEUR", 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trades) != 200 {
+		t.Fatalf("%d trades, want 200", len(trades))
+	}
+	first, last := trades[0], trades[len(trades)-1]
+	if first.ID != 71545010 || last.ID != 71545209 {
+		t.Fatalf("trade ids run %d..%d, want 71545010..71545209", first.ID, last.ID)
+	}
+	// The side is the taker's: a buy means the seller was the maker.
+	if first.IsBuyerMaker || !last.IsBuyerMaker {
+		t.Errorf("IsBuyerMaker = %v/%v for buy/sell takers, want false/true", first.IsBuyerMaker, last.IsBuyerMaker)
+	}
+	if first.Time != 1746100739182 {
+		t.Errorf("Time = %d, want 1746100739182", first.Time)
+	}
+	assertDecimal(t, "Price", first.Price, "59222.81741")
+	assertDecimal(t, "Qty", first.Qty, "0.5233073")
+
+	trades, err = p.GetTrades(context.Background(), "BTCEUR", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trades) != 5 || trades[4].ID != 71545209 {
+		t.Fatalf("GetTrades(5) = %d trades ending at %d, want the 5 latest", len(trades), trades[len(trades)-1].ID)
+	}
+
+	for _, limit := range []int{0, -1} {
+		trades, err := p.GetTrades(context.Background(), "BTCEUR", limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(trades) != 1 || trades[0].ID != 71545209 {
+			t.Errorf("GetTrades(limit %d) = %d trades, want the latest one", limit, len(trades))
+		}
+	}
+}
+
+func TestKrakenKlines(t *testing.T) {
+	p := newKrakenFixtureProvider(t)
+	tests := []struct {
+		interval  string
+		openTime  int64
+		closeTime int64
+		close     string
+		trades    int64
+	}{
+		{"1m", 1746100800000, 1746100859999, "56933.7", 242},
+		{"1h", 1746100800000, 1746104399999, "54705.4", 270},
+		{"1d", 1746057600000, 1746143999999, "34212.7", 177},
+	}
+	for _, tt := range tests {
+		klines, err := p.GetKlines(context.Background(), "BTCEUR", tt.interval, 50)
+		if err != nil {
+			t.Fatalf("GetKlines(%s): %v", tt.interval, err)
+		}
+		if len(klines) != 50 {
+			t.Fatalf("GetKlines(%s) = %d klines, want 50", tt.interval, len(klines))
+		}
+		k := klines[len(klines)-1]
+		if k.OpenTime != tt.openTime || k.CloseTime != tt.closeTime {
+			t.Errorf("%s: newest candle spans %d..%d, want %d..%d", tt.interval, k.OpenTime, k.CloseTime, tt.openTime, tt.closeTime)
+		}
+		assertDecimal(t, tt.interval+" Close", k.Close, tt.close)
+		if k.NumberOfTrades != tt.trades {
+			t.Errorf("%s: NumberOfTrades = %d, want %d", tt.interval, k.NumberOfTrades, tt.trades)
+		}
+	}
+	for _, limit := range []int{0, -1} {
+		klines, err := p.GetKlines(context.Background(), "BTCEUR", "1m", limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(klines) != 1 {
+			t.Errorf("GetKlines(limit %d) = %d klines, want 1", limit, len(klines))
+		}
+	}
+	if _, err := p.GetKlines(context.Background(), "BTCEUR", "3m", 10); !errors.Is(err, ErrUnsupportedInterval) {
+		t.Fatalf("GetKlines(3m) error = %v, want ErrUnsupportedInterval", err)
+	}
+}
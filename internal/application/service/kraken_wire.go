@@ -0,0 +1,291 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ntdat104/go-finance-dataset/internal/application/dto"
+	"github.com/ntdat104/go-finance-dataset/pkg/decimal"
+)
+
+// Wire types mirror the raw Kraken public REST payloads. Every response is wrapped
+// in {"error": [...], "result": ...} and reports failures with a 200 status.
+
+type krakenEnvelope struct {
+	Error  []string        `json:"error"`
+	Result json.RawMessage `json:"result"`
+}
+
+type krakenAssetPair struct {
+	Altname string `json:"altname"`
+	Wsname  string `json:"wsname"`
+	Base    string `json:"base"`
+	Quote   string `json:"quote"`
+	Status  string `json:"status"`
+}
+
+// krakenTicker holds [price, whole lot volume, lot volume] for a and b,
+// [price, lot volume] for c and [today, last 24 hours] for v, p, l and h.
+// Kraken sends no timestamp, so FetchedAt is set when the ticker is parsed.
+type krakenTicker struct {
+	A         []string `json:"a"`
+	B         []string `json:"b"`
+	C         []string `json:"c"`
+	V         []string `json:"v"`
+	P         []string `json:"p"`
+	L         []string `json:"l"`
+	H         []string `json:"h"`
+	O         string   `json:"o"`
+	FetchedAt int64    `json:"fetched_at"`
+}
+
+// krakenBookLevel is [price, volume, timestamp].
+type krakenBookLevel []json.RawMessage
+
+type krakenDepth struct {
+	Asks []krakenBookLevel `json:"asks"`
+	Bids []krakenBookLevel `json:"bids"`
+}
+
+// krakenTrade is [price, volume, time, side, order type, misc, trade id].
+type krakenTrade []json.RawMessage
+
+// krakenOHLC is [time, open, high, low, close, vwap, volume, count].
+type krakenOHLC []json.RawMessage
+
+// krakenAssets maps the legacy Kraken asset codes that do not reduce to the
+// common code by dropping their X or Z class prefix.
+var krakenAssets = map[string]string{
+	"XXBT": "BTC",
+	"XBT":  "BTC",
+	"XXDG": "DOGE",
+	"XDG":  "DOGE",
+}
+
+// krakenAsset returns the common code of a Kraken asset, e.g. BTC for XXBT and USD for ZUSD.
+func krakenAsset(code string) string {
+	if asset, ok := krakenAssets[code]; ok {
+		return asset
+	}
+	if len(code) == 4 && (code[0] == 'X' || code[0] == 'Z') {
+		return code[1:]
+	}
+	return code
+}
+
+// decodeKraken unwraps the response envelope, turning reported errors into Go errors.
+func decodeKraken(data []byte, v any) error {
+	var env krakenEnvelope
+	if err := decodeStrict(data, &env); err != nil {
+		return err
+	}
+	if len(env.Error) > 0 {
+		return fmt.Errorf("kraken error: %s", strings.Join(env.Error, ", "))
+	}
+	return json.Unmarshal(env.Result, v)
+}
+
+// decodeKrakenPair decodes the result entry of the single pair a request asked for,
+// ignoring the "last" cursor that some endpoints add next to it.
+func decodeKrakenPair(data []byte, v any) error {
+	var result map[string]json.RawMessage
+	if err := decodeKraken(data, &result); err != nil {
+		return err
+	}
+	for key, raw := range result {
+		if key != "last" {
+			return json.Unmarshal(raw, v)
+		}
+	}
+	return errors.New("result: missing pair")
+}
+
+func krakenString(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return ""
+	}
+	return s
+}
+
+func krakenSeconds(raw json.RawMessage) (float64, error) {
+	var f float64
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return 0, err
+	}
+	return f, nil
+}
+
+func krakenIndex(values []string, i int) string {
+	if i < len(values) {
+		return values[i]
+	}
+	return ""
+}
+
+func parseKrakenAssetPairs(data []byte) ([]dto.MarketSymbol, error) {
+	var w map[string]krakenAssetPair
+	if err := decodeKraken(data, &w); err != nil {
+		return nil, err
+	}
+	out := make([]dto.MarketSymbol, 0, len(w))
+	for name, pair := range w {
+		if pair.Base == "" || pair.Quote == "" {
+			return nil, fmt.Errorf("pair %s: field base or quote missing", name)
+		}
+		base, quote := krakenAsset(pair.Base), krakenAsset(pair.Quote)
+		out = append(out, dto.MarketSymbol{
+			Symbol:         base + quote,
+			ExchangeSymbol: name,
+			BaseAsset:      base,
+			QuoteAsset:     quote,
+			Status:         pair.Status,
+			Active:         pair.Status == "online",
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ExchangeSymbol < out[j].ExchangeSymbol })
+	return out, nil
+}
+
+func parseKrakenTicker(data []byte) (*krakenTicker, error) {
+	var w krakenTicker
+	if err := decodeKrakenPair(data, &w); err != nil {
+		return nil, err
+	}
+	if len(w.C) == 0 {
+		return nil, errors.New("field c: missing")
+	}
+	w.FetchedAt = time.Now().UnixMilli()
+	return &w, nil
+}
+
+func parseKrakenLevels(side string, levels []krakenBookLevel) ([]dto.PriceLevel, error) {
+	out := make([]dto.PriceLevel, 0, len(levels))
+	for i, level := range levels {
+		if len(level) < 2 {
+			return nil, fmt.Errorf("%s[%d]: expected at least 2 fields, got %d", side, i, len(level))
+		}
+		p := &decimalParser{}
+		pl := dto.PriceLevel{
+			Price:    p.required("price", krakenString(level[0])),
+			Quantity: p.required("volume", krakenString(level[1])),
+		}
+		if p.err != nil {
+			return nil, fmt.Errorf("%s[%d]: %w", side, i, p.err)
+		}
+		out = append(out, pl)
+	}
+	return out, nil
+}
+
+// parseKrakenDepth converts the book. Kraken has no book sequence number, so the
+// update id is the newest level timestamp in milliseconds.
+func parseKrakenDepth(data []byte) (*dto.DepthSnapshot, error) {
+	var w krakenDepth
+	if err := decodeKrakenPair(data, &w); err != nil {
+		return nil, err
+	}
+	bids, err := parseKrakenLevels("bids", w.Bids)
+	if err != nil {
+		return nil, err
+	}
+	asks, err := parseKrakenLevels("asks", w.Asks)
+	if err != nil {
+		return nil, err
+	}
+	var lastUpdate float64
+	for _, side := range [][]krakenBookLevel{w.Bids, w.Asks} {
+		for _, level := range side {
+			if len(level) > 2 {
+				if ts, err := krakenSeconds(level[2]); err == nil {
+					lastUpdate = math.Max(lastUpdate, ts)
+				}
+			}
+		}
+	}
+	return &dto.DepthSnapshot{LastUpdateID: int64(lastUpdate * 1000), Bids: bids, Asks: asks}, nil
+}
+
+func parseKrakenTrades(data []byte) ([]dto.Trade, error) {
+	var w []krakenTrade
+	if err := decodeKrakenPair(data, &w); err != nil {
+		return nil, err
+	}
+	out := make([]dto.Trade, 0, len(w))
+	for i, item := range w {
+		if len(item) < 7 {
+			return nil, fmt.Errorf("trade[%d]: expected 7 fields, got %d", i, len(item))
+		}
+		ts, err := krakenSeconds(item[2])
+		if err != nil {
+			return nil, fmt.Errorf("trade[%d]: field time: %w", i, err)
+		}
+		var id int64
+		if err := json.Unmarshal(item[6], &id); err != nil {
+			return nil, fmt.Errorf("trade[%d]: field trade_id: %w", i, err)
+		}
+		p := &decimalParser{}
+		t := dto.Trade{
+			ID:    id,
+			Price: p.required("price", krakenString(item[0])),
+			Qty:   p.required("volume", krakenString(item[1])),
+			Time:  int64(ts * 1000),
+			// The side is the taker's, so a sell means the buyer was the maker.
+			IsBuyerMaker: krakenString(item[3]) == "s",
+		}
+		if p.err != nil {
+			return nil, fmt.Errorf("trade[%d]: %w", i, p.err)
+		}
+		t.QuoteQty = t.Price.Mul(t.Qty)
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+// parseKrakenOHLC returns the candles oldest first, the last one still open.
+func parseKrakenOHLC(interval time.Duration) func([]byte) ([]dto.Kline, error) {
+	return func(data []byte) ([]dto.Kline, error) {
+		var w []krakenOHLC
+		if err := decodeKrakenPair(data, &w); err != nil {
+			return nil, err
+		}
+		out := make([]dto.Kline, 0, len(w))
+		for i, item := range w {
+			if len(item) < 8 {
+				return nil, fmt.Errorf("candle[%d]: expected 8 fields, got %d", i, len(item))
+			}
+			var openTime, count int64
+			if err := json.Unmarshal(item[0], &openTime); err != nil {
+				return nil, fmt.Errorf("candle[%d]: field time: %w", i, err)
+			}
+			if err := json.Unmarshal(item[7], &count); err != nil {
+				return nil, fmt.Errorf("candle[%d]: field count: %w", i, err)
+			}
+			p := &decimalParser{}
+			k := dto.Kline{
+				OpenTime:                 openTime * 1000,
+				Open:                     p.required("open", krakenString(item[1])),
+				High:                     p.required("high", krakenString(item[2])),
+				Low:                      p.required("low", krakenString(item[3])),
+				Close:                    p.required("close", krakenString(item[4])),
+				Volume:                   p.required("volume", krakenString(item[6])),
+				CloseTime:                openTime*1000 + interval.Milliseconds() - 1,
+				NumberOfTrades:           count,
+				TakerBuyBaseAssetVolume:  decimal.Zero,
+				TakerBuyQuoteAssetVolume: decimal.Zero,
+			}
+			vwap := p.required("vwap", krakenString(item[5]))
+			if p.err != nil {
+				return nil, fmt.Errorf("candle[%d]: %w", i, p.err)
+			}
+			k.QuoteAssetVolume = vwap.Mul(k.Volume)
+			out = append(out, k)
+		}
+		return out, nil
+	}
+}
@@ -1,29 +1,78 @@
 package service
 
 import (
+	"container/list"
+	"reflect"
+	"slices"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/ntdat104/go-finance-dataset/internal/application/dto"
 )
 
+// LocalCacheSvc is the cache backend interface. The in-memory implementation
+// holds values as they are; shared backends (see cache_backend.go) serialize them.
 type LocalCacheSvc interface {
 	Set(key string, value any, ttl time.Duration)
 	Get(key string) (any, bool)
 	Del(key string)
 	Has(key string) bool
+	// Peek returns an unexpired value without touching recency or hit stats.
+	Peek(key string) (any, bool)
+	GetExpireTime(key string) (*time.Time, bool)
+	// Keys returns the unexpired keys starting with prefix, sorted.
+	Keys(prefix string) []string
+	// DelPrefix deletes every key starting with prefix and returns how many were deleted.
+	DelPrefix(prefix string) int
+	// Hydrate swaps the value of an existing entry for its decoded form in the
+	// in-memory tier, keeping its expiry but evicting as Set does since the
+	// decoded form is usually larger. Shared tiers are left untouched.
+	Hydrate(key string, value any)
+	Stats() dto.LocalCacheStats
 }
 
+const (
+	// cacheEntryOverhead approximates the bookkeeping cost of one entry
+	// (map slot, list element and cacheItem) on top of key and value.
+	cacheEntryOverhead = 128
+	// maxSizeDepth bounds how many references the size estimate follows, so
+	// cyclic values cannot recurse forever.
+	maxSizeDepth = 64
+)
+
 type cacheItem struct {
+	key        string
 	value      any
 	expireTime time.Time
+	size       int64
 }
 
+// localCacheSvc is an in-memory cache bounded by entry count and by an
+// approximate byte size. When either bound is exceeded the least recently
+// used entries are evicted.
 type localCacheSvc struct {
-	store sync.Map
+	lock        sync.Mutex
+	items       map[string]*list.Element
+	lru         *list.List // front is the most recently used
+	maxEntries  int
+	maxBytes    int64
+	bytes       int64
+	hits        int64
+	misses      int64
+	evictions   int64
+	expirations int64
+	rejected    int64
 }
 
-func NewLocalCacheSvc() LocalCacheSvc {
+// NewLocalCacheSvc creates a cache holding at most maxEntries entries and
+// about maxBytes bytes. A bound of zero or less is not enforced.
+func NewLocalCacheSvc(maxEntries int, maxBytes int64) LocalCacheSvc {
 	s := &localCacheSvc{
-		store: sync.Map{},
+		items:      make(map[string]*list.Element),
+		lru:        list.New(),
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
 	}
 	// Start cleanup ticker
 	go func() {
@@ -37,39 +86,121 @@ func NewLocalCacheSvc() LocalCacheSvc {
 }
 
 func (c *localCacheSvc) Set(key string, value any, ttl time.Duration) {
-	expire := time.Now().Add(ttl)
-	c.store.Store(key, cacheItem{
+	item := &cacheItem{
+		key:        key,
 		value:      value,
-		expireTime: expire,
-	})
+		expireTime: time.Now().Add(ttl),
+		size:       int64(len(key)) + estimateSize(reflect.ValueOf(value), 0) + cacheEntryOverhead,
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		c.removeElement(elem)
+	}
+	if c.maxBytes > 0 && item.size > c.maxBytes {
+		c.rejected++
+		return
+	}
+	c.items[key] = c.lru.PushFront(item)
+	c.bytes += item.size
+	c.evictLocked()
+}
+
+// evictLocked drops least recently used entries until both bounds hold.
+func (c *localCacheSvc) evictLocked() {
+	for (c.maxEntries > 0 && len(c.items) > c.maxEntries) || (c.maxBytes > 0 && c.bytes > c.maxBytes) {
+		c.removeElement(c.lru.Back())
+		c.evictions++
+	}
 }
 
 func (c *localCacheSvc) GetExpireTime(key string) (*time.Time, bool) {
-	val, ok := c.store.Load(key)
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	elem, ok := c.items[key]
 	if !ok {
 		return nil, false
 	}
-
-	item := val.(cacheItem)
+	item := elem.Value.(*cacheItem)
 	return &item.expireTime, true
 }
 
 func (c *localCacheSvc) Get(key string) (any, bool) {
-	val, ok := c.store.Load(key)
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	elem, ok := c.items[key]
 	if !ok {
+		c.misses++
 		return nil, false
 	}
 
-	item := val.(cacheItem)
+	item := elem.Value.(*cacheItem)
 	if time.Now().After(item.expireTime) {
-		c.store.Delete(key)
+		c.removeElement(elem)
+		c.expirations++
+		c.misses++
 		return nil, false
 	}
+	c.lru.MoveToFront(elem)
+	c.hits++
 	return item.value, true
 }
 
+func (c *localCacheSvc) Peek(key string) (any, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	item := elem.Value.(*cacheItem)
+	if time.Now().After(item.expireTime) {
+		return nil, false
+	}
+	return item.value, true
+}
+
+func (c *localCacheSvc) Keys(prefix string) []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := time.Now()
+	keys := make([]string, 0)
+	for key, elem := range c.items {
+		if strings.HasPrefix(key, prefix) && !now.After(elem.Value.(*cacheItem).expireTime) {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func (c *localCacheSvc) DelPrefix(prefix string) int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	deleted := 0
+	for key, elem := range c.items {
+		if strings.HasPrefix(key, prefix) {
+			c.removeElement(elem)
+			deleted++
+		}
+	}
+	return deleted
+}
+
 func (c *localCacheSvc) Del(key string) {
-	c.store.Delete(key)
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		c.removeElement(elem)
+	}
 }
 
 func (c *localCacheSvc) Has(key string) bool {
@@ -77,13 +208,121 @@ func (c *localCacheSvc) Has(key string) bool {
 	return exists
 }
 
+func (c *localCacheSvc) Hydrate(key string, value any) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return
+	}
+	item := elem.Value.(*cacheItem)
+	size := int64(len(key)) + estimateSize(reflect.ValueOf(value), 0) + cacheEntryOverhead
+	if c.maxBytes > 0 && size > c.maxBytes {
+		c.removeElement(elem)
+		c.rejected++
+		return
+	}
+	c.bytes += size - item.size
+	item.value = value
+	item.size = size
+	c.evictLocked()
+}
+
+func (c *localCacheSvc) Stats() dto.LocalCacheStats {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return dto.LocalCacheStats{
+		Backend:     CacheBackendMemory,
+		Entries:     len(c.items),
+		Bytes:       c.bytes,
+		MaxEntries:  c.maxEntries,
+		MaxBytes:    c.maxBytes,
+		Hits:        c.hits,
+		Misses:      c.misses,
+		Evictions:   c.evictions,
+		Expirations: c.expirations,
+		Rejected:    c.rejected,
+	}
+}
+
+func (c *localCacheSvc) removeElement(elem *list.Element) {
+	item := c.lru.Remove(elem).(*cacheItem)
+	delete(c.items, item.key)
+	c.bytes -= item.size
+}
+
 func (c *localCacheSvc) cleanUp() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
 	now := time.Now()
-	c.store.Range(func(key, val any) bool {
-		item := val.(cacheItem)
-		if now.After(item.expireTime) {
-			c.store.Delete(key)
+	for elem := c.lru.Back(); elem != nil; {
+		prev := elem.Prev()
+		if now.After(elem.Value.(*cacheItem).expireTime) {
+			c.removeElement(elem)
+			c.expirations++
+		}
+		elem = prev
+	}
+}
+
+// estimateSize approximates the memory held by v, counting headers, string
+// and slice backing arrays and everything reachable through pointers.
+func estimateSize(v reflect.Value, depth int) int64 {
+	if !v.IsValid() {
+		return 0
+	}
+	return int64(v.Type().Size()) + estimateIndirect(v, depth)
+}
+
+// estimateIndirect returns the bytes v refers to outside of its own header.
+// Only following a pointer, slice or map goes one level deeper, so struct
+// fields are counted however deeply they nest.
+func estimateIndirect(v reflect.Value, depth int) int64 {
+	switch v.Kind() {
+	case reflect.String:
+		return int64(v.Len())
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() || depth >= maxSizeDepth {
+			return 0
 		}
-		return true
-	})
+		return estimateSize(v.Elem(), depth+1)
+	case reflect.Slice:
+		if v.IsNil() {
+			return 0
+		}
+		n := int64(v.Cap()) * int64(v.Type().Elem().Size())
+		if depth >= maxSizeDepth {
+			return n
+		}
+		for i := range v.Len() {
+			n += estimateIndirect(v.Index(i), depth+1)
+		}
+		return n
+	case reflect.Array:
+		var n int64
+		for i := range v.Len() {
+			n += estimateIndirect(v.Index(i), depth)
+		}
+		return n
+	case reflect.Struct:
+		var n int64
+		for i := range v.NumField() {
+			n += estimateIndirect(v.Field(i), depth)
+		}
+		return n
+	case reflect.Map:
+		if depth >= maxSizeDepth {
+			return 0
+		}
+		var n int64
+		iter := v.MapRange()
+		for iter.Next() {
+			n += estimateSize(iter.Key(), depth+1) + estimateSize(iter.Value(), depth+1)
+		}
+		return n
+	}
+	return 0
 }
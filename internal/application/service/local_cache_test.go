@@ -0,0 +1,139 @@
+package service
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocalCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewLocalCacheSvc(3, 0)
+	for _, key := range []string{"a", "b", "c"} {
+		c.Set(key, key, time.Minute)
+	}
+	// Reading a makes b the least recently used.
+	c.Get("a")
+	c.Set("d", "d", time.Minute)
+	if stats := c.Stats(); stats.Entries != 3 || stats.Evictions != 1 {
+		t.Fatalf("stats = %+v", stats)
+	}
+	if c.Has("b") || !c.Has("a") || !c.Has("c") || !c.Has("d") {
+		t.Error("want b evicted")
+	}
+	// Overwriting a key does not evict anything.
+	c.Set("c", "c2", time.Minute)
+	if stats := c.Stats(); stats.Entries != 3 || stats.Evictions != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestLocalCacheEvictsBySize(t *testing.T) {
+	value := strings.Repeat("x", 100)
+	entry := int64(len("k1")) + estimateSize(reflect.ValueOf(value), 0) + cacheEntryOverhead
+	c := NewLocalCacheSvc(0, 2*entry)
+
+	c.Set("k1", value, time.Minute)
+	c.Set("k2", value, time.Minute)
+	if stats := c.Stats(); stats.Bytes != 2*entry || stats.Evictions != 0 {
+		t.Fatalf("stats = %+v, want two entries of %d bytes", stats, entry)
+	}
+	c.Set("k3", value, time.Minute)
+	if c.Has("k1") || !c.Has("k2") || !c.Has("k3") {
+		t.Error("want k1 evicted")
+	}
+
+	// A value larger than the whole cache is rejected, not stored at the cost of everything else.
+	c.Set("big", strings.Repeat("x", int(2*entry)), time.Minute)
+	stats := c.Stats()
+	if c.Has("big") || stats.Rejected != 1 || stats.Entries != 2 || stats.Bytes != 2*entry {
+		t.Errorf("after an oversized Set: stats = %+v", stats)
+	}
+}
+
+func TestLocalCacheExpiry(t *testing.T) {
+	c := NewLocalCacheSvc(0, 0)
+	c.Set("gone", 1, -time.Second)
+	c.Set("kept", 1, time.Minute)
+	if _, ok := c.Get("gone"); ok {
+		t.Error("Get returned an expired entry")
+	}
+	if stats := c.Stats(); stats.Entries != 1 || stats.Expirations != 1 || stats.Misses != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestLocalCacheHydrate(t *testing.T) {
+	decoded := strings.Repeat("x", 1000)
+	entry := int64(len("k1")) + estimateSize(reflect.ValueOf(decoded), 0) + cacheEntryOverhead
+	c := NewLocalCacheSvc(0, 2*entry-1)
+	c.Set("k1", "raw", time.Minute)
+	c.Set("k2", "raw", time.Minute)
+
+	c.Hydrate("k1", decoded)
+	if stats := c.Stats(); stats.Entries != 2 || stats.Evictions != 0 {
+		t.Fatalf("stats = %+v, want both entries kept", stats)
+	}
+	// Growing k2 as well goes over the byte bound, so the least recently used k1 goes.
+	c.Hydrate("k2", decoded)
+	if stats := c.Stats(); stats.Entries != 1 || stats.Evictions != 1 || stats.Bytes != entry {
+		t.Errorf("stats = %+v, want k1 evicted", stats)
+	}
+	if _, ok := c.Peek("k1"); ok {
+		t.Error("k1 still cached")
+	}
+	// A decoded value larger than the whole cache drops the entry.
+	c.Hydrate("k2", strings.Repeat("x", int(2*entry)))
+	if stats := c.Stats(); stats.Entries != 0 || stats.Bytes != 0 || stats.Rejected != 1 {
+		t.Errorf("after an oversized Hydrate: stats = %+v", stats)
+	}
+	// Hydrating a missing key does nothing.
+	c.Hydrate("k3", decoded)
+	if stats := c.Stats(); stats.Entries != 0 {
+		t.Errorf("Hydrate created an entry: %+v", stats)
+	}
+}
+
+func TestEstimateSize(t *testing.T) {
+	type level struct {
+		Price string
+		Qty   string
+	}
+	tests := []struct {
+		name  string
+		value any
+		want  int64
+	}{
+		{"int", 1, 8},
+		{"string", "abcd", 16 + 4},
+		{"slice", []int64{1, 2}, 24 + 16},
+		{"struct", level{"1", "22"}, 32 + 3},
+		{"pointer", &level{"1", "22"}, 8 + 32 + 3},
+		{"nil pointer", (*level)(nil), 8},
+	}
+	for _, tt := range tests {
+		if got := estimateSize(reflect.ValueOf(tt.value), 0); got != tt.want {
+			t.Errorf("%s: estimateSize = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	// Strings are counted however deeply the value nests.
+	type node struct {
+		Next *node
+		Name string
+	}
+	var list *node
+	for range 20 {
+		list = &node{Next: list, Name: "abcd"}
+	}
+	if got, want := estimateSize(reflect.ValueOf(list), 0), int64(8+20*(24+4)); got != want {
+		t.Errorf("nested list: estimateSize = %d, want %d", got, want)
+	}
+
+	// A cyclic value stops at the depth limit.
+	cycle := &node{Name: "abcd"}
+	cycle.Next = cycle
+	if got := estimateSize(reflect.ValueOf(cycle), 0); got != 8+maxSizeDepth*(24+4) {
+		t.Errorf("cycle: estimateSize = %d", got)
+	}
+}
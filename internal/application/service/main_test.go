@@ -0,0 +1,13 @@
+package service
+
+import (
+	"os"
+	"testing"
+
+	"github.com/ntdat104/go-finance-dataset/pkg/logger"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitDefault()
+	os.Exit(m.Run())
+}
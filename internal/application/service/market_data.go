@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ntdat104/go-finance-dataset/internal/application/dto"
+)
+
+// Exchange names used in the /api/v1/crypto/{exchange}/... routes.
+const (
+	ExchangeBinance = "binance"
+)
+
+var (
+	ErrUnknownExchange     = errors.New("unknown exchange")
+	ErrUnsupportedInterval = errors.New("unsupported interval")
+	ErrUnknownSymbol       = errors.New("unknown symbol")
+)
+
+// MarketDataProvider is the market data every exchange serves. Symbols use the
+// exchange-independent naming of dto.MarketSymbol and intervals the Binance
+// kline interval names; each provider maps them to its own API. Trades and
+// klines are returned oldest first.
+type MarketDataProvider interface {
+	Exchange() string
+	GetSymbols(ctx context.Context) ([]dto.MarketSymbol, error)
+	GetTicker(ctx context.Context, symbol string) (*dto.MarketTicker, error)
+	GetBook(ctx context.Context, symbol string) (*dto.BookTicker, error)
+	GetDepth(ctx context.Context, symbol string, limit int) (*dto.DepthSnapshot, error)
+	GetTrades(ctx context.Context, symbol string, limit int) ([]dto.Trade, error)
+	GetKlines(ctx context.Context, symbol, interval string, limit int) ([]dto.Kline, error)
+}
+
+// MarketDataSvc looks up the provider of an exchange.
+type MarketDataSvc interface {
+	Provider(exchange string) (MarketDataProvider, error)
+	Exchanges() []string
+}
+
+type marketDataSvc struct {
+	providers map[string]MarketDataProvider
+}
+
+func NewMarketDataSvc(providers ...MarketDataProvider) MarketDataSvc {
+	s := &marketDataSvc{providers: make(map[string]MarketDataProvider, len(providers))}
+	for _, p := range providers {
+		s.providers[p.Exchange()] = p
+	}
+	return s
+}
+
+func (s *marketDataSvc) Provider(exchange string) (MarketDataProvider, error) {
+	p, ok := s.providers[strings.ToLower(exchange)]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownExchange, exchange)
+	}
+	return p, nil
+}
+
+func (s *marketDataSvc) Exchanges() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// binanceProvider serves the common market data from the Binance spot service.
+type binanceProvider struct {
+	binanceSvc BinanceSvc
+}
+
+func NewBinanceProvider(binanceSvc BinanceSvc) MarketDataProvider {
+	return &binanceProvider{binanceSvc: binanceSvc}
+}
+
+func (p *binanceProvider) Exchange() string {
+	return ExchangeBinance
+}
+
+func (p *binanceProvider) GetSymbols(ctx context.Context) ([]dto.MarketSymbol, error) {
+	info, err := p.binanceSvc.GetExchangeInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	symbols := make([]dto.MarketSymbol, 0, len(info.Symbols))
+	for _, sym := range info.Symbols {
+		symbols = append(symbols, dto.MarketSymbol{
+			Symbol:         sym.Symbol,
+			ExchangeSymbol: sym.Symbol,
+			BaseAsset:      sym.BaseAsset,
+			QuoteAsset:     sym.QuoteAsset,
+			Status:         sym.Status,
+			Active:         sym.Status == "TRADING",
+		})
+	}
+	return symbols, nil
+}
+
+func (p *binanceProvider) GetTicker(ctx context.Context, symbol string) (*dto.MarketTicker, error) {
+	t, err := p.binanceSvc.GetTicker24Hr(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.MarketTicker{
+		Symbol:      t.Symbol,
+		LastPrice:   t.LastPrice,
+		BidPrice:    t.BidPrice,
+		AskPrice:    t.AskPrice,
+		OpenPrice:   t.OpenPrice,
+		HighPrice:   t.HighPrice,
+		LowPrice:    t.LowPrice,
+		Volume:      t.Volume,
+		QuoteVolume: t.QuoteVolume,
+		Time:        t.CloseTime,
+	}, nil
+}
+
+func (p *binanceProvider) GetBook(ctx context.Context, symbol string) (*dto.BookTicker, error) {
+	return p.binanceSvc.GetBookTicker(ctx, symbol)
+}
+
+func (p *binanceProvider) GetDepth(ctx context.Context, symbol string, limit int) (*dto.DepthSnapshot, error) {
+	return p.binanceSvc.GetDepth(ctx, symbol, limit)
+}
+
+func (p *binanceProvider) GetTrades(ctx context.Context, symbol string, limit int) ([]dto.Trade, error) {
+	return p.binanceSvc.GetRecentTrades(ctx, symbol, limit)
+}
+
+func (p *binanceProvider) GetKlines(ctx context.Context, symbol, interval string, limit int) ([]dto.Kline, error) {
+	if err := ValidateInterval(interval); err != nil {
+		return nil, fmt.Errorf("%w %q", ErrUnsupportedInterval, interval)
+	}
+	return p.binanceSvc.GetKlines(ctx, symbol, interval, limit)
+}
@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"log"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ntdat104/go-finance-dataset/internal/application/dto"
+)
+
+const (
+	// maxBufferedDepthUpdates bounds the diffs held while waiting for a snapshot.
+	maxBufferedDepthUpdates = 10000
+	orderBookResyncBackoff  = time.Second
+)
+
+var (
+	ErrOrderBookNotTracked = errors.New("order book is not tracked for this symbol")
+	ErrOrderBookNotSynced  = errors.New("order book is synchronising, try again shortly")
+)
+
+// OrderBookSvc maintains local order books from a depth snapshot plus the
+// depth diff stream, following Binance's documented update id sequencing.
+type OrderBookSvc interface {
+	Track(symbol string) error
+	Symbols() []string
+	GetOrderBook(symbol string, limit int) (*dto.OrderBook, error)
+	HandleStreamEvent(event dto.StreamEvent)
+	// Close stops resynchronising books.
+	Close()
+}
+
+// localBook is the state of one symbol's book. Bids are sorted best (highest)
+// first and asks best (lowest) first, and each side keeps at most depth levels.
+type localBook struct {
+	lock         sync.Mutex
+	symbol       string
+	depth        int
+	synced       bool
+	resyncing    bool
+	lastUpdateID int64
+	eventTime    int64
+	bids         []dto.PriceLevel
+	asks         []dto.PriceLevel
+	buffer       []dto.DepthUpdate
+}
+
+type orderBookSvc struct {
+	binanceSvc    BinanceSvc
+	streamSvc     StreamSvc
+	snapshotLimit int
+	books         sync.Map // symbol -> *localBook
+	ctx           context.Context
+	cancel        context.CancelFunc
+}
+
+func NewOrderBookSvc(binanceSvc BinanceSvc, streamSvc StreamSvc, snapshotLimit int) OrderBookSvc {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &orderBookSvc{
+		binanceSvc:    binanceSvc,
+		streamSvc:     streamSvc,
+		snapshotLimit: snapshotLimit,
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+}
+
+func (s *orderBookSvc) Close() {
+	s.cancel()
+}
+
+// Track starts maintaining the book of symbol by subscribing to its depth stream.
+func (s *orderBookSvc) Track(symbol string) error {
+	symbol = strings.ToUpper(symbol)
+	if _, loaded := s.books.LoadOrStore(symbol, &localBook{symbol: symbol, depth: s.snapshotLimit}); loaded {
+		return nil
+	}
+	if err := s.streamSvc.Subscribe(strings.ToLower(symbol) + "@depth@100ms"); err != nil {
+		s.books.Delete(symbol)
+		return err
+	}
+	return nil
+}
+
+// Symbols returns the tracked symbols.
+func (s *orderBookSvc) Symbols() []string {
+	var symbols []string
+	s.books.Range(func(key, _ any) bool {
+		symbols = append(symbols, key.(string))
+		return true
+	})
+	slices.Sort(symbols)
+	return symbols
+}
+
+// GetOrderBook returns the top limit levels per side, or the full book when limit is not positive.
+func (s *orderBookSvc) GetOrderBook(symbol string, limit int) (*dto.OrderBook, error) {
+	val, ok := s.books.Load(strings.ToUpper(symbol))
+	if !ok {
+		return nil, ErrOrderBookNotTracked
+	}
+	book := val.(*localBook)
+	book.lock.Lock()
+	defer book.lock.Unlock()
+
+	if !book.synced {
+		return nil, ErrOrderBookNotSynced
+	}
+	out := &dto.OrderBook{
+		Symbol:       book.symbol,
+		LastUpdateID: book.lastUpdateID,
+		EventTime:    book.eventTime,
+		Bids:         topLevels(book.bids, limit),
+		Asks:         topLevels(book.asks, limit),
+	}
+	out.Checksum = orderBookChecksum(out.Bids, out.Asks)
+	return out, nil
+}
+
+func topLevels(levels []dto.PriceLevel, limit int) []dto.PriceLevel {
+	n := len(levels)
+	if limit > 0 {
+		n = min(n, limit)
+	}
+	return slices.Clone(levels[:n])
+}
+
+func orderBookChecksum(bids, asks []dto.PriceLevel) uint32 {
+	var parts []string
+	for i := 0; i < max(len(bids), len(asks)); i++ {
+		if i < len(bids) {
+			parts = append(parts, bids[i].Price.String(), bids[i].Quantity.String())
+		}
+		if i < len(asks) {
+			parts = append(parts, asks[i].Price.String(), asks[i].Quantity.String())
+		}
+	}
+	return crc32.ChecksumIEEE([]byte(strings.Join(parts, ":")))
+}
+
+// HandleStreamEvent applies depth diffs to tracked books. A book whose stream
+// disconnects is unsynced until a snapshot lines up with the diffs that follow.
+func (s *orderBookSvc) HandleStreamEvent(event dto.StreamEvent) {
+	if event.Type != dto.StreamEventDepthUpdate && event.Type != dto.StreamEventDisconnect {
+		return
+	}
+	val, ok := s.books.Load(event.Symbol)
+	if !ok {
+		return
+	}
+	book := val.(*localBook)
+
+	book.lock.Lock()
+	defer book.lock.Unlock()
+
+	if event.Type == dto.StreamEventDisconnect {
+		if strings.Contains(event.Stream, "@depth") && book.synced {
+			log.Printf("Order book %s stream disconnected, resyncing", book.symbol)
+			book.synced = false
+			book.buffer = book.buffer[:0]
+		}
+		return
+	}
+	update := event.DepthUpdate
+
+	if book.synced {
+		switch {
+		case update.FinalUpdateID <= book.lastUpdateID:
+			return
+		case update.FirstUpdateID > book.lastUpdateID+1:
+			log.Printf("Order book %s missed updates %d-%d, resyncing", book.symbol, book.lastUpdateID+1, update.FirstUpdateID-1)
+			book.synced = false
+			book.buffer = book.buffer[:0]
+		default:
+			book.apply(update)
+			return
+		}
+	}
+
+	if len(book.buffer) >= maxBufferedDepthUpdates {
+		book.buffer = book.buffer[:0]
+	}
+	book.buffer = append(book.buffer, *update)
+	if !book.resyncing {
+		book.resyncing = true
+		go s.resync(book)
+	}
+}
+
+// resync fetches snapshots until one lines up with the buffered diffs, or the
+// service is closed.
+func (s *orderBookSvc) resync(book *localBook) {
+	for {
+		snapshot, err := s.binanceSvc.GetDepthSnapshot(s.ctx, book.symbol, s.snapshotLimit)
+		if err == nil {
+			if err = book.syncFrom(snapshot); err == nil {
+				return
+			}
+			log.Printf("Order book %s: %v, refetching snapshot", book.symbol, err)
+		} else if s.ctx.Err() == nil {
+			log.Printf("Failed to fetch depth snapshot for %s: %v", book.symbol, err)
+		}
+		select {
+		case <-time.After(orderBookResyncBackoff):
+		case <-s.ctx.Done():
+			book.lock.Lock()
+			book.resyncing = false
+			book.lock.Unlock()
+			return
+		}
+	}
+}
+
+// syncFrom replaces the book with snapshot and replays the buffered diffs on top.
+func (b *localBook) syncFrom(snapshot *dto.DepthSnapshot) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if len(b.buffer) == 0 || snapshot.LastUpdateID < b.buffer[0].FirstUpdateID-1 {
+		return fmt.Errorf("snapshot %d is older than the buffered updates", snapshot.LastUpdateID)
+	}
+	pending := b.buffer[:0]
+	for _, update := range b.buffer {
+		if update.FinalUpdateID > snapshot.LastUpdateID {
+			pending = append(pending, update)
+		}
+	}
+	if len(pending) > 0 && pending[0].FirstUpdateID > snapshot.LastUpdateID+1 {
+		b.buffer = b.buffer[:0]
+		return fmt.Errorf("snapshot %d does not connect to update %d", snapshot.LastUpdateID, pending[0].FirstUpdateID)
+	}
+
+	b.bids = b.bids[:0]
+	b.asks = b.asks[:0]
+	b.setLevels(snapshot.Bids, true)
+	b.setLevels(snapshot.Asks, false)
+	b.trim()
+	b.lastUpdateID = snapshot.LastUpdateID
+	for i := range pending {
+		update := pending[i]
+		if update.FirstUpdateID > b.lastUpdateID+1 {
+			b.buffer = b.buffer[:0]
+			return fmt.Errorf("gap in buffered updates at %d", update.FirstUpdateID)
+		}
+		b.apply(&update)
+	}
+	b.buffer = b.buffer[:0]
+	b.synced = true
+	b.resyncing = false
+	return nil
+}
+
+func (b *localBook) apply(update *dto.DepthUpdate) {
+	b.setLevels(update.Bids, true)
+	b.setLevels(update.Asks, false)
+	b.trim()
+	b.lastUpdateID = update.FinalUpdateID
+	b.eventTime = update.EventTime
+}
+
+// setLevels upserts levels; a zero quantity removes the price level.
+func (b *localBook) setLevels(levels []dto.PriceLevel, bids bool) {
+	side := &b.asks
+	if bids {
+		side = &b.bids
+	}
+	for _, level := range levels {
+		i := sort.Search(len(*side), func(i int) bool {
+			if bids {
+				return (*side)[i].Price.Cmp(level.Price) <= 0
+			}
+			return (*side)[i].Price.Cmp(level.Price) >= 0
+		})
+		exists := i < len(*side) && (*side)[i].Price.Cmp(level.Price) == 0
+		switch {
+		case level.Quantity.Sign() == 0:
+			if exists {
+				*side = slices.Delete(*side, i, i+1)
+			}
+		case exists:
+			(*side)[i] = level
+		default:
+			*side = slices.Insert(*side, i, level)
+		}
+	}
+}
+
+// trim drops the levels beyond the book's depth; without further snapshots
+// they would go stale as the diff stream only covers the top of the book.
+func (b *localBook) trim() {
+	if b.depth <= 0 {
+		return
+	}
+	b.bids = b.bids[:min(len(b.bids), b.depth)]
+	b.asks = b.asks[:min(len(b.asks), b.depth)]
+}
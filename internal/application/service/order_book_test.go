@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ntdat104/go-finance-dataset/internal/application/dto"
+	"github.com/ntdat104/go-finance-dataset/pkg/decimal"
+)
+
+// fakeStreamSvc records the upstream streams instead of connecting to Binance.
+type fakeStreamSvc struct {
+	lock      sync.Mutex
+	streams   []string
+	listeners []func(dto.StreamEvent)
+}
+
+func (f *fakeStreamSvc) Subscribe(streams ...string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.streams = append(f.streams, streams...)
+	return nil
+}
+
+func (f *fakeStreamSvc) Unsubscribe(streams ...string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.streams = slices.DeleteFunc(f.streams, func(s string) bool { return slices.Contains(streams, s) })
+	return nil
+}
+
+func (f *fakeStreamSvc) Streams() []string {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return slices.Clone(f.streams)
+}
+
+func (f *fakeStreamSvc) AddListener(fn func(dto.StreamEvent)) {
+	f.listeners = append(f.listeners, fn)
+}
+
+func (f *fakeStreamSvc) Close() {}
+
+// emit delivers event to the listeners as the stream connection would.
+func (f *fakeStreamSvc) emit(event dto.StreamEvent) {
+	for _, fn := range f.listeners {
+		fn(event)
+	}
+}
+
+// snapshotSvc serves depth snapshots from a channel, blocking until one is sent.
+type snapshotSvc struct {
+	BinanceSvc
+	snapshots chan *dto.DepthSnapshot
+	returned  chan struct{}
+}
+
+func (s *snapshotSvc) GetDepthSnapshot(ctx context.Context, symbol string, limit int) (*dto.DepthSnapshot, error) {
+	select {
+	case snapshot := <-s.snapshots:
+		return snapshot, nil
+	case <-ctx.Done():
+		close(s.returned)
+		return nil, ctx.Err()
+	}
+}
+
+func levels(pairs ...string) []dto.PriceLevel {
+	out := make([]dto.PriceLevel, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		out = append(out, dto.PriceLevel{Price: decimal.MustParse(pairs[i]), Quantity: decimal.MustParse(pairs[i+1])})
+	}
+	return out
+}
+
+func depthEvent(first, final int64, bids, asks []dto.PriceLevel) dto.StreamEvent {
+	return dto.StreamEvent{
+		Stream: "btcusdt@depth@100ms",
+		Type:   dto.StreamEventDepthUpdate,
+		Symbol: "BTCUSDT",
+		DepthUpdate: &dto.DepthUpdate{
+			FirstUpdateID: first,
+			FinalUpdateID: final,
+			Bids:          bids,
+			Asks:          asks,
+		},
+	}
+}
+
+// waitSynced polls until the book is served.
+func waitSynced(t *testing.T, svc OrderBookSvc) *dto.OrderBook {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		book, err := svc.GetOrderBook("btcusdt", 0)
+		if err == nil {
+			return book
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("book never synced: %v", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func newTestOrderBookSvc(t *testing.T, snapshotLimit int) (OrderBookSvc, *snapshotSvc, *fakeStreamSvc) {
+	t.Helper()
+	depth := &snapshotSvc{snapshots: make(chan *dto.DepthSnapshot, 1), returned: make(chan struct{})}
+	streams := &fakeStreamSvc{}
+	svc := NewOrderBookSvc(depth, streams, snapshotLimit)
+	t.Cleanup(svc.Close)
+	streams.AddListener(svc.HandleStreamEvent)
+	if err := svc.Track("btcusdt"); err != nil {
+		t.Fatal(err)
+	}
+	return svc, depth, streams
+}
+
+func TestOrderBookSequencing(t *testing.T) {
+	svc, depth, streams := newTestOrderBookSvc(t, 1000)
+	if _, err := svc.GetOrderBook("BTCUSDT", 0); !errors.Is(err, ErrOrderBookNotSynced) {
+		t.Fatalf("error = %v before any snapshot, want ErrOrderBookNotSynced", err)
+	}
+
+	// Diffs 99-100 are covered by the snapshot; 101-102 are replayed on top of it.
+	streams.emit(depthEvent(99, 100, levels("100", "9"), nil))
+	streams.emit(depthEvent(101, 102, levels("100.5", "1"), levels("101", "2")))
+	depth.snapshots <- &dto.DepthSnapshot{LastUpdateID: 100, Bids: levels("100", "3", "99", "1"), Asks: levels("101", "5")}
+	book := waitSynced(t, svc)
+	if book.LastUpdateID != 102 {
+		t.Fatalf("LastUpdateID = %d, want 102", book.LastUpdateID)
+	}
+	if len(book.Bids) != 3 || book.Bids[0].Price.String() != "100.5" || book.Bids[1].Quantity.String() != "3" {
+		t.Fatalf("bids = %v, want 100.5 on top of the snapshot", book.Bids)
+	}
+	if len(book.Asks) != 1 || book.Asks[0].Quantity.String() != "2" {
+		t.Fatalf("asks = %v, want the replayed quantity", book.Asks)
+	}
+
+	// A zero quantity removes the level; stale diffs are ignored.
+	streams.emit(depthEvent(103, 103, levels("99", "0"), nil))
+	streams.emit(depthEvent(90, 101, levels("98", "1"), nil))
+	book = waitSynced(t, svc)
+	if book.LastUpdateID != 103 || len(book.Bids) != 2 {
+		t.Fatalf("book = %+v, want update 103 applied and the stale one ignored", book)
+	}
+
+	// A gap unsyncs the book until a new snapshot connects.
+	streams.emit(depthEvent(110, 111, nil, nil))
+	if _, err := svc.GetOrderBook("BTCUSDT", 0); !errors.Is(err, ErrOrderBookNotSynced) {
+		t.Fatalf("error = %v after a gap, want ErrOrderBookNotSynced", err)
+	}
+	depth.snapshots <- &dto.DepthSnapshot{LastUpdateID: 110, Bids: levels("100", "1"), Asks: levels("101", "1")}
+	if book = waitSynced(t, svc); book.LastUpdateID != 111 {
+		t.Fatalf("LastUpdateID = %d after resync, want 111", book.LastUpdateID)
+	}
+}
+
+func TestOrderBookLevels(t *testing.T) {
+	svc, depth, streams := newTestOrderBookSvc(t, 2)
+	streams.emit(depthEvent(1, 1, nil, nil))
+	depth.snapshots <- &dto.DepthSnapshot{LastUpdateID: 1, Bids: levels("100", "1", "99", "1", "98", "1")}
+	if book := waitSynced(t, svc); len(book.Bids) != 2 {
+		t.Fatalf("bids = %v, want the snapshot trimmed to 2 levels", book.Bids)
+	}
+
+	// Prices are matched exactly, whatever their scale or float64 rounding.
+	streams.emit(depthEvent(2, 2, levels("100.00", "5", "100.0000000000000000001", "2"), nil))
+	book := waitSynced(t, svc)
+	want := []string{"100.0000000000000000001:2", "100.00:5"}
+	if len(book.Bids) != len(want) {
+		t.Fatalf("bids = %v, want %v", book.Bids, want)
+	}
+	for i, level := range book.Bids {
+		if got := level.Price.String() + ":" + level.Quantity.String(); got != want[i] {
+			t.Errorf("bid %d = %s, want %s", i, got, want[i])
+		}
+	}
+	streams.emit(depthEvent(3, 3, levels("100", "0"), nil))
+	if book := waitSynced(t, svc); len(book.Bids) != 1 || book.Bids[0].Quantity.String() != "2" {
+		t.Errorf("bids = %v, want 100 removed", book.Bids)
+	}
+}
+
+func TestOrderBookUnsyncsOnDisconnect(t *testing.T) {
+	svc, depth, streams := newTestOrderBookSvc(t, 1000)
+	streams.emit(depthEvent(1, 1, nil, nil))
+	depth.snapshots <- &dto.DepthSnapshot{LastUpdateID: 1, Bids: levels("1", "1")}
+	waitSynced(t, svc)
+
+	// Another stream of the symbol dropping says nothing about the depth stream.
+	streams.emit(dto.StreamEvent{Stream: "btcusdt@trade", Type: dto.StreamEventDisconnect, Symbol: "BTCUSDT"})
+	waitSynced(t, svc)
+
+	streams.emit(dto.StreamEvent{Stream: "btcusdt@depth@100ms", Type: dto.StreamEventDisconnect, Symbol: "BTCUSDT"})
+	if _, err := svc.GetOrderBook("BTCUSDT", 0); !errors.Is(err, ErrOrderBookNotSynced) {
+		t.Fatalf("error = %v after a disconnect, want ErrOrderBookNotSynced", err)
+	}
+}
+
+func TestOrderBookCloseStopsResync(t *testing.T) {
+	svc, depth, streams := newTestOrderBookSvc(t, 1000)
+	streams.emit(depthEvent(1, 1, nil, nil))
+	svc.Close()
+	select {
+	case <-depth.returned:
+	case <-time.After(2 * time.Second):
+		t.Fatal("resync kept waiting for a snapshot after Close")
+	}
+}
+
+func TestOrderBookChecksum(t *testing.T) {
+	tests := []struct {
+		bids, asks []dto.PriceLevel
+		want       uint32
+	}{
+		{levels("100.5", "1"), levels("101", "2"), 3350140319},
+		// Bids and asks interleave from the top; the longer side runs on alone.
+		{levels("100.5", "1", "100", "3"), levels("101", "2"), 58950642},
+	}
+	for _, tt := range tests {
+		if got := orderBookChecksum(tt.bids, tt.asks); got != tt.want {
+			t.Errorf("orderBookChecksum(%v, %v) = %d, want %d", tt.bids, tt.asks, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,325 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ntdat104/go-finance-dataset/internal/application/dto"
+)
+
+// Push channels clients can subscribe to. Topics are "<channel>:<SYMBOL>", plus
+// ":<interval>" for klines, e.g. "ticker:BTCUSDT" or "klines:ETHUSDT:1m".
+const (
+	PushChannelTicker = "ticker"
+	PushChannelTrades = "trades"
+	PushChannelKlines = "klines"
+	PushChannelBook   = "book"
+)
+
+var (
+	ErrPushTooManyConnections   = errors.New("too many push connections")
+	ErrPushTooManySubscriptions = errors.New("subscription limit reached")
+	ErrPushTooManyStreams       = errors.New("upstream stream limit reached")
+	// ErrPushSlowConsumer is the reason a client is disconnected when it cannot keep up.
+	ErrPushSlowConsumer = errors.New("client is too slow to keep up with updates")
+)
+
+// PushSvc fans stream events out to subscribed push clients.
+type PushSvc interface {
+	Connect() (PushClient, error)
+	HandleStreamEvent(event dto.StreamEvent)
+}
+
+// PushClient is one connected client with its own bounded message queue.
+type PushClient interface {
+	Subscribe(topics ...string) error
+	Unsubscribe(topics ...string) error
+	Topics() []string
+	Messages() <-chan dto.PushMessage
+	// Done is closed when the client is closed or dropped; Err tells why.
+	Done() <-chan struct{}
+	Err() error
+	Close()
+}
+
+type pushSvc struct {
+	streamSvc        StreamSvc
+	maxConnections   int
+	maxSubscriptions int
+	maxStreams       int // upstream streams subscribed on demand, across all clients
+	bufferSize       int
+	lock             sync.RWMutex
+	clients          map[*pushClient]struct{}
+	topics           map[string]map[*pushClient]struct{}
+	// streamRefs counts subscribers of upstream streams this service subscribed on demand.
+	streamRefs map[string]int
+}
+
+type pushClient struct {
+	svc      *pushSvc
+	lock     sync.Mutex
+	topics   map[string]bool
+	messages chan dto.PushMessage
+	done     chan struct{}
+	dropped  int
+	err      error
+}
+
+func NewPushSvc(streamSvc StreamSvc, maxConnections, maxSubscriptions, maxStreams, bufferSize int) PushSvc {
+	return &pushSvc{
+		streamSvc:        streamSvc,
+		maxConnections:   maxConnections,
+		maxSubscriptions: maxSubscriptions,
+		maxStreams:       maxStreams,
+		bufferSize:       max(bufferSize, 1),
+		clients:          make(map[*pushClient]struct{}),
+		topics:           make(map[string]map[*pushClient]struct{}),
+		streamRefs:       make(map[string]int),
+	}
+}
+
+// Connect registers a new client.
+func (s *pushSvc) Connect() (PushClient, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.maxConnections > 0 && len(s.clients) >= s.maxConnections {
+		return nil, ErrPushTooManyConnections
+	}
+	c := &pushClient{
+		svc:      s,
+		topics:   make(map[string]bool),
+		messages: make(chan dto.PushMessage, s.bufferSize),
+		done:     make(chan struct{}),
+	}
+	s.clients[c] = struct{}{}
+	return c, nil
+}
+
+// parsePushTopic validates a topic and returns it normalised along with the upstream stream it needs.
+func parsePushTopic(topic string) (string, string, error) {
+	parts := strings.Split(topic, ":")
+	if len(parts) < 2 {
+		return "", "", fmt.Errorf("invalid topic %q", topic)
+	}
+	channel := parts[0]
+	symbol, err := normalizeSymbol(parts[1])
+	if err != nil {
+		return "", "", fmt.Errorf("invalid topic %q: %w", topic, err)
+	}
+	stream := strings.ToLower(symbol) + "@"
+	switch {
+	case (channel == PushChannelTicker || channel == PushChannelTrades) && len(parts) == 2:
+		stream += "trade"
+	case channel == PushChannelBook && len(parts) == 2:
+		stream += "bookTicker"
+	case channel == PushChannelKlines && len(parts) == 3:
+		if err := ValidateInterval(parts[2]); err != nil {
+			return "", "", err
+		}
+		stream += "kline_" + parts[2]
+		return channel + ":" + symbol + ":" + parts[2], stream, nil
+	default:
+		return "", "", fmt.Errorf("invalid topic %q", topic)
+	}
+	return channel + ":" + symbol, stream, nil
+}
+
+// HandleStreamEvent publishes the event to the clients subscribed to its topics.
+func (s *pushSvc) HandleStreamEvent(event dto.StreamEvent) {
+	switch event.Type {
+	case dto.StreamEventTrade:
+		s.publish(PushChannelTicker, event.Symbol, "", &dto.TickerPrice{Symbol: event.Symbol, Price: event.Trade.Price})
+		s.publish(PushChannelTrades, event.Symbol, "", event.Trade)
+	case dto.StreamEventBookTicker:
+		s.publish(PushChannelBook, event.Symbol, "", event.BookTicker)
+	case dto.StreamEventKline:
+		s.publish(PushChannelKlines, event.Symbol, event.Kline.Interval, event.Kline)
+	}
+}
+
+func (s *pushSvc) publish(channel, symbol, interval string, payload any) {
+	topic := channel + ":" + symbol
+	if interval != "" {
+		topic += ":" + interval
+	}
+	msg := dto.PushMessage{Topic: topic, Channel: channel, Symbol: symbol, Payload: payload}
+
+	s.lock.RLock()
+	var slow []*pushClient
+	for c := range s.topics[topic] {
+		if !c.offer(msg) {
+			slow = append(slow, c)
+		}
+	}
+	s.lock.RUnlock()
+
+	for _, c := range slow {
+		c.closeWithErr(ErrPushSlowConsumer)
+	}
+}
+
+// offer queues msg without blocking. It drops messages while the queue is full
+// and reports false once the client has fallen a whole queue behind.
+func (c *pushClient) offer(msg dto.PushMessage) bool {
+	select {
+	case c.messages <- msg:
+		c.lock.Lock()
+		c.dropped = 0
+		c.lock.Unlock()
+		return true
+	default:
+		c.lock.Lock()
+		defer c.lock.Unlock()
+		c.dropped++
+		return c.dropped < cap(c.messages)
+	}
+}
+
+func (c *pushClient) Subscribe(topics ...string) error {
+	s := c.svc
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	type parsed struct{ topic, stream string }
+	var added []parsed
+	for _, raw := range topics {
+		topic, stream, err := parsePushTopic(raw)
+		if err != nil {
+			return err
+		}
+		if !c.topics[topic] {
+			added = append(added, parsed{topic, stream})
+		}
+	}
+	if s.maxSubscriptions > 0 && len(c.topics)+len(added) > s.maxSubscriptions {
+		return ErrPushTooManySubscriptions
+	}
+
+	for _, p := range added {
+		if err := s.retainStreamLocked(p.stream); err != nil {
+			return err
+		}
+		c.topics[p.topic] = true
+		if s.topics[p.topic] == nil {
+			s.topics[p.topic] = make(map[*pushClient]struct{})
+		}
+		s.topics[p.topic][c] = struct{}{}
+	}
+	return nil
+}
+
+func (c *pushClient) Unsubscribe(topics ...string) error {
+	s := c.svc
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, raw := range topics {
+		topic, stream, err := parsePushTopic(raw)
+		if err != nil {
+			return err
+		}
+		if c.topics[topic] {
+			s.removeLocked(c, topic, stream)
+		}
+	}
+	return nil
+}
+
+// retainStreamLocked makes sure the upstream stream is subscribed. Streams that
+// were already subscribed, e.g. from config, are never counted and so never dropped.
+// At most maxStreams are subscribed on demand.
+func (s *pushSvc) retainStreamLocked(stream string) error {
+	if n, ok := s.streamRefs[stream]; ok {
+		s.streamRefs[stream] = n + 1
+		return nil
+	}
+	for _, existing := range s.streamSvc.Streams() {
+		if existing == stream {
+			return nil
+		}
+	}
+	if s.maxStreams > 0 && len(s.streamRefs) >= s.maxStreams {
+		return ErrPushTooManyStreams
+	}
+	if err := s.streamSvc.Subscribe(stream); err != nil {
+		return err
+	}
+	s.streamRefs[stream] = 1
+	return nil
+}
+
+func (s *pushSvc) releaseStreamLocked(stream string) {
+	n, ok := s.streamRefs[stream]
+	if !ok {
+		return
+	}
+	if n > 1 {
+		s.streamRefs[stream] = n - 1
+		return
+	}
+	delete(s.streamRefs, stream)
+	_ = s.streamSvc.Unsubscribe(stream)
+}
+
+func (s *pushSvc) removeLocked(c *pushClient, topic, stream string) {
+	delete(c.topics, topic)
+	delete(s.topics[topic], c)
+	if len(s.topics[topic]) == 0 {
+		delete(s.topics, topic)
+	}
+	s.releaseStreamLocked(stream)
+}
+
+func (c *pushClient) Topics() []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	out := make([]string, 0, len(c.topics))
+	for topic := range c.topics {
+		out = append(out, topic)
+	}
+	return out
+}
+
+func (c *pushClient) Messages() <-chan dto.PushMessage {
+	return c.messages
+}
+
+func (c *pushClient) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *pushClient) Err() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.err
+}
+
+func (c *pushClient) Close() {
+	c.closeWithErr(nil)
+}
+
+func (c *pushClient) closeWithErr(err error) {
+	s := c.svc
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.clients[c]; !ok {
+		return
+	}
+	delete(s.clients, c)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for topic := range c.topics {
+		_, stream, _ := parsePushTopic(topic)
+		s.removeLocked(c, topic, stream)
+	}
+	c.err = err
+	close(c.done)
+}
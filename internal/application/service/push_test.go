@@ -0,0 +1,119 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/ntdat104/go-finance-dataset/internal/application/dto"
+)
+
+func TestParsePushTopic(t *testing.T) {
+	tests := []struct {
+		topic  string
+		want   string
+		stream string
+		ok     bool
+	}{
+		{"ticker:btcusdt", "ticker:BTCUSDT", "btcusdt@trade", true},
+		{"trades:ETHUSDT", "trades:ETHUSDT", "ethusdt@trade", true},
+		{"book:BTCUSDT", "book:BTCUSDT", "btcusdt@bookTicker", true},
+		{"klines:BTCUSDT:1m", "klines:BTCUSDT:1m", "btcusdt@kline_1m", true},
+		{"klines:BTCUSDT:2m", "", "", false},
+		{"klines:BTCUSDT", "", "", false},
+		{"ticker:", "", "", false},
+		{"ticker:BTC/USDT", "", "", false},
+		{"ticker:btcusdt@depth", "", "", false},
+		{"ticker:BTC USDT", "", "", false},
+		{"depth:BTCUSDT", "", "", false},
+		{"ticker", "", "", false},
+	}
+	for _, tt := range tests {
+		topic, stream, err := parsePushTopic(tt.topic)
+		if (err == nil) != tt.ok {
+			t.Errorf("parsePushTopic(%q) error = %v, want ok %v", tt.topic, err, tt.ok)
+			continue
+		}
+		if topic != tt.want || stream != tt.stream {
+			t.Errorf("parsePushTopic(%q) = %q, %q; want %q, %q", tt.topic, topic, stream, tt.want, tt.stream)
+		}
+	}
+}
+
+func TestPushStreamsAreRefCounted(t *testing.T) {
+	streams := &fakeStreamSvc{streams: []string{"btcusdt@trade"}}
+	svc := NewPushSvc(streams, 0, 0, 0, 8)
+	a, _ := svc.Connect()
+	b, _ := svc.Connect()
+
+	if err := a.Subscribe("ticker:BTCUSDT", "book:ETHUSDT"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Subscribe("book:ETHUSDT"); err != nil {
+		t.Fatal(err)
+	}
+	if got := streams.Streams(); !slices.Equal(got, []string{"btcusdt@trade", "ethusdt@bookTicker"}) {
+		t.Fatalf("streams = %v", got)
+	}
+	a.Close()
+	if got := streams.Streams(); !slices.Contains(got, "ethusdt@bookTicker") {
+		t.Fatalf("stream dropped while b still subscribes: %v", got)
+	}
+	b.Close()
+	// The configured stream stays, the on-demand one goes.
+	if got := streams.Streams(); !slices.Equal(got, []string{"btcusdt@trade"}) {
+		t.Fatalf("streams = %v, want only the configured one", got)
+	}
+}
+
+func TestPushCapsOnDemandStreams(t *testing.T) {
+	streams := &fakeStreamSvc{}
+	svc := NewPushSvc(streams, 0, 0, 2, 8)
+	a, _ := svc.Connect()
+	b, _ := svc.Connect()
+
+	if err := a.Subscribe("ticker:AAAUSDT", "ticker:BBBUSDT"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Subscribe("ticker:CCCUSDT"); !errors.Is(err, ErrPushTooManyStreams) {
+		t.Fatalf("Subscribe error = %v, want ErrPushTooManyStreams", err)
+	}
+	// Topics sharing an open stream are still allowed.
+	if err := b.Subscribe("trades:AAAUSDT"); err != nil {
+		t.Fatalf("Subscribe to an open stream: %v", err)
+	}
+	a.Unsubscribe("ticker:BBBUSDT")
+	if err := b.Subscribe("ticker:CCCUSDT"); err != nil {
+		t.Fatalf("Subscribe after a stream was released: %v", err)
+	}
+}
+
+func TestPushDeliversAndDropsSlowClients(t *testing.T) {
+	streams := &fakeStreamSvc{}
+	svc := NewPushSvc(streams, 0, 0, 0, 2)
+	streams.AddListener(svc.HandleStreamEvent)
+	c, _ := svc.Connect()
+	if err := c.Subscribe("trades:BTCUSDT"); err != nil {
+		t.Fatal(err)
+	}
+
+	event := dto.StreamEvent{Type: dto.StreamEventTrade, Symbol: "BTCUSDT", Trade: &dto.Trade{ID: 1}}
+	streams.emit(event)
+	msg := <-c.Messages()
+	if msg.Topic != "trades:BTCUSDT" || msg.Payload.(*dto.Trade).ID != 1 {
+		t.Fatalf("message = %+v", msg)
+	}
+
+	// Two queued plus two dropped messages make the client a whole queue behind.
+	for range 4 {
+		streams.emit(event)
+	}
+	select {
+	case <-c.Done():
+	default:
+		t.Fatal("slow client was not dropped")
+	}
+	if !errors.Is(c.Err(), ErrPushSlowConsumer) {
+		t.Fatalf("Err = %v, want ErrPushSlowConsumer", c.Err())
+	}
+}
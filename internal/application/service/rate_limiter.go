@@ -0,0 +1,331 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ntdat104/go-finance-dataset/internal/application/dto"
+)
+
+const (
+	// defaultRetryAfter429 is used when a 429 arrives without a Retry-After header.
+	defaultRetryAfter429 = time.Minute
+	// defaultRetryAfter418 is used when an IP ban arrives without a Retry-After header.
+	defaultRetryAfter418 = 2 * time.Minute
+	// statusIPBanned is returned by Binance once an IP keeps sending requests after a 429.
+	statusIPBanned = 418
+)
+
+var (
+	usedWeightHeader     = regexp.MustCompile(`(?i)^X-Mbx-Used-Weight-(\d+)([smhd])$`)
+	sapiUsedWeightHeader = regexp.MustCompile(`(?i)^X-Sapi-Used-Ip-Weight-(\d+)([smhd])$`)
+)
+
+// RateLimitError is returned when a request is shed instead of risking a ban.
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("upstream request weight exhausted, retry after %s", e.RetryAfter.Round(time.Second))
+}
+
+// RateLimiterSvc keeps upstream requests within Binance's request weight limits.
+// The /sapi endpoints have their own IP weight limits, counted apart from the
+// spot API's.
+type RateLimiterSvc interface {
+	// Acquire reserves the weight of a request to path, waiting up to the
+	// configured max wait for budget and shedding the request otherwise.
+	Acquire(ctx context.Context, path string, params map[string]string) error
+	// Observe updates the budget from an upstream response.
+	Observe(resp *http.Response)
+	Budget() dto.RateLimitBudget
+}
+
+type weightWindow struct {
+	interval string
+	duration time.Duration
+	limit    int
+	used     int
+	start    time.Time
+}
+
+type rateLimiterSvc struct {
+	lock        sync.Mutex
+	windows     []*weightWindow
+	sapiWindows []*weightWindow
+	margin      float64
+	maxWait     time.Duration
+	bannedUntil time.Time
+	lastStatus  int
+	queued      int64
+	shed        int64
+}
+
+// NewRateLimiterSvc creates a limiter from the spot and /sapi weight limits
+// keyed by interval ("1m", "10s", ...). Only margin of each limit is used,
+// leaving headroom for requests made by anything else sharing our IP.
+func NewRateLimiterSvc(limits, sapiLimits map[string]int, margin float64, maxWait time.Duration) (RateLimiterSvc, error) {
+	if margin <= 0 || margin > 1 {
+		margin = 1
+	}
+	windows, err := newWeightWindows(limits)
+	if err != nil {
+		return nil, err
+	}
+	sapiWindows, err := newWeightWindows(sapiLimits)
+	if err != nil {
+		return nil, err
+	}
+	return &rateLimiterSvc{windows: windows, sapiWindows: sapiWindows, margin: margin, maxWait: maxWait}, nil
+}
+
+// newWeightWindows returns the windows of limits, shortest first.
+func newWeightWindows(limits map[string]int) ([]*weightWindow, error) {
+	var windows []*weightWindow
+	for interval, limit := range limits {
+		duration, err := parseLimitInterval(interval)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, &weightWindow{
+			interval: strings.ToLower(interval),
+			duration: duration,
+			limit:    limit,
+		})
+	}
+	slices.SortFunc(windows, func(a, b *weightWindow) int { return int(a.duration - b.duration) })
+	return windows, nil
+}
+
+// parseLimitInterval parses "<n><unit>" where unit is s, m, h or d.
+func parseLimitInterval(interval string) (time.Duration, error) {
+	interval = strings.ToLower(interval)
+	if len(interval) < 2 {
+		return 0, fmt.Errorf("invalid rate limit interval %q", interval)
+	}
+	n, err := strconv.Atoi(interval[:len(interval)-1])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid rate limit interval %q", interval)
+	}
+	units := map[byte]time.Duration{'s': time.Second, 'm': time.Minute, 'h': time.Hour, 'd': 24 * time.Hour}
+	unit, ok := units[interval[len(interval)-1]]
+	if !ok {
+		return 0, fmt.Errorf("invalid rate limit interval %q", interval)
+	}
+	return time.Duration(n) * unit, nil
+}
+
+// requestWeight returns the request weight Binance charges for a spot or futures endpoint.
+func requestWeight(path string, params map[string]string) int {
+	_, hasSymbol := params["symbol"]
+	switch {
+	case strings.HasSuffix(path, "/api/v3/exchangeInfo"):
+		return 20
+	case strings.HasSuffix(path, "/api/v3/ticker/price"), strings.HasSuffix(path, "/api/v3/ticker/bookTicker"):
+		if hasSymbol {
+			return 2
+		}
+		return 4
+	case strings.HasSuffix(path, "/api/v3/ticker/24hr"):
+		if hasSymbol {
+			return 2
+		}
+		return 80
+	case strings.HasSuffix(path, "/api/v3/depth"):
+		limit, _ := strconv.Atoi(params["limit"])
+		switch {
+		case limit <= 100:
+			return 5
+		case limit <= 500:
+			return 25
+		case limit <= 1000:
+			return 50
+		default:
+			return 250
+		}
+	case strings.HasSuffix(path, "/api/v3/trades"), strings.HasSuffix(path, "/api/v3/historicalTrades"):
+		return 25
+	case strings.HasSuffix(path, "/api/v3/account"), strings.HasSuffix(path, "/api/v3/myTrades"):
+		return 20
+	case strings.HasSuffix(path, "/api/v3/openOrders"):
+		if hasSymbol {
+			return 6
+		}
+		return 80
+	case strings.HasSuffix(path, "/api/v3/aggTrades"):
+		return 4
+	case strings.HasSuffix(path, "/sapi/v1/capital/withdraw/history"):
+		return 18000
+	case strings.HasSuffix(path, "/api/v3/klines"), strings.HasSuffix(path, "/api/v3/avgPrice"):
+		return 2
+	case strings.HasSuffix(path, "/v1/premiumIndex"):
+		if hasSymbol {
+			return 1
+		}
+		return 10
+	case strings.HasSuffix(path, "/v1/premiumIndexKlines"), strings.HasSuffix(path, "/v1/continuousKlines"):
+		limit, _ := strconv.Atoi(params["limit"])
+		switch {
+		case limit < 100:
+			return 1
+		case limit < 500:
+			return 2
+		case limit <= 1000:
+			return 5
+		default:
+			return 10
+		}
+	default:
+		return 1
+	}
+}
+
+// roll starts a new window once the current one has elapsed. Windows are
+// aligned to the clock like Binance's own counters.
+func (w *weightWindow) roll(now time.Time) {
+	if start := now.Truncate(w.duration); start.After(w.start) {
+		w.start = start
+		w.used = 0
+	}
+}
+
+// windowsFor returns the windows a request to path is counted in.
+func (s *rateLimiterSvc) windowsFor(path string) []*weightWindow {
+	if strings.Contains(path, "/sapi/") {
+		return s.sapiWindows
+	}
+	return s.windows
+}
+
+func (s *rateLimiterSvc) Acquire(ctx context.Context, path string, params map[string]string) error {
+	weight := requestWeight(path, params)
+	windows := s.windowsFor(path)
+	for {
+		s.lock.Lock()
+		now := time.Now()
+		var wait time.Duration
+		if now.Before(s.bannedUntil) {
+			wait = s.bannedUntil.Sub(now)
+		}
+		for _, w := range windows {
+			w.roll(now)
+			budget := int(float64(w.limit) * s.margin)
+			// A request heavier than the whole budget may still go out on an idle window.
+			if w.used+weight > budget && w.used > 0 {
+				wait = max(wait, w.start.Add(w.duration).Sub(now))
+			}
+		}
+		if wait == 0 {
+			for _, w := range windows {
+				w.used += weight
+			}
+			s.lock.Unlock()
+			return nil
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			s.shed++
+			s.lock.Unlock()
+			return &RateLimitError{RetryAfter: wait}
+		}
+		if wait > s.maxWait {
+			s.shed++
+			s.lock.Unlock()
+			return &RateLimitError{RetryAfter: wait}
+		}
+		s.queued++
+		s.lock.Unlock()
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *rateLimiterSvc) Observe(resp *http.Response) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := time.Now()
+	s.lastStatus = resp.StatusCode
+	for name, values := range resp.Header {
+		windows := s.windows
+		m := usedWeightHeader.FindStringSubmatch(name)
+		if m == nil {
+			windows = s.sapiWindows
+			m = sapiUsedWeightHeader.FindStringSubmatch(name)
+		}
+		if m == nil || len(values) == 0 {
+			continue
+		}
+		used, err := strconv.Atoi(values[0])
+		if err != nil {
+			continue
+		}
+		interval := strings.ToLower(m[1] + m[2])
+		for _, w := range windows {
+			if w.interval == interval {
+				w.roll(now)
+				// The header also counts requests we did not make ourselves.
+				w.used = max(w.used, used)
+			}
+		}
+	}
+
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != statusIPBanned {
+		return
+	}
+	retryAfter := defaultRetryAfter429
+	if resp.StatusCode == statusIPBanned {
+		retryAfter = defaultRetryAfter418
+	}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		retryAfter = time.Duration(seconds) * time.Second
+	}
+	if until := now.Add(retryAfter); until.After(s.bannedUntil) {
+		s.bannedUntil = until
+	}
+}
+
+func (s *rateLimiterSvc) Budget() dto.RateLimitBudget {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := time.Now()
+	budget := dto.RateLimitBudget{
+		Windows:     budgetWindows(s.windows, now),
+		SAPIWindows: budgetWindows(s.sapiWindows, now),
+		LastStatus:  s.lastStatus,
+		Queued:      s.queued,
+		Shed:        s.shed,
+	}
+	if now.Before(s.bannedUntil) {
+		budget.BannedUntil = s.bannedUntil.UnixMilli()
+	}
+	return budget
+}
+
+func budgetWindows(windows []*weightWindow, now time.Time) []dto.WeightWindow {
+	out := make([]dto.WeightWindow, 0, len(windows))
+	for _, w := range windows {
+		w.roll(now)
+		out = append(out, dto.WeightWindow{
+			Interval:  w.interval,
+			Limit:     w.limit,
+			Used:      w.used,
+			Remaining: max(w.limit-w.used, 0),
+			ResetsAt:  w.start.Add(w.duration).UnixMilli(),
+		})
+	}
+	return out
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRequestWeight(t *testing.T) {
+	symbol := map[string]string{"symbol": "BTCUSDT"}
+	tests := []struct {
+		path   string
+		params map[string]string
+		want   int
+	}{
+		{"/api/v3/exchangeInfo", nil, 20},
+		{"/api/v3/ticker/price", symbol, 2},
+		{"/api/v3/ticker/price", nil, 4},
+		{"/api/v3/ticker/24hr", symbol, 2},
+		{"/api/v3/ticker/24hr", nil, 80},
+		{"/api/v3/depth", map[string]string{"limit": "100"}, 5},
+		{"/api/v3/depth", map[string]string{"limit": "101"}, 25},
+		{"/api/v3/depth", map[string]string{"limit": "1000"}, 50},
+		{"/api/v3/depth", map[string]string{"limit": "5000"}, 250},
+		{"/api/v3/depth", nil, 5},
+		{"/api/v3/historicalTrades", symbol, 25},
+		{"/api/v3/klines", symbol, 2},
+		{"/api/v3/account", nil, 20},
+		{"/api/v3/myTrades", symbol, 20},
+		{"/api/v3/openOrders", symbol, 6},
+		{"/api/v3/openOrders", nil, 80},
+		{"/sapi/v1/capital/deposit/hisrec", nil, 1},
+		{"/sapi/v1/capital/withdraw/history", nil, 18000},
+		{"/api/v3/ping", nil, 1},
+	}
+	for _, tt := range tests {
+		if got := requestWeight(tt.path, tt.params); got != tt.want {
+			t.Errorf("requestWeight(%s, %v) = %d, want %d", tt.path, tt.params, got, tt.want)
+		}
+	}
+}
+
+func TestParseLimitInterval(t *testing.T) {
+	tests := map[string]time.Duration{"10s": 10 * time.Second, "1M": time.Minute, "1h": time.Hour, "1d": 24 * time.Hour}
+	for interval, want := range tests {
+		if got, err := parseLimitInterval(interval); err != nil || got != want {
+			t.Errorf("parseLimitInterval(%q) = %v, %v; want %v", interval, got, err, want)
+		}
+	}
+	for _, interval := range []string{"", "m", "0m", "-1m", "1w", "xm"} {
+		if _, err := parseLimitInterval(interval); err == nil {
+			t.Errorf("parseLimitInterval(%q) = nil error", interval)
+		}
+	}
+}
+
+func TestWeightWindowRoll(t *testing.T) {
+	base := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	w := &weightWindow{duration: time.Minute}
+	w.roll(base.Add(10 * time.Second))
+	if !w.start.Equal(base) {
+		t.Fatalf("start = %v, want the minute boundary %v", w.start, base)
+	}
+	w.used = 100
+	w.roll(base.Add(59 * time.Second))
+	if w.used != 100 {
+		t.Errorf("used = %d within the window, want 100", w.used)
+	}
+	w.roll(base.Add(time.Minute))
+	if w.used != 0 || !w.start.Equal(base.Add(time.Minute)) {
+		t.Errorf("after the boundary used = %d start = %v, want a fresh window", w.used, w.start)
+	}
+}
+
+func TestRateLimiterAcquire(t *testing.T) {
+	limiter, err := NewRateLimiterSvc(map[string]int{"1h": 100}, map[string]int{"1h": 10}, 0.5, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	// The budget is 50: two exchangeInfo calls fit, the third is shed.
+	for range 2 {
+		if err := limiter.Acquire(ctx, "/api/v3/exchangeInfo", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var rateErr *RateLimitError
+	if err := limiter.Acquire(ctx, "/api/v3/exchangeInfo", nil); !errors.As(err, &rateErr) || rateErr.RetryAfter <= 0 {
+		t.Fatalf("third Acquire = %v, want a RateLimitError", err)
+	}
+	// /sapi requests only count against their own budget of 5.
+	for range 5 {
+		if err := limiter.Acquire(ctx, "/sapi/v1/capital/deposit/hisrec", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := limiter.Acquire(ctx, "/sapi/v1/capital/deposit/hisrec", nil); !errors.As(err, &rateErr) {
+		t.Fatalf("Acquire past the /sapi budget = %v, want a RateLimitError", err)
+	}
+	budget := limiter.Budget()
+	if budget.Windows[0].Used != 40 || budget.Windows[0].Remaining != 60 || budget.Shed != 2 {
+		t.Errorf("budget = %+v", budget)
+	}
+	if budget.SAPIWindows[0].Used != 5 {
+		t.Errorf("sapi windows = %+v", budget.SAPIWindows)
+	}
+}
+
+func TestRateLimiterObserve(t *testing.T) {
+	limiter, err := NewRateLimiterSvc(map[string]int{"1m": 1200, "1d": 100000}, map[string]int{"1m": 12000}, 1, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := http.Header{}
+	header.Set("X-Mbx-Used-Weight-1m", "1150")
+	header.Set("X-Sapi-Used-Ip-Weight-1m", "300")
+	header.Set("Retry-After", "30")
+	limiter.Observe(&http.Response{StatusCode: http.StatusTooManyRequests, Header: header})
+
+	budget := limiter.Budget()
+	if budget.Windows[0].Interval != "1m" || budget.Windows[0].Used != 1150 || budget.Windows[1].Used != 0 {
+		t.Errorf("windows = %+v", budget.Windows)
+	}
+	if budget.SAPIWindows[0].Used != 300 {
+		t.Errorf("sapi windows = %+v", budget.SAPIWindows)
+	}
+	if budget.LastStatus != http.StatusTooManyRequests {
+		t.Errorf("LastStatus = %d", budget.LastStatus)
+	}
+	if until := time.UnixMilli(budget.BannedUntil); time.Until(until) < 29*time.Second {
+		t.Errorf("BannedUntil = %v, want about 30s ahead", until)
+	}
+	// The ban is longer than maxWait, so requests are shed rather than queued.
+	var rateErr *RateLimitError
+	if err := limiter.Acquire(context.Background(), "/api/v3/ping", nil); !errors.As(err, &rateErr) {
+		t.Errorf("Acquire during a ban = %v, want a RateLimitError", err)
+	}
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"sync"
+
+	"github.com/ntdat104/go-finance-dataset/internal/application/dto"
+)
+
+// refreshPool runs background cache refreshes on a fixed number of workers.
+// A key is refreshed by at most one job at a time, and a slow refresh only
+// occupies its own worker, so it never delays refreshes of other keys.
+type refreshPool struct {
+	workers   int
+	jobs      chan refreshJob
+	lock      sync.Mutex
+	pending   map[string]struct{}
+	completed int64
+	failed    int64
+	dropped   int64
+}
+
+type refreshJob struct {
+	key string
+	run func() error
+}
+
+func newRefreshPool(workers, queueSize int) *refreshPool {
+	workers = max(workers, 1)
+	p := &refreshPool{
+		workers: workers,
+		jobs:    make(chan refreshJob, max(queueSize, 0)),
+		pending: make(map[string]struct{}),
+	}
+	for range workers {
+		go p.work()
+	}
+	return p
+}
+
+// Submit queues a refresh of key. It reports false when the key is already
+// queued or running, or when the queue is full; the refresh is then dropped
+// and a later cache hit will try again.
+func (p *refreshPool) Submit(key string, run func() error) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if _, ok := p.pending[key]; ok {
+		return false
+	}
+	select {
+	case p.jobs <- refreshJob{key: key, run: run}:
+		p.pending[key] = struct{}{}
+		return true
+	default:
+		p.dropped++
+		return false
+	}
+}
+
+func (p *refreshPool) work() {
+	for job := range p.jobs {
+		err := job.run()
+		p.lock.Lock()
+		delete(p.pending, job.key)
+		if err != nil {
+			p.failed++
+		} else {
+			p.completed++
+		}
+		p.lock.Unlock()
+	}
+}
+
+func (p *refreshPool) Stats() dto.RefreshStats {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return dto.RefreshStats{
+		Workers:   p.workers,
+		Pending:   len(p.pending),
+		Completed: p.completed,
+		Failed:    p.failed,
+		Dropped:   p.dropped,
+	}
+}
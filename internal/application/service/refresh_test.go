@@ -0,0 +1,79 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRefreshPoolDedupsKeys(t *testing.T) {
+	p := newRefreshPool(1, 4)
+	release := make(chan struct{})
+	ran := make(chan string, 4)
+	job := func(key string, err error) func() error {
+		return func() error {
+			<-release
+			ran <- key
+			return err
+		}
+	}
+
+	if !p.Submit("a", job("a", nil)) {
+		t.Fatal("first Submit of a was refused")
+	}
+	if p.Submit("a", job("a", nil)) {
+		t.Error("second Submit of a was queued while the first is pending")
+	}
+	if !p.Submit("b", job("b", errors.New("boom"))) {
+		t.Error("Submit of b was refused")
+	}
+	if stats := p.Stats(); stats.Pending != 2 || stats.Dropped != 0 {
+		t.Errorf("stats = %+v, want 2 pending", stats)
+	}
+
+	close(release)
+	for range 2 {
+		<-ran
+	}
+	waitFor(t, func() bool { return p.Stats().Pending == 0 })
+	if stats := p.Stats(); stats.Completed != 1 || stats.Failed != 1 {
+		t.Errorf("stats = %+v, want 1 completed and 1 failed", stats)
+	}
+	// Once done, the key can be refreshed again.
+	if !p.Submit("a", job("a", nil)) {
+		t.Error("Submit of a was refused after it finished")
+	}
+	<-ran
+}
+
+func TestRefreshPoolDropsWhenFull(t *testing.T) {
+	p := newRefreshPool(1, 1)
+	release := make(chan struct{})
+	defer close(release)
+	block := func() error { <-release; return nil }
+
+	p.Submit("running", block)
+	// The worker takes the first job off the queue, leaving room for one more.
+	waitFor(t, func() bool { return len(p.jobs) == 0 })
+	if !p.Submit("queued", block) {
+		t.Fatal("Submit into an empty queue was refused")
+	}
+	if p.Submit("dropped", block) {
+		t.Error("Submit into a full queue was accepted")
+	}
+	if stats := p.Stats(); stats.Dropped != 1 || stats.Pending != 2 {
+		t.Errorf("stats = %+v, want 1 dropped and 2 pending", stats)
+	}
+}
+
+// waitFor polls cond until it holds, failing the test after a second.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within a second")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/ntdat104/go-finance-dataset/pkg/config"
+	"github.com/ntdat104/go-finance-dataset/pkg/datasource"
+	"github.com/ntdat104/go-finance-dataset/pkg/httpclient"
+)
+
+// restClient fetches and caches the REST data of one upstream API.
+// Every exchange service embeds one, so they share the fetch, cache and
+// refresh plumbing and only differ in URLs and parsers.
+type restClient struct {
+	namespace      string // cache key prefix, e.g. "spot"
+	localCacheSvc  LocalCacheSvc
+	rateLimiterSvc RateLimiterSvc // nil when the upstream has no weight budget to track
+	httpClient     httpclient.Client
+	flights        *flightGroup
+	policies       cachePolicies
+	refreshPool    *refreshPool
+	auth           *binanceAuth // nil when no endpoint needs an API key
+	hosts          *hostPool    // nil when the upstream has a single base URL
+}
+
+func newRestClient(namespace string, rateLimiterSvc RateLimiterSvc, httpClient httpclient.Client, localCacheSvc LocalCacheSvc, cacheCfg config.Cache) *restClient {
+	return &restClient{
+		namespace:      namespace,
+		localCacheSvc:  localCacheSvc,
+		rateLimiterSvc: rateLimiterSvc,
+		httpClient:     httpClient,
+		flights:        newFlightGroup(),
+		refreshPool:    newRefreshPool(cacheCfg.RefreshWorkers, cacheCfg.RefreshQueueSize),
+		policies:       newCachePolicies(cacheCfg),
+	}
+}
+
+// APIError is an error response of an upstream API, e.g. {"code":-1121,"msg":"Invalid symbol."}.
+// Responses without such a body only carry the status, with Code left zero.
+type APIError struct {
+	StatusCode int    `json:"-"`
+	Code       int    `json:"code"`
+	Msg        string `json:"msg"`
+}
+
+func (e *APIError) Error() string {
+	if e.Code == 0 {
+		return "upstream error: " + e.Msg
+	}
+	return fmt.Sprintf("upstream error %d: %s", e.Code, e.Msg)
+}
+
+// fetchData makes an HTTP GET request to the given API URL with parameters and returns the raw body.
+// The request is bound to ctx, so it is abandoned once the caller goes away.
+// With several hosts, a request failing because of its host is sent to the next
+// healthy one, and the failing host is skipped until its cooldown passes.
+func (c *restClient) fetchData(ctx context.Context, apiURL string, params map[string]string) ([]byte, error) {
+	if c.hosts == nil {
+		return c.fetchFrom(ctx, apiURL, params)
+	}
+	path, ok := c.hosts.split(apiURL)
+	if !ok {
+		return c.fetchFrom(ctx, apiURL, params)
+	}
+	var lastErr error
+	for _, host := range c.hosts.candidates() {
+		body, err := c.fetchFrom(ctx, host+path, params)
+		if err == nil {
+			c.hosts.markUp(host)
+			return body, nil
+		}
+		if !isHostFailure(ctx, err) {
+			return nil, err
+		}
+		log.Printf("%s host %s is unhealthy, failing over: %v", c.namespace, host, err)
+		c.hosts.markDown(host)
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// fetchFrom fetches apiURL from its own host. Requests to endpoints needing an
+// API key are authenticated when the client has auth; a signed request rejected
+// for its timestamp is retried once after resyncing the server time.
+func (c *restClient) fetchFrom(ctx context.Context, apiURL string, params map[string]string) ([]byte, error) {
+	u, err := url.Parse(apiURL)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing URL: %w", err)
+	}
+	q := u.Query()
+	for key, value := range params {
+		q.Set(key, value)
+	}
+	security := securityNone
+	if c.auth != nil {
+		security = binanceSecurity(u.Path)
+	}
+
+	body, err := c.fetchOnce(ctx, u, q, params, security)
+	var apiErr *APIError
+	if security == securitySigned && errors.As(err, &apiErr) && apiErr.Code == codeInvalidTimestamp {
+		c.auth.clock.reset()
+		body, err = c.fetchOnce(ctx, u, q, params, security)
+	}
+	return body, err
+}
+
+func (c *restClient) fetchOnce(ctx context.Context, u *url.URL, q url.Values, params map[string]string, security endpointSecurity) ([]byte, error) {
+	u.RawQuery = q.Encode()
+	// Error messages name the URL without the signature.
+	target := u.String()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request for %s: %w", target, err)
+	}
+	if c.rateLimiterSvc != nil {
+		if err := c.rateLimiterSvc.Acquire(ctx, u.Path, params); err != nil {
+			return nil, err
+		}
+	}
+	// Signing comes last so waiting for weight budget does not age the timestamp.
+	if security != securityNone {
+		if req.URL.RawQuery, err = c.auth.authenticate(ctx, req, q, security); err != nil {
+			return nil, err
+		}
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching data from %s: %w", target, err)
+	}
+	defer resp.Body.Close()
+	if c.rateLimiterSvc != nil {
+		c.rateLimiterSvc.Observe(resp)
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == statusIPBanned {
+		retryAfter := c.retryAfter(resp)
+		return nil, fmt.Errorf("received status code %d from %s: %w", resp.StatusCode, target, &RateLimitError{RetryAfter: retryAfter})
+	}
+	if resp.StatusCode != http.StatusOK {
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		if body, err := io.ReadAll(io.LimitReader(resp.Body, 4096)); err == nil && json.Unmarshal(body, apiErr) == nil && apiErr.Msg != "" {
+			return nil, fmt.Errorf("received non-OK status code %d from %s: %w", resp.StatusCode, target, apiErr)
+		}
+		return nil, fmt.Errorf("received non-OK status code %d from %s: %w", resp.StatusCode, target, &APIError{StatusCode: resp.StatusCode, Msg: resp.Status})
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response from %s: %w", target, err)
+	}
+	return body, nil
+}
+
+// retryAfter returns how long to back off after a 429 or 418 response.
+func (c *restClient) retryAfter(resp *http.Response) time.Duration {
+	if c.rateLimiterSvc != nil {
+		return time.Until(time.UnixMilli(c.rateLimiterSvc.Budget().BannedUntil))
+	}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	return time.Minute
+}
+
+// fetchTyped fetches data from the API and decodes it with parse.
+func fetchTyped[T any](ctx context.Context, c *restClient, apiURL string, params map[string]string, parse func([]byte) (T, error)) (T, error) {
+	var zero T
+	body, err := c.fetchData(ctx, apiURL, params)
+	if err != nil {
+		return zero, err
+	}
+	data, err := parse(body)
+	if err != nil {
+		return zero, fmt.Errorf("error decoding response from %s: %w", apiURL, err)
+	}
+	return data, nil
+}
+
+// cacheKeys returns the data key and the refresh delay key of a cache entry.
+func (c *restClient) cacheKeys(cacheName, keySuffix string) (string, string) {
+	key := fmt.Sprintf("%s_%s:%s", c.namespace, cacheName, keySuffix)
+	return key, key + ":delay"
+}
+
+// cachedValue is what getWithCache stores: the data and when it was fetched.
+type cachedValue struct {
+	data      any
+	fetchedAt time.Time
+}
+
+// setCached stores data under key according to policy and defers its next refresh.
+func (c *restClient) setCached(key, delayKey string, data any, policy cachePolicy) cachedValue {
+	entry := cachedValue{data: data, fetchedAt: time.Now()}
+	c.localCacheSvc.Set(key, entry, policy.retention())
+	c.localCacheSvc.Set(delayKey, true, policy.refreshInterval)
+	return entry
+}
+
+// fetchAndCache fetches data from the API and stores it in the local cache.
+func fetchAndCache[T any](ctx context.Context, c *restClient, key, delayKey, apiURL string, params map[string]string, policy cachePolicy, parse func([]byte) (T, error)) (cachedValue, error) {
+	data, err := fetchTyped(ctx, c, apiURL, params, parse)
+	if err != nil {
+		return cachedValue{}, err
+	}
+	return c.setCached(key, delayKey, data, policy), nil
+}
+
+// refreshCache queues a background refresh of a cached key if the refresh interval has passed.
+func refreshCache[T any](ctx context.Context, c *restClient, key, delayKey, apiURL string, params map[string]string, policy cachePolicy, parse func([]byte) (T, error)) {
+	if _, delayExists := c.localCacheSvc.Get(delayKey); delayExists {
+		return
+	}
+	queued := c.refreshPool.Submit(key, func() error {
+		data, err := fetchTyped(ctx, c, apiURL, params, parse)
+		if err != nil {
+			log.Printf("Failed to refresh %s cache for %s: %v", c.namespace, key, err)
+			c.localCacheSvc.Del(delayKey)
+			return err
+		}
+		c.setCached(key, delayKey, data, policy)
+		return nil
+	})
+	if queued {
+		c.localCacheSvc.Set(delayKey, true, policy.refreshInterval)
+	}
+}
+
+// decodeCached returns the data of a cache entry as T. Entries read from a
+// shared backend hold raw JSON, which is decoded once and kept in memory.
+func decodeCached[T any](c *restClient, key string, entry cachedValue) (T, bool) {
+	if data, ok := entry.data.(T); ok {
+		return data, true
+	}
+	var data T
+	raw, ok := entry.data.(json.RawMessage)
+	if !ok {
+		return data, false
+	}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		log.Printf("Failed to decode %s cache for %s: %v", c.namespace, key, err)
+		return data, false
+	}
+	c.localCacheSvc.Hydrate(key, cachedValue{data: data, fetchedAt: entry.fetchedAt})
+	return data, true
+}
+
+// getWithCache retrieves data from cache or fetches it from the API, caching the result.
+// The cache policy is looked up by cacheName and, for klines, the interval param.
+// Entries past their ttl are refetched, but still served if the fetch fails and
+// the policy allows serving stale data for at most maxStaleness past the ttl.
+// Where the data came from is recorded in the datasource.Info of ctx.
+func getWithCache[T any](ctx context.Context, c *restClient, cacheName, keySuffix, apiURL string, params map[string]string, parse func([]byte) (T, error)) (T, error) {
+	key, delayKey := c.cacheKeys(cacheName, keySuffix)
+	policy := c.policies.get(cacheName, params["interval"])
+
+	var stale T
+	var staleAt time.Time
+	hasStale := false
+	if cachedData, found := c.localCacheSvc.Get(key); found {
+		if entry, ok := cachedData.(cachedValue); ok {
+			if data, ok := decodeCached[T](c, key, entry); ok {
+				age := time.Since(entry.fetchedAt)
+				if age < policy.ttl {
+					refreshCache(context.WithoutCancel(ctx), c, key, delayKey, apiURL, params, policy, parse)
+					datasource.Record(ctx, datasource.Cache, entry.fetchedAt)
+					return data, nil
+				}
+				stale, staleAt = data, entry.fetchedAt
+				hasStale = policy.serveStaleOnError && age < policy.ttl+policy.maxStaleness
+			}
+		}
+	}
+
+	// Concurrent misses for the same key share a single upstream fetch.
+	fetched, err := c.flights.Do(ctx, key, func(ctx context.Context) (any, error) {
+		return fetchAndCache(ctx, c, key, delayKey, apiURL, params, policy, parse)
+	})
+	if err != nil {
+		if hasStale {
+			log.Printf("Serving stale %s cache for %s: %v", c.namespace, key, err)
+			datasource.Record(ctx, datasource.Stale, staleAt)
+			return stale, nil
+		}
+		var zero T
+		return zero, err
+	}
+	entry := fetched.(cachedValue)
+	datasource.Record(ctx, datasource.Upstream, entry.fetchedAt)
+	return entry.data.(T), nil
+}
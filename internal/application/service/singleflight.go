@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ntdat104/go-finance-dataset/internal/application/dto"
+)
+
+// flightGroup collapses concurrent calls for the same key into one execution
+// whose result or error is shared by every caller.
+type flightGroup struct {
+	lock      sync.Mutex
+	calls     map[string]*flightCall
+	requests  int64
+	executed  int64
+	collapsed int64
+}
+
+type flightCall struct {
+	done  chan struct{}
+	value any
+	err   error
+}
+
+func newFlightGroup() *flightGroup {
+	return &flightGroup{calls: make(map[string]*flightCall)}
+}
+
+// Do runs fn once per key at a time. fn gets a context detached from the
+// caller's cancellation, so one caller giving up does not fail the others;
+// each caller still stops waiting when its own ctx is done.
+func (g *flightGroup) Do(ctx context.Context, key string, fn func(context.Context) (any, error)) (any, error) {
+	g.lock.Lock()
+	g.requests++
+	call, ok := g.calls[key]
+	if ok {
+		g.collapsed++
+	} else {
+		g.executed++
+		call = &flightCall{done: make(chan struct{})}
+		g.calls[key] = call
+		go func() {
+			call.value, call.err = fn(context.WithoutCancel(ctx))
+			g.lock.Lock()
+			delete(g.calls, key)
+			g.lock.Unlock()
+			close(call.done)
+		}()
+	}
+	g.lock.Unlock()
+
+	select {
+	case <-call.done:
+		return call.value, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (g *flightGroup) Stats() dto.SingleflightStats {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	return dto.SingleflightStats{
+		Requests:  g.requests,
+		Executed:  g.executed,
+		Collapsed: g.collapsed,
+		InFlight:  len(g.calls),
+	}
+}
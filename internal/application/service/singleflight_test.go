@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFlightGroupCollapses(t *testing.T) {
+	g := newFlightGroup()
+	release := make(chan struct{})
+	var calls atomic.Int32
+	fn := func(context.Context) (any, error) {
+		calls.Add(1)
+		<-release
+		return "value", nil
+	}
+
+	const callers = 10
+	var wg sync.WaitGroup
+	results := make([]any, callers)
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = g.Do(context.Background(), "key", fn)
+		}()
+	}
+	// Wait until every caller has joined the flight before letting it land.
+	for g.Stats().Requests < callers {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("fn ran %d times, want 1", n)
+	}
+	for i, v := range results {
+		if v != "value" {
+			t.Errorf("caller %d got %v", i, v)
+		}
+	}
+	stats := g.Stats()
+	if stats.Executed != 1 || stats.Collapsed != callers-1 || stats.InFlight != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestFlightGroupSharesErrorsAndForgetsKeys(t *testing.T) {
+	g := newFlightGroup()
+	boom := errors.New("boom")
+	if _, err := g.Do(context.Background(), "key", func(context.Context) (any, error) { return nil, boom }); !errors.Is(err, boom) {
+		t.Fatalf("err = %v, want boom", err)
+	}
+	// A finished flight is not cached: the next call runs fn again.
+	v, err := g.Do(context.Background(), "key", func(context.Context) (any, error) { return 2, nil })
+	if err != nil || v != 2 {
+		t.Errorf("second Do = %v, %v", v, err)
+	}
+}
+
+func TestFlightGroupCallerCancellation(t *testing.T) {
+	g := newFlightGroup()
+	release := make(chan struct{})
+	fnErr := make(chan error, 1)
+	fn := func(ctx context.Context) (any, error) {
+		<-release
+		fnErr <- ctx.Err()
+		return "value", nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := g.Do(ctx, "key", fn)
+		done <- err
+	}()
+	for g.Stats().InFlight == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled caller got %v", err)
+	}
+
+	// The flight keeps going for callers still waiting on it.
+	waiting := make(chan any, 1)
+	go func() {
+		v, _ := g.Do(context.Background(), "key", fn)
+		waiting <- v
+	}()
+	for g.Stats().Collapsed == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	if v := <-waiting; v != "value" {
+		t.Errorf("waiting caller got %v", v)
+	}
+	if err := <-fnErr; err != nil {
+		t.Errorf("fn saw its context end: %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+package interfaces
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ntdat104/go-finance-dataset/internal/application/constants"
+	"github.com/ntdat104/go-finance-dataset/internal/application/response"
+	"github.com/ntdat104/go-finance-dataset/internal/application/service"
+	"github.com/ntdat104/go-finance-dataset/pkg/middleware"
+)
+
+type AccountHandler interface {
+	Account(ctx *gin.Context)
+	Balances(ctx *gin.Context)
+	OpenOrders(ctx *gin.Context)
+	MyTrades(ctx *gin.Context)
+	Deposits(ctx *gin.Context)
+	Withdrawals(ctx *gin.Context)
+}
+
+type accountHandler struct {
+	router     *gin.Engine
+	adminToken string
+	binanceSvc service.BinanceSvc
+}
+
+// NewAccountHandler serves the Binance account data of the configured API keys.
+// It is private, so every route needs the admin token.
+func NewAccountHandler(router *gin.Engine, adminToken string, binanceSvc service.BinanceSvc) AccountHandler {
+	h := &accountHandler{
+		router:     router,
+		adminToken: adminToken,
+		binanceSvc: binanceSvc,
+	}
+	h.initRoutes()
+	return h
+}
+
+func (h *accountHandler) initRoutes() {
+	admin := h.router.Group("", middleware.AdminAuthMiddleware(h.adminToken))
+	admin.GET(constants.ApiAccount, h.Account)
+	admin.GET(constants.ApiAccountBalances, h.Balances)
+	admin.GET(constants.ApiAccountOpenOrders, h.OpenOrders)
+	admin.GET(constants.ApiAccountMyTrades, h.MyTrades)
+	admin.GET(constants.ApiAccountDeposits, h.Deposits)
+	admin.GET(constants.ApiAccountWithdrawals, h.Withdrawals)
+}
+
+// Account returns the spot account of the API key named by the key query
+// parameter, or of the default key.
+func (h *accountHandler) Account(ctx *gin.Context) {
+	resp, err := h.binanceSvc.GetAccount(ctx.Request.Context(), ctx.Query("key"))
+	if err != nil {
+		upstreamError(ctx, err)
+		return
+	}
+	response.Success(ctx, resp)
+}
+
+// Balances returns the non-zero balances of the account.
+func (h *accountHandler) Balances(ctx *gin.Context) {
+	resp, err := h.binanceSvc.GetBalances(ctx.Request.Context(), ctx.Query("key"))
+	if err != nil {
+		upstreamError(ctx, err)
+		return
+	}
+	response.Success(ctx, resp)
+}
+
+// OpenOrders returns the open orders of the account, on symbol when given.
+func (h *accountHandler) OpenOrders(ctx *gin.Context) {
+	resp, err := h.binanceSvc.GetOpenOrders(ctx.Request.Context(), ctx.Query("key"), ctx.Query("symbol"))
+	if err != nil {
+		upstreamError(ctx, err)
+		return
+	}
+	response.Success(ctx, resp)
+}
+
+// MyTrades returns the fills of the account on symbol, optionally from fromId
+// or between startTime and endTime in milliseconds.
+func (h *accountHandler) MyTrades(ctx *gin.Context) {
+	symbol, ok := requireSymbol(ctx)
+	if !ok {
+		return
+	}
+	limit, ok := queryLimit(ctx, "500")
+	if !ok {
+		return
+	}
+	fromId, ok := queryTime(ctx, "fromId")
+	if !ok {
+		return
+	}
+	startTime, endTime, ok := queryTimeRange(ctx)
+	if !ok {
+		return
+	}
+	resp, err := h.binanceSvc.GetMyTrades(ctx.Request.Context(), ctx.Query("key"), symbol, fromId, startTime, endTime, limit)
+	if err != nil {
+		upstreamError(ctx, err)
+		return
+	}
+	response.Success(ctx, resp)
+}
+
+// Deposits returns the deposits of the account, of coin when given.
+func (h *accountHandler) Deposits(ctx *gin.Context) {
+	limit, ok := queryLimit(ctx, "1000")
+	if !ok {
+		return
+	}
+	startTime, endTime, ok := queryTimeRange(ctx)
+	if !ok {
+		return
+	}
+	resp, err := h.binanceSvc.GetDepositHistory(ctx.Request.Context(), ctx.Query("key"), ctx.Query("coin"), startTime, endTime, limit)
+	if err != nil {
+		upstreamError(ctx, err)
+		return
+	}
+	response.Success(ctx, resp)
+}
+
+// Withdrawals returns the withdrawals of the account, of coin when given.
+func (h *accountHandler) Withdrawals(ctx *gin.Context) {
+	limit, ok := queryLimit(ctx, "1000")
+	if !ok {
+		return
+	}
+	startTime, endTime, ok := queryTimeRange(ctx)
+	if !ok {
+		return
+	}
+	resp, err := h.binanceSvc.GetWithdrawHistory(ctx.Request.Context(), ctx.Query("key"), ctx.Query("coin"), startTime, endTime, limit)
+	if err != nil {
+		upstreamError(ctx, err)
+		return
+	}
+	response.Success(ctx, resp)
+}
+
+func queryTimeRange(ctx *gin.Context) (*int64, *int64, bool) {
+	startTime, ok := queryTime(ctx, "startTime")
+	if !ok {
+		return nil, nil, false
+	}
+	endTime, ok := queryTime(ctx, "endTime")
+	if !ok {
+		return nil, nil, false
+	}
+	return startTime, endTime, true
+}
@@ -1,13 +1,20 @@
 package interfaces
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ntdat104/go-finance-dataset/internal/application/constants"
+	"github.com/ntdat104/go-finance-dataset/internal/application/dto"
 	"github.com/ntdat104/go-finance-dataset/internal/application/response"
 	"github.com/ntdat104/go-finance-dataset/internal/application/service"
+	"github.com/ntdat104/go-finance-dataset/pkg/httpclient"
 )
 
 type BinanceHandler interface {
@@ -20,6 +27,7 @@ type BinanceHandler interface {
 	Depth(ctx *gin.Context)
 	RecentTrades(ctx *gin.Context)
 	Klines(ctx *gin.Context)
+	KlinesRange(ctx *gin.Context)
 	HistoricalTrades(ctx *gin.Context)
 	AggregateTrades(ctx *gin.Context)
 	AvgPrice(ctx *gin.Context)
@@ -51,6 +59,7 @@ func (h *binanceHandler) initRoutes() {
 	h.router.GET(constants.ApiBinanceDepth, h.Depth)
 	h.router.GET(constants.ApiBinanceRecentTrades, h.RecentTrades)
 	h.router.GET(constants.ApiBinanceKlines, h.Klines)
+	h.router.GET(constants.ApiBinanceKlinesRange, h.KlinesRange)
 	h.router.GET(constants.ApiBinanceHistoricalTrades, h.HistoricalTrades)
 	h.router.GET(constants.ApiBinanceAggregateTrades, h.AggregateTrades)
 	h.router.GET(constants.ApiBinanceAvgPrice, h.AvgPrice)
@@ -60,9 +69,9 @@ func (h *binanceHandler) initRoutes() {
 
 // Ping handles the /api/v3/ping endpoint.
 func (c *binanceHandler) Ping(ctx *gin.Context) {
-	resp, err := c.binanceSvc.GetPing()
+	resp, err := c.binanceSvc.GetPing(ctx.Request.Context())
 	if err != nil {
-		response.JSON(ctx, http.StatusInternalServerError, gin.H{"error": err.Error()})
+		upstreamError(ctx, err)
 		return
 	}
 	response.Success(ctx, resp)
@@ -70,9 +79,9 @@ func (c *binanceHandler) Ping(ctx *gin.Context) {
 
 // ServerTime handles the /api/v3/time endpoint.
 func (c *binanceHandler) ServerTime(ctx *gin.Context) {
-	resp, err := c.binanceSvc.GetServerTime()
+	resp, err := c.binanceSvc.GetServerTime(ctx.Request.Context())
 	if err != nil {
-		response.JSON(ctx, http.StatusInternalServerError, gin.H{"error": err.Error()})
+		upstreamError(ctx, err)
 		return
 	}
 	response.Success(ctx, resp)
@@ -80,9 +89,9 @@ func (c *binanceHandler) ServerTime(ctx *gin.Context) {
 
 // ExchangeInfo handles the /api/v3/exchangeInfo endpoint.
 func (c *binanceHandler) ExchangeInfo(ctx *gin.Context) {
-	resp, err := c.binanceSvc.GetExchangeInfo()
+	resp, err := c.binanceSvc.GetExchangeInfo(ctx.Request.Context())
 	if err != nil {
-		response.JSON(ctx, http.StatusInternalServerError, gin.H{"error": err.Error()})
+		upstreamError(ctx, err)
 		return
 	}
 	response.Success(ctx, resp)
@@ -95,9 +104,9 @@ func (c *binanceHandler) TickerPrice(ctx *gin.Context) {
 		response.JSON(ctx, http.StatusBadRequest, gin.H{"error": "symbol query parameter is required"})
 		return
 	}
-	resp, err := c.binanceSvc.GetTickerPrice(symbol)
+	resp, err := c.binanceSvc.GetTickerPrice(ctx.Request.Context(), symbol)
 	if err != nil {
-		response.JSON(ctx, http.StatusInternalServerError, gin.H{"error": err.Error()})
+		upstreamError(ctx, err)
 		return
 	}
 	response.Success(ctx, resp)
@@ -105,9 +114,9 @@ func (c *binanceHandler) TickerPrice(ctx *gin.Context) {
 
 // AllPrices handles the /api/v3/ticker/price endpoint for all symbols.
 func (c *binanceHandler) AllPrices(ctx *gin.Context) {
-	resp, err := c.binanceSvc.GetAllTickerPrices()
+	resp, err := c.binanceSvc.GetAllTickerPrices(ctx.Request.Context())
 	if err != nil {
-		response.JSON(ctx, http.StatusInternalServerError, gin.H{"error": err.Error()})
+		upstreamError(ctx, err)
 		return
 	}
 	response.Success(ctx, resp)
@@ -120,9 +129,9 @@ func (c *binanceHandler) BookTicker(ctx *gin.Context) {
 		response.JSON(ctx, http.StatusBadRequest, gin.H{"error": "symbol query parameter is required"})
 		return
 	}
-	resp, err := c.binanceSvc.GetBookTicker(symbol)
+	resp, err := c.binanceSvc.GetBookTicker(ctx.Request.Context(), symbol)
 	if err != nil {
-		response.JSON(ctx, http.StatusInternalServerError, gin.H{"error": err.Error()})
+		upstreamError(ctx, err)
 		return
 	}
 	response.Success(ctx, resp)
@@ -142,9 +151,9 @@ func (c *binanceHandler) Depth(ctx *gin.Context) {
 		return
 	}
 
-	resp, err := c.binanceSvc.GetDepth(symbol, limit)
+	resp, err := c.binanceSvc.GetDepth(ctx.Request.Context(), symbol, limit)
 	if err != nil {
-		response.JSON(ctx, http.StatusInternalServerError, gin.H{"error": err.Error()})
+		upstreamError(ctx, err)
 		return
 	}
 	response.Success(ctx, resp)
@@ -159,14 +168,14 @@ func (c *binanceHandler) RecentTrades(ctx *gin.Context) {
 	}
 	limitStr := ctx.DefaultQuery("limit", "10")
 	limit, err := strconv.Atoi(limitStr)
-	if err != nil {
+	if err != nil || limit <= 0 {
 		response.JSON(ctx, http.StatusBadRequest, gin.H{"error": "invalid limit parameter"})
 		return
 	}
 
-	resp, err := c.binanceSvc.GetRecentTrades(symbol, limit)
+	resp, err := c.binanceSvc.GetRecentTrades(ctx.Request.Context(), symbol, limit)
 	if err != nil {
-		response.JSON(ctx, http.StatusInternalServerError, gin.H{"error": err.Error()})
+		upstreamError(ctx, err)
 		return
 	}
 	response.Success(ctx, resp)
@@ -187,14 +196,74 @@ func (c *binanceHandler) Klines(ctx *gin.Context) {
 		return
 	}
 
-	resp, err := c.binanceSvc.GetKlines(symbol, interval, limit)
+	resp, err := c.binanceSvc.GetKlines(ctx.Request.Context(), symbol, interval, limit)
 	if err != nil {
-		response.JSON(ctx, http.StatusInternalServerError, gin.H{"error": err.Error()})
+		upstreamError(ctx, err)
 		return
 	}
 	response.Success(ctx, resp)
 }
 
+// KlinesRange handles the /api/v3/klines endpoint for an arbitrary time range, paginating upstream.
+// With stream=true the candles are written as newline-delimited JSON while pages arrive.
+func (c *binanceHandler) KlinesRange(ctx *gin.Context) {
+	symbol := ctx.Query("symbol")
+	interval := ctx.Query("interval")
+	if symbol == "" || interval == "" {
+		response.JSON(ctx, http.StatusBadRequest, gin.H{"error": "symbol and interval query parameters are required"})
+		return
+	}
+	if err := service.ValidateInterval(interval); err != nil {
+		response.JSON(ctx, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	startTime, err := strconv.ParseInt(ctx.Query("startTime"), 10, 64)
+	if err != nil {
+		response.JSON(ctx, http.StatusBadRequest, gin.H{"error": "invalid startTime parameter"})
+		return
+	}
+	endTime := time.Now().UnixMilli()
+	if s := ctx.Query("endTime"); s != "" {
+		endTime, err = strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			response.JSON(ctx, http.StatusBadRequest, gin.H{"error": "invalid endTime parameter"})
+			return
+		}
+	}
+	if endTime < startTime {
+		response.JSON(ctx, http.StatusBadRequest, gin.H{"error": "endTime must not be before startTime"})
+		return
+	}
+
+	if ctx.Query("stream") != "true" {
+		resp, err := c.binanceSvc.GetKlinesRange(ctx.Request.Context(), symbol, interval, startTime, endTime)
+		if err != nil {
+			upstreamError(ctx, err)
+			return
+		}
+		response.Success(ctx, resp)
+		return
+	}
+
+	ctx.Header("Content-Type", "application/x-ndjson")
+	ctx.Status(http.StatusOK)
+	enc := json.NewEncoder(ctx.Writer)
+	err = c.binanceSvc.StreamKlinesRange(ctx.Request.Context(), symbol, interval, startTime, endTime, func(page []dto.Kline) error {
+		for _, k := range page {
+			if err := enc.Encode(k); err != nil {
+				return err
+			}
+		}
+		ctx.Writer.Flush()
+		return nil
+	})
+	if err != nil {
+		// Headers are already sent, so the failure is reported as the last line of the stream.
+		_ = enc.Encode(gin.H{"error": err.Error()})
+		ctx.Writer.Flush()
+	}
+}
+
 // HistoricalTrades handles the /api/v3/historicalTrades endpoint.
 func (c *binanceHandler) HistoricalTrades(ctx *gin.Context) {
 	symbol := ctx.Query("symbol")
@@ -220,9 +289,9 @@ func (c *binanceHandler) HistoricalTrades(ctx *gin.Context) {
 		fromId = &id
 	}
 
-	resp, err := c.binanceSvc.GetHistoricalTrades(symbol, limit, fromId)
+	resp, err := c.binanceSvc.GetHistoricalTrades(ctx.Request.Context(), symbol, limit, fromId)
 	if err != nil {
-		response.JSON(ctx, http.StatusInternalServerError, gin.H{"error": err.Error()})
+		upstreamError(ctx, err)
 		return
 	}
 	response.Success(ctx, resp)
@@ -272,9 +341,9 @@ func (c *binanceHandler) AggregateTrades(ctx *gin.Context) {
 		limit = l
 	}
 
-	resp, err := c.binanceSvc.GetAggregateTrades(symbol, fromId, startTime, endTime, limit)
+	resp, err := c.binanceSvc.GetAggregateTrades(ctx.Request.Context(), symbol, fromId, startTime, endTime, limit)
 	if err != nil {
-		response.JSON(ctx, http.StatusInternalServerError, gin.H{"error": err.Error()})
+		upstreamError(ctx, err)
 		return
 	}
 	response.Success(ctx, resp)
@@ -287,9 +356,9 @@ func (c *binanceHandler) AvgPrice(ctx *gin.Context) {
 		response.JSON(ctx, http.StatusBadRequest, gin.H{"error": "symbol query parameter is required"})
 		return
 	}
-	resp, err := c.binanceSvc.GetAvgPrice(symbol)
+	resp, err := c.binanceSvc.GetAvgPrice(ctx.Request.Context(), symbol)
 	if err != nil {
-		response.JSON(ctx, http.StatusInternalServerError, gin.H{"error": err.Error()})
+		upstreamError(ctx, err)
 		return
 	}
 	response.Success(ctx, resp)
@@ -302,9 +371,9 @@ func (c *binanceHandler) Ticker24Hr(ctx *gin.Context) {
 		response.JSON(ctx, http.StatusBadRequest, gin.H{"error": "symbol query parameter is required"})
 		return
 	}
-	resp, err := c.binanceSvc.GetTicker24Hr(symbol)
+	resp, err := c.binanceSvc.GetTicker24Hr(ctx.Request.Context(), symbol)
 	if err != nil {
-		response.JSON(ctx, http.StatusInternalServerError, gin.H{"error": err.Error()})
+		upstreamError(ctx, err)
 		return
 	}
 	response.Success(ctx, resp)
@@ -312,10 +381,33 @@ func (c *binanceHandler) Ticker24Hr(ctx *gin.Context) {
 
 // AllBookTickers handles the /api/v3/ticker/bookTicker endpoint for all symbols.
 func (c *binanceHandler) AllBookTickers(ctx *gin.Context) {
-	resp, err := c.binanceSvc.GetAllBookTickers()
+	resp, err := c.binanceSvc.GetAllBookTickers(ctx.Request.Context())
 	if err != nil {
-		response.JSON(ctx, http.StatusInternalServerError, gin.H{"error": err.Error()})
+		upstreamError(ctx, err)
 		return
 	}
 	response.Success(ctx, resp)
 }
+
+// upstreamError reports a failed upstream call. Requests shed by the rate
+// limiter are answered with 429 and a Retry-After header, an open circuit
+// breaker with 503 and an expired deadline with 504.
+func upstreamError(ctx *gin.Context, err error) {
+	var rateLimitErr *service.RateLimitError
+	switch {
+	case errors.As(err, &rateLimitErr):
+		ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(rateLimitErr.RetryAfter.Seconds()))))
+		response.JSON(ctx, http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, service.ErrMissingCredentials):
+		response.JSON(ctx, http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, httpclient.ErrCircuitOpen):
+		response.JSON(ctx, http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, context.DeadlineExceeded):
+		response.JSON(ctx, http.StatusGatewayTimeout, gin.H{"error": err.Error()})
+		return
+	}
+	response.JSON(ctx, http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,117 @@
+package interfaces
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ntdat104/go-finance-dataset/internal/application/constants"
+	"github.com/ntdat104/go-finance-dataset/internal/application/dto"
+	"github.com/ntdat104/go-finance-dataset/internal/application/response"
+	"github.com/ntdat104/go-finance-dataset/internal/application/service"
+	"github.com/ntdat104/go-finance-dataset/pkg/middleware"
+)
+
+// maxCacheWarmItems caps the number of requests a single warm call may trigger.
+const maxCacheWarmItems = 100
+
+type CacheHandler interface {
+	Stats(ctx *gin.Context)
+	Keys(ctx *gin.Context)
+	Entry(ctx *gin.Context)
+	Invalidate(ctx *gin.Context)
+	Warm(ctx *gin.Context)
+}
+
+type cacheHandler struct {
+	router     *gin.Engine
+	adminToken string
+	binanceSvc service.BinanceSvc
+}
+
+func NewCacheHandler(router *gin.Engine, adminToken string, binanceSvc service.BinanceSvc) CacheHandler {
+	h := &cacheHandler{
+		router:     router,
+		adminToken: adminToken,
+		binanceSvc: binanceSvc,
+	}
+	h.initRoutes()
+	return h
+}
+
+func (h *cacheHandler) initRoutes() {
+	admin := h.router.Group("", middleware.AdminAuthMiddleware(h.adminToken))
+	admin.GET(constants.ApiAdminCacheStats, h.Stats)
+	admin.GET(constants.ApiAdminCacheKeys, h.Keys)
+	admin.DELETE(constants.ApiAdminCacheKeys, h.Invalidate)
+	admin.GET(constants.ApiAdminCacheEntry, h.Entry)
+	admin.POST(constants.ApiAdminCacheWarm, h.Warm)
+}
+
+// Stats returns the market data cache statistics.
+func (h *cacheHandler) Stats(ctx *gin.Context) {
+	response.Success(ctx, h.binanceSvc.CacheStats())
+}
+
+// Keys lists cached keys starting with prefix, e.g. prefix=spot_klines:, with their age and expiry.
+func (h *cacheHandler) Keys(ctx *gin.Context) {
+	limitStr := ctx.DefaultQuery("limit", "1000")
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 0 {
+		response.JSON(ctx, http.StatusBadRequest, gin.H{"error": "invalid limit parameter"})
+		return
+	}
+	response.Success(ctx, h.binanceSvc.CacheEntries(ctx.Query("prefix"), limit))
+}
+
+// Entry returns one cached key with its value.
+func (h *cacheHandler) Entry(ctx *gin.Context) {
+	key := ctx.Query("key")
+	if key == "" {
+		response.JSON(ctx, http.StatusBadRequest, gin.H{"error": "key query parameter is required"})
+		return
+	}
+	entry, ok := h.binanceSvc.CacheEntry(key)
+	if !ok {
+		response.JSON(ctx, http.StatusNotFound, gin.H{"error": "key not found"})
+		return
+	}
+	response.Success(ctx, entry)
+}
+
+// Invalidate deletes the key given by key, or every key starting with prefix.
+func (h *cacheHandler) Invalidate(ctx *gin.Context) {
+	key := ctx.Query("key")
+	prefix := ctx.Query("prefix")
+	if (key == "") == (prefix == "") {
+		response.JSON(ctx, http.StatusBadRequest, gin.H{"error": "exactly one of key or prefix is required"})
+		return
+	}
+	response.Success(ctx, gin.H{"deleted": h.binanceSvc.InvalidateCache(key, prefix)})
+}
+
+// Warm fetches the requested entries into the cache. The body is a list of
+// {"cache":"depth","symbol":"BTCUSDT","limit":100}; each item reports its own result.
+func (h *cacheHandler) Warm(ctx *gin.Context) {
+	var items []dto.CacheWarmItem
+	if err := ctx.ShouldBindJSON(&items); err != nil {
+		response.JSON(ctx, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(items) == 0 || len(items) > maxCacheWarmItems {
+		response.JSON(ctx, http.StatusBadRequest, gin.H{"error": "between 1 and " + strconv.Itoa(maxCacheWarmItems) + " items are required"})
+		return
+	}
+
+	results := make([]dto.CacheWarmResult, 0, len(items))
+	for _, item := range items {
+		result := dto.CacheWarmResult{CacheWarmItem: item}
+		key, err := h.binanceSvc.WarmCache(ctx.Request.Context(), item)
+		result.Key = key
+		if err != nil {
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	response.Success(ctx, results)
+}
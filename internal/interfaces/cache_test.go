@@ -0,0 +1,102 @@
+package interfaces
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ntdat104/go-finance-dataset/internal/application/constants"
+	"github.com/ntdat104/go-finance-dataset/internal/application/dto"
+	"github.com/ntdat104/go-finance-dataset/internal/application/service"
+)
+
+const testAdminToken = "secret"
+
+// cacheAdminSvc answers the cache admin calls of the handler.
+type cacheAdminSvc struct {
+	service.BinanceSvc
+	invalidated []string
+}
+
+func (s *cacheAdminSvc) CacheEntry(key string) (*dto.CacheEntry, bool) {
+	if key != "spot_tickerprice:BTCUSDT" {
+		return nil, false
+	}
+	return &dto.CacheEntry{Key: key}, true
+}
+
+func (s *cacheAdminSvc) InvalidateCache(key, prefix string) int {
+	s.invalidated = append(s.invalidated, key+prefix)
+	return 2
+}
+
+func (s *cacheAdminSvc) WarmCache(ctx context.Context, item dto.CacheWarmItem) (string, error) {
+	if item.Symbol == "" {
+		return "", errors.New("symbol is required")
+	}
+	return "spot_" + item.Cache + ":" + item.Symbol, nil
+}
+
+func serveAdmin(router *gin.Engine, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(constants.Authorization, "Bearer "+testAdminToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestCacheHandler(t *testing.T) {
+	svc := &cacheAdminSvc{}
+	router := gin.New()
+	NewCacheHandler(router, testAdminToken, svc)
+
+	tests := []struct {
+		method, target, body string
+		want                 int
+	}{
+		{http.MethodGet, constants.ApiAdminCacheKeys + "?limit=-1", "", http.StatusBadRequest},
+		{http.MethodGet, constants.ApiAdminCacheEntry, "", http.StatusBadRequest},
+		{http.MethodGet, constants.ApiAdminCacheEntry + "?key=spot_tickerprice:ETHUSDT", "", http.StatusNotFound},
+		{http.MethodGet, constants.ApiAdminCacheEntry + "?key=spot_tickerprice:BTCUSDT", "", http.StatusOK},
+		{http.MethodDelete, constants.ApiAdminCacheKeys, "", http.StatusBadRequest},
+		{http.MethodDelete, constants.ApiAdminCacheKeys + "?key=a&prefix=b", "", http.StatusBadRequest},
+		{http.MethodDelete, constants.ApiAdminCacheKeys + "?prefix=spot_depth:", "", http.StatusOK},
+		{http.MethodPost, constants.ApiAdminCacheWarm, "[]", http.StatusBadRequest},
+		{http.MethodPost, constants.ApiAdminCacheWarm, "{", http.StatusBadRequest},
+		{http.MethodPost, constants.ApiAdminCacheWarm, "[" + strings.Repeat(`{"cache":"depth"},`, maxCacheWarmItems) + `{"cache":"depth"}]`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if w := serveAdmin(router, tt.method, tt.target, tt.body); w.Code != tt.want {
+			t.Errorf("%s %s = %d, want %d", tt.method, tt.target, w.Code, tt.want)
+		}
+	}
+	if fmt.Sprint(svc.invalidated) != "[spot_depth:]" {
+		t.Errorf("invalidated = %v, want only the valid request", svc.invalidated)
+	}
+
+	// Warm items report their own results.
+	w := serveAdmin(router, http.MethodPost, constants.ApiAdminCacheWarm, `[{"cache":"depth","symbol":"BTCUSDT"},{"cache":"depth"}]`)
+	var body struct {
+		Data []dto.CacheWarmResult `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("warm = %d %s", w.Code, w.Body)
+	}
+	if len(body.Data) != 2 || body.Data[0].Key != "spot_depth:BTCUSDT" || body.Data[1].Error == "" {
+		t.Errorf("warm results = %+v", body.Data)
+	}
+
+	// Every route needs the admin token.
+	req := httptest.NewRequest(http.MethodGet, constants.ApiAdminCacheStats, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("unauthenticated stats = %d, want 401", w.Code)
+	}
+}
@@ -0,0 +1,97 @@
+package interfaces
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ntdat104/go-finance-dataset/internal/application/constants"
+	"github.com/ntdat104/go-finance-dataset/internal/application/response"
+	"github.com/ntdat104/go-finance-dataset/internal/application/service"
+	"github.com/ntdat104/go-finance-dataset/pkg/export"
+)
+
+type ExportHandler interface {
+	Export(ctx *gin.Context)
+}
+
+type exportHandler struct {
+	router    *gin.Engine
+	exportSvc service.ExportSvc
+}
+
+func NewExportHandler(router *gin.Engine, exportSvc service.ExportSvc) ExportHandler {
+	h := &exportHandler{
+		router:    router,
+		exportSvc: exportSvc,
+	}
+	h.initRoutes()
+	return h
+}
+
+func (h *exportHandler) initRoutes() {
+	h.router.GET(constants.ApiBinanceExport, h.Export)
+}
+
+// Export streams klines, aggTrades or historicalTrades for a symbol and time range
+// as csv, ndjson or parquet using chunked transfer encoding.
+func (h *exportHandler) Export(ctx *gin.Context) {
+	dataset := ctx.DefaultQuery("type", service.DatasetKlines)
+	format := ctx.DefaultQuery("format", export.FormatCSV)
+	symbol := ctx.Query("symbol")
+	interval := ctx.Query("interval")
+	if symbol == "" {
+		response.JSON(ctx, http.StatusBadRequest, gin.H{"error": "symbol query parameter is required"})
+		return
+	}
+	schema, err := h.exportSvc.Schema(dataset)
+	if err != nil {
+		response.JSON(ctx, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if dataset == service.DatasetKlines {
+		if err := service.ValidateInterval(interval); err != nil {
+			response.JSON(ctx, http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	startTime, err := strconv.ParseInt(ctx.Query("startTime"), 10, 64)
+	if err != nil {
+		response.JSON(ctx, http.StatusBadRequest, gin.H{"error": "invalid startTime parameter"})
+		return
+	}
+	endTime := time.Now().UnixMilli()
+	if s := ctx.Query("endTime"); s != "" {
+		endTime, err = strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			response.JSON(ctx, http.StatusBadRequest, gin.H{"error": "invalid endTime parameter"})
+			return
+		}
+	}
+	if endTime < startTime {
+		response.JSON(ctx, http.StatusBadRequest, gin.H{"error": "endTime must not be before startTime"})
+		return
+	}
+
+	writer, err := export.NewWriter(format, ctx.Writer, schema)
+	if err != nil {
+		response.JSON(ctx, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	fileName := fmt.Sprintf("%s_%s_%d_%d.%s", symbol, dataset, startTime, endTime, writer.Extension())
+	ctx.Header("Content-Type", writer.ContentType())
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+	ctx.Status(http.StatusOK)
+
+	if err := h.exportSvc.Export(ctx.Request.Context(), writer, dataset, symbol, interval, startTime, endTime); err != nil {
+		// The status line is already sent. Dropping the connection before the final chunk
+		// makes the client see a truncated transfer instead of a silently short file.
+		log.Printf("Export of %s %s failed: %v", symbol, dataset, err)
+		if conn, _, hijackErr := ctx.Writer.Hijack(); hijackErr == nil {
+			conn.Close()
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package interfaces
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ntdat104/go-finance-dataset/internal/application/constants"
+	"github.com/ntdat104/go-finance-dataset/internal/application/response"
+	"github.com/ntdat104/go-finance-dataset/internal/application/service"
+)
+
+type FundingAnalyticsHandler interface {
+	Funding(ctx *gin.Context)
+	RollingFunding(ctx *gin.Context)
+	Basis(ctx *gin.Context)
+	Ranking(ctx *gin.Context)
+}
+
+type fundingAnalyticsHandler struct {
+	router              *gin.Engine
+	fundingAnalyticsSvc service.FundingAnalyticsSvc
+}
+
+func NewFundingAnalyticsHandler(router *gin.Engine, fundingAnalyticsSvc service.FundingAnalyticsSvc) FundingAnalyticsHandler {
+	h := &fundingAnalyticsHandler{
+		router:              router,
+		fundingAnalyticsSvc: fundingAnalyticsSvc,
+	}
+	h.initRoutes()
+	return h
+}
+
+func (h *fundingAnalyticsHandler) initRoutes() {
+	h.router.GET(constants.ApiAnalyticsFunding, h.Funding)
+	h.router.GET(constants.ApiAnalyticsFundingRolling, h.RollingFunding)
+	h.router.GET(constants.ApiAnalyticsBasis, h.Basis)
+	h.router.GET(constants.ApiAnalyticsRanking, h.Ranking)
+}
+
+// Funding returns the current and annualised funding of a perpetual.
+func (h *fundingAnalyticsHandler) Funding(ctx *gin.Context) {
+	symbol, ok := requireSymbol(ctx)
+	if !ok {
+		return
+	}
+	resp, err := h.fundingAnalyticsSvc.GetFunding(ctx.Request.Context(), symbol)
+	if err != nil {
+		analyticsError(ctx, err)
+		return
+	}
+	response.Success(ctx, resp)
+}
+
+// RollingFunding averages the settled funding over windows, e.g. windows=1d,7d,30d.
+func (h *fundingAnalyticsHandler) RollingFunding(ctx *gin.Context) {
+	symbol, ok := requireSymbol(ctx)
+	if !ok {
+		return
+	}
+	resp, err := h.fundingAnalyticsSvc.GetRollingFunding(ctx.Request.Context(), symbol, splitTopics(ctx.Query("windows")))
+	if err != nil {
+		analyticsError(ctx, err)
+		return
+	}
+	response.Success(ctx, resp)
+}
+
+// Basis returns the spot-perp basis of a symbol.
+func (h *fundingAnalyticsHandler) Basis(ctx *gin.Context) {
+	symbol, ok := requireSymbol(ctx)
+	if !ok {
+		return
+	}
+	resp, err := h.fundingAnalyticsSvc.GetBasis(ctx.Request.Context(), symbol)
+	if err != nil {
+		analyticsError(ctx, err)
+		return
+	}
+	response.Success(ctx, resp)
+}
+
+// Ranking ranks perpetuals by=funding (default) or by=basis, order=desc (default)
+// or asc, optionally restricted to symbols=BTCUSDT,ETHUSDT.
+func (h *fundingAnalyticsHandler) Ranking(ctx *gin.Context) {
+	limit, ok := queryLimit(ctx, "20")
+	if !ok {
+		return
+	}
+	order := ctx.DefaultQuery("order", "desc")
+	if order != "asc" && order != "desc" {
+		response.JSON(ctx, http.StatusBadRequest, gin.H{"error": "order must be asc or desc"})
+		return
+	}
+	by := ctx.DefaultQuery("by", service.RankByFunding)
+	resp, err := h.fundingAnalyticsSvc.GetRanking(ctx.Request.Context(), by, splitTopics(ctx.Query("symbols")), limit, order == "asc")
+	if err != nil {
+		analyticsError(ctx, err)
+		return
+	}
+	response.Success(ctx, resp)
+}
+
+func analyticsError(ctx *gin.Context, err error) {
+	if errors.Is(err, service.ErrUnsupportedRanking) || errors.Is(err, service.ErrInvalidWindow) {
+		response.JSON(ctx, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	marketDataError(ctx, err)
+}
@@ -0,0 +1,224 @@
+package interfaces
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ntdat104/go-finance-dataset/internal/application/constants"
+	"github.com/ntdat104/go-finance-dataset/internal/application/response"
+	"github.com/ntdat104/go-finance-dataset/internal/application/service"
+)
+
+type FuturesHandler interface {
+	MarkPrice(ctx *gin.Context)
+	FundingRate(ctx *gin.Context)
+	OpenInterest(ctx *gin.Context)
+	LongShortRatio(ctx *gin.Context)
+	PremiumIndexKlines(ctx *gin.Context)
+	ContinuousKlines(ctx *gin.Context)
+}
+
+type futuresHandler struct {
+	router  *gin.Engine
+	markets map[string]service.BinanceFuturesSvc
+}
+
+func NewFuturesHandler(router *gin.Engine, futuresSvcs ...service.BinanceFuturesSvc) FuturesHandler {
+	h := &futuresHandler{
+		router:  router,
+		markets: make(map[string]service.BinanceFuturesSvc, len(futuresSvcs)),
+	}
+	for _, svc := range futuresSvcs {
+		h.markets[svc.Market()] = svc
+	}
+	h.initRoutes()
+	return h
+}
+
+func (h *futuresHandler) initRoutes() {
+	h.router.GET(constants.ApiFuturesMarkPrice, h.MarkPrice)
+	h.router.GET(constants.ApiFuturesFundingRate, h.FundingRate)
+	h.router.GET(constants.ApiFuturesOpenInterest, h.OpenInterest)
+	h.router.GET(constants.ApiFuturesLongShortRatio, h.LongShortRatio)
+	h.router.GET(constants.ApiFuturesPremiumIndexKlines, h.PremiumIndexKlines)
+	h.router.GET(constants.ApiFuturesContinuousKlines, h.ContinuousKlines)
+}
+
+// MarkPrice returns the mark price and funding of a contract, or of all contracts without symbol.
+func (h *futuresHandler) MarkPrice(ctx *gin.Context) {
+	svc, ok := h.market(ctx)
+	if !ok {
+		return
+	}
+	resp, err := svc.GetMarkPrice(ctx.Request.Context(), ctx.Query("symbol"))
+	if err != nil {
+		futuresError(ctx, err)
+		return
+	}
+	response.Success(ctx, resp)
+}
+
+// FundingRate returns the funding rate history of a perpetual.
+func (h *futuresHandler) FundingRate(ctx *gin.Context) {
+	svc, ok := h.market(ctx)
+	if !ok {
+		return
+	}
+	symbol, ok := requireSymbol(ctx)
+	if !ok {
+		return
+	}
+	startTime, ok := queryTime(ctx, "startTime")
+	if !ok {
+		return
+	}
+	endTime, ok := queryTime(ctx, "endTime")
+	if !ok {
+		return
+	}
+	limit, ok := queryLimit(ctx, "100")
+	if !ok {
+		return
+	}
+	resp, err := svc.GetFundingRateHistory(ctx.Request.Context(), symbol, startTime, endTime, limit)
+	if err != nil {
+		futuresError(ctx, err)
+		return
+	}
+	response.Success(ctx, resp)
+}
+
+// OpenInterest returns the current open interest of a contract.
+func (h *futuresHandler) OpenInterest(ctx *gin.Context) {
+	svc, ok := h.market(ctx)
+	if !ok {
+		return
+	}
+	symbol, ok := requireSymbol(ctx)
+	if !ok {
+		return
+	}
+	resp, err := svc.GetOpenInterest(ctx.Request.Context(), symbol)
+	if err != nil {
+		futuresError(ctx, err)
+		return
+	}
+	response.Success(ctx, resp)
+}
+
+// LongShortRatio returns the long/short ratio history. type is global (default),
+// topAccount or topPosition; period defaults to 5m.
+func (h *futuresHandler) LongShortRatio(ctx *gin.Context) {
+	svc, ok := h.market(ctx)
+	if !ok {
+		return
+	}
+	symbol, ok := requireSymbol(ctx)
+	if !ok {
+		return
+	}
+	limit, ok := queryLimit(ctx, "30")
+	if !ok {
+		return
+	}
+	kind := ctx.DefaultQuery("type", service.LongShortGlobalAccount)
+	period := ctx.DefaultQuery("period", "5m")
+	resp, err := svc.GetLongShortRatio(ctx.Request.Context(), kind, symbol, period, limit)
+	if err != nil {
+		futuresError(ctx, err)
+		return
+	}
+	response.Success(ctx, resp)
+}
+
+// PremiumIndexKlines returns the latest premium index candles of a contract.
+func (h *futuresHandler) PremiumIndexKlines(ctx *gin.Context) {
+	svc, ok := h.market(ctx)
+	if !ok {
+		return
+	}
+	symbol := ctx.Query("symbol")
+	interval := ctx.Query("interval")
+	if symbol == "" || interval == "" {
+		response.JSON(ctx, http.StatusBadRequest, gin.H{"error": "symbol and interval query parameters are required"})
+		return
+	}
+	limit, ok := queryLimit(ctx, "10")
+	if !ok {
+		return
+	}
+	resp, err := svc.GetPremiumIndexKlines(ctx.Request.Context(), symbol, interval, limit)
+	if err != nil {
+		futuresError(ctx, err)
+		return
+	}
+	response.Success(ctx, resp)
+}
+
+// ContinuousKlines returns the latest candles of a pair's continuous contract.
+// contractType defaults to PERPETUAL.
+func (h *futuresHandler) ContinuousKlines(ctx *gin.Context) {
+	svc, ok := h.market(ctx)
+	if !ok {
+		return
+	}
+	pair := ctx.Query("pair")
+	interval := ctx.Query("interval")
+	if pair == "" || interval == "" {
+		response.JSON(ctx, http.StatusBadRequest, gin.H{"error": "pair and interval query parameters are required"})
+		return
+	}
+	limit, ok := queryLimit(ctx, "10")
+	if !ok {
+		return
+	}
+	contractType := ctx.DefaultQuery("contractType", "PERPETUAL")
+	resp, err := svc.GetContinuousKlines(ctx.Request.Context(), pair, contractType, interval, limit)
+	if err != nil {
+		futuresError(ctx, err)
+		return
+	}
+	response.Success(ctx, resp)
+}
+
+// market resolves the market path parameter, answering 404 for unknown markets.
+func (h *futuresHandler) market(ctx *gin.Context) (service.BinanceFuturesSvc, bool) {
+	svc, ok := h.markets[strings.ToLower(ctx.Param("market"))]
+	if !ok {
+		markets := make([]string, 0, len(h.markets))
+		for name := range h.markets {
+			markets = append(markets, name)
+		}
+		sort.Strings(markets)
+		response.JSON(ctx, http.StatusNotFound, gin.H{"error": service.ErrUnknownFuturesMarket.Error() + " " + strconv.Quote(ctx.Param("market")), "markets": markets})
+		return nil, false
+	}
+	return svc, true
+}
+
+// queryTime parses an optional millisecond timestamp query parameter.
+func queryTime(ctx *gin.Context, name string) (*int64, bool) {
+	s := ctx.Query(name)
+	if s == "" {
+		return nil, true
+	}
+	t, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		response.JSON(ctx, http.StatusBadRequest, gin.H{"error": "invalid " + name + " parameter"})
+		return nil, false
+	}
+	return &t, true
+}
+
+// futuresError answers 400 for parameters the futures API does not support.
+func futuresError(ctx *gin.Context, err error) {
+	if errors.Is(err, service.ErrUnsupportedPeriod) || errors.Is(err, service.ErrUnsupportedContractType) || errors.Is(err, service.ErrUnsupportedRatio) {
+		response.JSON(ctx, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	marketDataError(ctx, err)
+}
@@ -0,0 +1,84 @@
+package interfaces
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ntdat104/go-finance-dataset/internal/application/constants"
+	"github.com/ntdat104/go-finance-dataset/internal/application/response"
+	"github.com/ntdat104/go-finance-dataset/internal/application/service"
+	"github.com/ntdat104/go-finance-dataset/pkg/middleware"
+)
+
+type KlineGapHandler interface {
+	Gaps(ctx *gin.Context)
+	Repair(ctx *gin.Context)
+}
+
+type klineGapHandler struct {
+	router      *gin.Engine
+	adminToken  string
+	klineGapSvc service.KlineGapSvc
+}
+
+func NewKlineGapHandler(router *gin.Engine, adminToken string, klineGapSvc service.KlineGapSvc) KlineGapHandler {
+	h := &klineGapHandler{
+		router:      router,
+		adminToken:  adminToken,
+		klineGapSvc: klineGapSvc,
+	}
+	h.initRoutes()
+	return h
+}
+
+func (h *klineGapHandler) initRoutes() {
+	admin := h.router.Group("", middleware.AdminAuthMiddleware(h.adminToken))
+	admin.GET(constants.ApiAdminKlineGaps, h.Gaps)
+	admin.POST(constants.ApiAdminKlineGapsRepair, h.Repair)
+}
+
+// Gaps scans one series when symbol and interval are given, otherwise every stored series.
+// With cached=true the reports of the last scheduled scan are returned without rescanning.
+func (h *klineGapHandler) Gaps(ctx *gin.Context) {
+	symbol := ctx.Query("symbol")
+	interval := ctx.Query("interval")
+	if symbol != "" && interval != "" {
+		resp, err := h.klineGapSvc.Scan(symbol, interval)
+		if err != nil {
+			response.JSON(ctx, http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		response.Success(ctx, resp)
+		return
+	}
+	if ctx.Query("cached") == "true" {
+		response.Success(ctx, h.klineGapSvc.LastReports())
+		return
+	}
+	resp, err := h.klineGapSvc.ScanAll()
+	if err != nil {
+		response.JSON(ctx, http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	response.Success(ctx, resp)
+}
+
+// Repair re-fetches the missing candles of one series and returns the post-repair report.
+func (h *klineGapHandler) Repair(ctx *gin.Context) {
+	symbol := ctx.Query("symbol")
+	interval := ctx.Query("interval")
+	if symbol == "" || interval == "" {
+		response.JSON(ctx, http.StatusBadRequest, gin.H{"error": "symbol and interval query parameters are required"})
+		return
+	}
+	if err := service.ValidateInterval(interval); err != nil {
+		response.JSON(ctx, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	resp, err := h.klineGapSvc.Repair(ctx.Request.Context(), symbol, interval)
+	if err != nil {
+		response.JSON(ctx, http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	response.Success(ctx, resp)
+}
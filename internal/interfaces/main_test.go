@@ -0,0 +1,17 @@
+package interfaces
+
+import (
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ntdat104/go-finance-dataset/pkg/config"
+	"github.com/ntdat104/go-finance-dataset/pkg/logger"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitDefault()
+	config.InitConfig("../../config/dev.yml")
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
@@ -0,0 +1,207 @@
+package interfaces
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ntdat104/go-finance-dataset/internal/application/constants"
+	"github.com/ntdat104/go-finance-dataset/internal/application/response"
+	"github.com/ntdat104/go-finance-dataset/internal/application/service"
+)
+
+type MarketDataHandler interface {
+	Exchanges(ctx *gin.Context)
+	Symbols(ctx *gin.Context)
+	Ticker(ctx *gin.Context)
+	Book(ctx *gin.Context)
+	Depth(ctx *gin.Context)
+	Trades(ctx *gin.Context)
+	Klines(ctx *gin.Context)
+}
+
+type marketDataHandler struct {
+	router        *gin.Engine
+	marketDataSvc service.MarketDataSvc
+}
+
+func NewMarketDataHandler(router *gin.Engine, marketDataSvc service.MarketDataSvc) MarketDataHandler {
+	h := &marketDataHandler{
+		router:        router,
+		marketDataSvc: marketDataSvc,
+	}
+	h.initRoutes()
+	return h
+}
+
+func (h *marketDataHandler) initRoutes() {
+	h.router.GET(constants.ApiMarketExchanges, h.Exchanges)
+	h.router.GET(constants.ApiMarketSymbols, h.Symbols)
+	h.router.GET(constants.ApiMarketTicker, h.Ticker)
+	h.router.GET(constants.ApiMarketBook, h.Book)
+	h.router.GET(constants.ApiMarketDepth, h.Depth)
+	h.router.GET(constants.ApiMarketTrades, h.Trades)
+	h.router.GET(constants.ApiMarketKlines, h.Klines)
+}
+
+// Exchanges lists the exchanges served under /api/v1/crypto/{exchange}.
+func (h *marketDataHandler) Exchanges(ctx *gin.Context) {
+	response.Success(ctx, h.marketDataSvc.Exchanges())
+}
+
+// Symbols lists the pairs of an exchange.
+func (h *marketDataHandler) Symbols(ctx *gin.Context) {
+	provider, ok := h.provider(ctx)
+	if !ok {
+		return
+	}
+	resp, err := provider.GetSymbols(ctx.Request.Context())
+	if err != nil {
+		marketDataError(ctx, err)
+		return
+	}
+	response.Success(ctx, resp)
+}
+
+// Ticker returns the 24h ticker of a symbol.
+func (h *marketDataHandler) Ticker(ctx *gin.Context) {
+	provider, ok := h.provider(ctx)
+	if !ok {
+		return
+	}
+	symbol, ok := requireSymbol(ctx)
+	if !ok {
+		return
+	}
+	resp, err := provider.GetTicker(ctx.Request.Context(), symbol)
+	if err != nil {
+		marketDataError(ctx, err)
+		return
+	}
+	response.Success(ctx, resp)
+}
+
+// Book returns the best bid and ask of a symbol.
+func (h *marketDataHandler) Book(ctx *gin.Context) {
+	provider, ok := h.provider(ctx)
+	if !ok {
+		return
+	}
+	symbol, ok := requireSymbol(ctx)
+	if !ok {
+		return
+	}
+	resp, err := provider.GetBook(ctx.Request.Context(), symbol)
+	if err != nil {
+		marketDataError(ctx, err)
+		return
+	}
+	response.Success(ctx, resp)
+}
+
+// Depth returns the order book of a symbol.
+func (h *marketDataHandler) Depth(ctx *gin.Context) {
+	provider, ok := h.provider(ctx)
+	if !ok {
+		return
+	}
+	symbol, ok := requireSymbol(ctx)
+	if !ok {
+		return
+	}
+	limit, ok := queryLimit(ctx, "10")
+	if !ok {
+		return
+	}
+	resp, err := provider.GetDepth(ctx.Request.Context(), symbol, limit)
+	if err != nil {
+		marketDataError(ctx, err)
+		return
+	}
+	response.Success(ctx, resp)
+}
+
+// Trades returns the recent trades of a symbol.
+func (h *marketDataHandler) Trades(ctx *gin.Context) {
+	provider, ok := h.provider(ctx)
+	if !ok {
+		return
+	}
+	symbol, ok := requireSymbol(ctx)
+	if !ok {
+		return
+	}
+	limit, ok := queryLimit(ctx, "10")
+	if !ok {
+		return
+	}
+	resp, err := provider.GetTrades(ctx.Request.Context(), symbol, limit)
+	if err != nil {
+		marketDataError(ctx, err)
+		return
+	}
+	response.Success(ctx, resp)
+}
+
+// Klines returns the latest candles of a symbol.
+func (h *marketDataHandler) Klines(ctx *gin.Context) {
+	provider, ok := h.provider(ctx)
+	if !ok {
+		return
+	}
+	symbol := ctx.Query("symbol")
+	interval := ctx.Query("interval")
+	if symbol == "" || interval == "" {
+		response.JSON(ctx, http.StatusBadRequest, gin.H{"error": "symbol and interval query parameters are required"})
+		return
+	}
+	limit, ok := queryLimit(ctx, "10")
+	if !ok {
+		return
+	}
+	resp, err := provider.GetKlines(ctx.Request.Context(), symbol, interval, limit)
+	if err != nil {
+		marketDataError(ctx, err)
+		return
+	}
+	response.Success(ctx, resp)
+}
+
+// provider resolves the exchange path parameter, answering 404 for unknown exchanges.
+func (h *marketDataHandler) provider(ctx *gin.Context) (service.MarketDataProvider, bool) {
+	provider, err := h.marketDataSvc.Provider(ctx.Param("exchange"))
+	if err != nil {
+		response.JSON(ctx, http.StatusNotFound, gin.H{"error": err.Error(), "exchanges": h.marketDataSvc.Exchanges()})
+		return nil, false
+	}
+	return provider, true
+}
+
+func requireSymbol(ctx *gin.Context) (string, bool) {
+	symbol := ctx.Query("symbol")
+	if symbol == "" {
+		response.JSON(ctx, http.StatusBadRequest, gin.H{"error": "symbol query parameter is required"})
+		return "", false
+	}
+	return symbol, true
+}
+
+func queryLimit(ctx *gin.Context, def string) (int, bool) {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", def))
+	if err != nil || limit <= 0 {
+		response.JSON(ctx, http.StatusBadRequest, gin.H{"error": "invalid limit parameter"})
+		return 0, false
+	}
+	return limit, true
+}
+
+// marketDataError answers 400 for requests a provider cannot map to its API
+// and reports everything else as an upstream failure.
+func marketDataError(ctx *gin.Context, err error) {
+	if errors.Is(err, service.ErrUnsupportedInterval) || errors.Is(err, service.ErrUnknownSymbol) {
+		response.JSON(ctx, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	upstreamError(ctx, err)
+}
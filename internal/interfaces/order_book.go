@@ -0,0 +1,64 @@
+package interfaces
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ntdat104/go-finance-dataset/internal/application/constants"
+	"github.com/ntdat104/go-finance-dataset/internal/application/response"
+	"github.com/ntdat104/go-finance-dataset/internal/application/service"
+)
+
+type OrderBookHandler interface {
+	OrderBook(ctx *gin.Context)
+}
+
+type orderBookHandler struct {
+	router       *gin.Engine
+	orderBookSvc service.OrderBookSvc
+}
+
+func NewOrderBookHandler(router *gin.Engine, orderBookSvc service.OrderBookSvc) OrderBookHandler {
+	h := &orderBookHandler{
+		router:       router,
+		orderBookSvc: orderBookSvc,
+	}
+	h.initRoutes()
+	return h
+}
+
+func (h *orderBookHandler) initRoutes() {
+	h.router.GET(constants.ApiBinanceOrderBook, h.OrderBook)
+}
+
+// OrderBook returns the locally maintained book of a tracked symbol.
+// limit=0 returns every level.
+func (h *orderBookHandler) OrderBook(ctx *gin.Context) {
+	symbol := ctx.Query("symbol")
+	if symbol == "" {
+		response.JSON(ctx, http.StatusBadRequest, gin.H{"error": "symbol query parameter is required"})
+		return
+	}
+	limitStr := ctx.DefaultQuery("limit", "100")
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 0 {
+		response.JSON(ctx, http.StatusBadRequest, gin.H{"error": "invalid limit parameter"})
+		return
+	}
+
+	resp, err := h.orderBookSvc.GetOrderBook(symbol, limit)
+	switch {
+	case errors.Is(err, service.ErrOrderBookNotTracked):
+		response.JSON(ctx, http.StatusNotFound, gin.H{"error": err.Error(), "tracked": h.orderBookSvc.Symbols()})
+		return
+	case errors.Is(err, service.ErrOrderBookNotSynced):
+		response.JSON(ctx, http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		response.JSON(ctx, http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	response.Success(ctx, resp)
+}
@@ -0,0 +1,239 @@
+package interfaces
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ntdat104/go-finance-dataset/internal/application/constants"
+	"github.com/ntdat104/go-finance-dataset/internal/application/response"
+	"github.com/ntdat104/go-finance-dataset/internal/application/service"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	pushWriteTimeout      = 10 * time.Second
+	pushHeartbeatPeriod   = 15 * time.Second
+	pushMethodSubscribe   = "SUBSCRIBE"
+	pushMethodUnsubscribe = "UNSUBSCRIBE"
+	pushMethodList        = "LIST_SUBSCRIPTIONS"
+)
+
+type PushHandler interface {
+	WebSocket(ctx *gin.Context)
+	SSE(ctx *gin.Context)
+}
+
+type pushHandler struct {
+	router         *gin.Engine
+	pushSvc        service.PushSvc
+	allowedOrigins []string
+}
+
+// pushRequest is a control message sent by websocket clients.
+type pushRequest struct {
+	ID     int64    `json:"id"`
+	Method string   `json:"method"`
+	Params []string `json:"params"`
+}
+
+type pushReply struct {
+	ID     int64    `json:"id"`
+	Result []string `json:"result,omitempty"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// NewPushHandler serves the push API. Browsers may only open websockets from
+// allowedOrigins, where "*" allows any origin.
+func NewPushHandler(router *gin.Engine, pushSvc service.PushSvc, allowedOrigins []string) PushHandler {
+	h := &pushHandler{
+		router:         router,
+		pushSvc:        pushSvc,
+		allowedOrigins: allowedOrigins,
+	}
+	h.initRoutes()
+	return h
+}
+
+func (h *pushHandler) initRoutes() {
+	h.router.GET(constants.ApiPushWebSocket, h.WebSocket)
+	h.router.GET(constants.ApiPushSSE, h.SSE)
+}
+
+// connect registers a client and applies the comma separated subscribe query parameter.
+func (h *pushHandler) connect(ctx *gin.Context) (service.PushClient, bool) {
+	client, err := h.pushSvc.Connect()
+	if err != nil {
+		response.JSON(ctx, http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if topics := splitTopics(ctx.Query("subscribe")); len(topics) > 0 {
+		if err := client.Subscribe(topics...); err != nil {
+			client.Close()
+			response.JSON(ctx, http.StatusBadRequest, gin.H{"error": err.Error()})
+			return nil, false
+		}
+	}
+	return client, true
+}
+
+func splitTopics(s string) []string {
+	var topics []string
+	for _, topic := range strings.Split(s, ",") {
+		if topic = strings.TrimSpace(topic); topic != "" {
+			topics = append(topics, topic)
+		}
+	}
+	return topics
+}
+
+// WebSocket pushes updates over a websocket. Clients manage subscriptions with
+// {"id":1,"method":"SUBSCRIBE","params":["ticker:BTCUSDT","klines:BTCUSDT:1m"]};
+// UNSUBSCRIBE and LIST_SUBSCRIPTIONS work the same way.
+func (h *pushHandler) WebSocket(ctx *gin.Context) {
+	client, ok := h.connect(ctx)
+	if !ok {
+		return
+	}
+	defer client.Close()
+
+	server := websocket.Server{
+		Handshake: h.handshake,
+		Handler: func(conn *websocket.Conn) {
+			h.serveWebSocket(conn, client)
+		},
+	}
+	server.ServeHTTP(ctx.Writer, ctx.Request)
+}
+
+// handshake accepts clients that send no Origin, as non-browser clients do,
+// and browsers on an allowed origin.
+func (h *pushHandler) handshake(config *websocket.Config, req *http.Request) error {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	if !slices.Contains(h.allowedOrigins, "*") && !slices.Contains(h.allowedOrigins, origin) {
+		return fmt.Errorf("origin %q is not allowed", origin)
+	}
+	var err error
+	config.Origin, err = websocket.Origin(config, req)
+	return err
+}
+
+func (h *pushHandler) serveWebSocket(conn *websocket.Conn, client service.PushClient) {
+	defer conn.Close()
+
+	replies := make(chan response.Response, 16)
+	go func() {
+		defer client.Close()
+		for {
+			var req pushRequest
+			if err := websocket.JSON.Receive(conn, &req); err != nil {
+				var syntaxErr *json.SyntaxError
+				var typeErr *json.UnmarshalTypeError
+				if !errors.As(err, &syntaxErr) && !errors.As(err, &typeErr) {
+					return
+				}
+				req.Method = ""
+			}
+			select {
+			case replies <- h.handlePushRequest(client, req):
+			case <-client.Done():
+				return
+			}
+		}
+	}()
+
+	send := func(msg response.Response) bool {
+		conn.SetWriteDeadline(time.Now().Add(pushWriteTimeout))
+		return websocket.JSON.Send(conn, msg) == nil
+	}
+	for {
+		select {
+		case reply := <-replies:
+			if !send(reply) {
+				return
+			}
+		case msg := <-client.Messages():
+			if !send(response.NewResponse("", http.StatusOK, msg)) {
+				return
+			}
+		case <-client.Done():
+			if err := client.Err(); err != nil {
+				send(response.NewResponse("", http.StatusTooManyRequests, gin.H{"error": err.Error()}))
+			}
+			return
+		}
+	}
+}
+
+func (h *pushHandler) handlePushRequest(client service.PushClient, req pushRequest) response.Response {
+	var err error
+	switch req.Method {
+	case pushMethodSubscribe:
+		err = client.Subscribe(req.Params...)
+	case pushMethodUnsubscribe:
+		err = client.Unsubscribe(req.Params...)
+	case pushMethodList:
+	default:
+		err = fmt.Errorf("unknown method %q", req.Method)
+	}
+	if err != nil {
+		return response.NewResponse("", http.StatusBadRequest, pushReply{ID: req.ID, Error: err.Error()})
+	}
+	return response.NewResponse("", http.StatusOK, pushReply{ID: req.ID, Result: client.Topics()})
+}
+
+// SSE pushes updates as server-sent events for the topics in the subscribe query parameter.
+func (h *pushHandler) SSE(ctx *gin.Context) {
+	if len(splitTopics(ctx.Query("subscribe"))) == 0 {
+		response.JSON(ctx, http.StatusBadRequest, gin.H{"error": "subscribe query parameter is required"})
+		return
+	}
+	client, ok := h.connect(ctx)
+	if !ok {
+		return
+	}
+	defer client.Close()
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+	ctx.Writer.Flush()
+
+	heartbeat := time.NewTicker(pushHeartbeatPeriod)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case msg := <-client.Messages():
+			data, err := json.Marshal(response.NewResponse("", http.StatusOK, msg))
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(ctx.Writer, "event: %s\ndata: %s\n\n", msg.Channel, data); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(ctx.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case <-client.Done():
+			if err := client.Err(); err != nil {
+				fmt.Fprintf(ctx.Writer, "event: error\ndata: %s\n\n", err.Error())
+				ctx.Writer.Flush()
+			}
+			return
+		case <-ctx.Request.Context().Done():
+			return
+		}
+		ctx.Writer.Flush()
+	}
+}
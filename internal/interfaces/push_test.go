@@ -0,0 +1,38 @@
+package interfaces
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/net/websocket"
+)
+
+func TestPushHandshake(t *testing.T) {
+	tests := []struct {
+		allowed []string
+		origin  string
+		ok      bool
+	}{
+		{nil, "", true},
+		{nil, "https://example.com", false},
+		{[]string{"https://app.example.com"}, "https://app.example.com", true},
+		{[]string{"https://app.example.com"}, "https://evil.example.com", false},
+		{[]string{"*"}, "https://evil.example.com", true},
+	}
+	for _, tt := range tests {
+		h := &pushHandler{allowedOrigins: tt.allowed}
+		req := httptest.NewRequest("GET", "/ws", nil)
+		if tt.origin != "" {
+			req.Header.Set("Origin", tt.origin)
+		}
+		config := &websocket.Config{Version: websocket.ProtocolVersionHybi13}
+		err := h.handshake(config, req)
+		if (err == nil) != tt.ok {
+			t.Errorf("allowed %v, origin %q: error = %v, want ok %v", tt.allowed, tt.origin, err, tt.ok)
+			continue
+		}
+		if tt.ok && tt.origin != "" && config.Origin.String() != tt.origin {
+			t.Errorf("origin %q recorded as %v", tt.origin, config.Origin)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package interfaces
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ntdat104/go-finance-dataset/internal/application/constants"
+	"github.com/ntdat104/go-finance-dataset/internal/application/response"
+	"github.com/ntdat104/go-finance-dataset/internal/application/service"
+	"github.com/ntdat104/go-finance-dataset/pkg/middleware"
+)
+
+type RateLimitHandler interface {
+	Budget(ctx *gin.Context)
+}
+
+type rateLimitHandler struct {
+	router         *gin.Engine
+	adminToken     string
+	rateLimiterSvc service.RateLimiterSvc
+}
+
+func NewRateLimitHandler(router *gin.Engine, adminToken string, rateLimiterSvc service.RateLimiterSvc) RateLimitHandler {
+	h := &rateLimitHandler{
+		router:         router,
+		adminToken:     adminToken,
+		rateLimiterSvc: rateLimiterSvc,
+	}
+	h.initRoutes()
+	return h
+}
+
+func (h *rateLimitHandler) initRoutes() {
+	admin := h.router.Group("", middleware.AdminAuthMiddleware(h.adminToken))
+	admin.GET(constants.ApiAdminRateLimit, h.Budget)
+}
+
+// Budget returns the current upstream request weight budget.
+func (h *rateLimitHandler) Budget(ctx *gin.Context) {
+	response.Success(ctx, h.rateLimiterSvc.Budget())
+}
@@ -0,0 +1,161 @@
+package apikey
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Key types. HMAC keys sign with their secret and produce hex signatures;
+// Ed25519 keys sign with a PKCS#8 private key and produce base64 signatures.
+const (
+	TypeHMAC    = "hmac"
+	TypeEd25519 = "ed25519"
+)
+
+// ErrNotFound is returned for a key name the store does not hold.
+var ErrNotFound = errors.New("api key not found")
+
+// Spec describes a key to load. Secret is used by HMAC keys, PrivateKeyPath by
+// Ed25519 keys and points at a PEM encoded PKCS#8 private key.
+type Spec struct {
+	Name           string
+	Type           string
+	APIKey         string
+	Secret         string
+	PrivateKeyPath string
+}
+
+// Key is a loaded API key. The signing material is unexported so a Key can be
+// logged or serialised without leaking it.
+type Key struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	APIKey string `json:"-"`
+
+	secret     []byte
+	privateKey ed25519.PrivateKey
+}
+
+// Sign signs payload, typically the URL encoded query string of a request.
+func (k *Key) Sign(payload string) (string, error) {
+	switch k.Type {
+	case TypeHMAC:
+		mac := hmac.New(sha256.New, k.secret)
+		mac.Write([]byte(payload))
+		return hex.EncodeToString(mac.Sum(nil)), nil
+	case TypeEd25519:
+		sig, err := k.privateKey.Sign(rand.Reader, []byte(payload), crypto.Hash(0))
+		if err != nil {
+			return "", err
+		}
+		return base64.StdEncoding.EncodeToString(sig), nil
+	default:
+		return "", fmt.Errorf("unsupported key type %q", k.Type)
+	}
+}
+
+// Store holds the API keys by name.
+type Store struct {
+	keys        map[string]*Key
+	defaultName string
+}
+
+// NewStore loads specs. defaultName is the key returned for an empty name; it
+// defaults to the first spec.
+func NewStore(defaultName string, specs ...Spec) (*Store, error) {
+	s := &Store{keys: make(map[string]*Key, len(specs)), defaultName: defaultName}
+	for _, spec := range specs {
+		key, err := load(spec)
+		if err != nil {
+			return nil, fmt.Errorf("api key %q: %w", spec.Name, err)
+		}
+		if _, ok := s.keys[key.Name]; ok {
+			return nil, fmt.Errorf("api key %q: duplicate name", spec.Name)
+		}
+		s.keys[key.Name] = key
+		if s.defaultName == "" {
+			s.defaultName = key.Name
+		}
+	}
+	return s, nil
+}
+
+func load(spec Spec) (*Key, error) {
+	if spec.Name == "" {
+		return nil, errors.New("name is required")
+	}
+	if spec.APIKey == "" {
+		return nil, errors.New("api key is required")
+	}
+	key := &Key{Name: spec.Name, Type: spec.Type, APIKey: spec.APIKey}
+	switch spec.Type {
+	case TypeHMAC:
+		if spec.Secret == "" {
+			return nil, errors.New("secret is required for hmac keys")
+		}
+		key.secret = []byte(spec.Secret)
+	case TypeEd25519:
+		data, err := os.ReadFile(spec.PrivateKeyPath)
+		if err != nil {
+			return nil, err
+		}
+		if key.privateKey, err = parseEd25519(data); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", spec.Type)
+	}
+	return key, nil
+}
+
+func parseEd25519(data []byte) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("private key is not PEM encoded")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key is a %T, not ed25519", parsed)
+	}
+	return privateKey, nil
+}
+
+// Get returns the key called name, or the default key for an empty name.
+func (s *Store) Get(name string) (*Key, error) {
+	if name == "" {
+		name = s.defaultName
+	}
+	key, ok := s.keys[name]
+	if !ok {
+		if name == "" {
+			return nil, fmt.Errorf("%w: none configured", ErrNotFound)
+		}
+		return nil, fmt.Errorf("%w: %q", ErrNotFound, name)
+	}
+	return key, nil
+}
+
+// Names lists the key names in order.
+func (s *Store) Names() []string {
+	names := make([]string, 0, len(s.keys))
+	for name := range s.keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,114 @@
+package apikey
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSignHMAC(t *testing.T) {
+	// The example from Binance's API documentation.
+	store, err := NewStore("", Spec{
+		Name:   "main",
+		Type:   TypeHMAC,
+		APIKey: "vmPUZE6mv9SD5VNHk4HlWFsOr6aKE2zvsw0MuIgwCIPy6utIco14y7Ju91duEh8A",
+		Secret: "NhqPtmdSJYdKjVHjA7PZj4Mge3R5YNiP1e3UZjInClVN65XAbvqqM6A7H5fATj0j",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := store.Get("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := "symbol=LTCBTC&side=BUY&type=LIMIT&timeInForce=GTC&quantity=1&price=0.1&recvWindow=5000&timestamp=1499827319559"
+	got, err := key.Sign(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "c8db56825ae71d6d79447849e617115f4a920fa2acdcab2b053c4b2838bd6b71"; got != want {
+		t.Errorf("Sign = %s, want %s", got, want)
+	}
+}
+
+func TestSignEd25519(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewStore("", Spec{Name: "ed", Type: TypeEd25519, APIKey: "key", PrivateKeyPath: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := store.Get("ed")
+	payload := "symbol=BTCUSDT&timestamp=1700000000000"
+	got, err := key.Sign(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := base64.StdEncoding.DecodeString(got)
+	if err != nil {
+		t.Fatalf("signature %q is not base64: %v", got, err)
+	}
+	if !ed25519.Verify(public, []byte(payload), sig) {
+		t.Error("signature does not verify against the public key")
+	}
+}
+
+func TestNewStoreRejectsBadSpecs(t *testing.T) {
+	tests := []struct {
+		name  string
+		specs []Spec
+	}{
+		{"no name", []Spec{{Type: TypeHMAC, APIKey: "k", Secret: "s"}}},
+		{"no api key", []Spec{{Name: "a", Type: TypeHMAC, Secret: "s"}}},
+		{"no secret", []Spec{{Name: "a", Type: TypeHMAC, APIKey: "k"}}},
+		{"unknown type", []Spec{{Name: "a", Type: "rsa", APIKey: "k"}}},
+		{"missing key file", []Spec{{Name: "a", Type: TypeEd25519, APIKey: "k", PrivateKeyPath: "/nonexistent"}}},
+		{"duplicate", []Spec{
+			{Name: "a", Type: TypeHMAC, APIKey: "k", Secret: "s"},
+			{Name: "a", Type: TypeHMAC, APIKey: "k", Secret: "s"},
+		}},
+	}
+	for _, tt := range tests {
+		if _, err := NewStore("", tt.specs...); err == nil {
+			t.Errorf("%s: NewStore succeeded", tt.name)
+		}
+	}
+}
+
+func TestStoreGet(t *testing.T) {
+	store, err := NewStore("b",
+		Spec{Name: "a", Type: TypeHMAC, APIKey: "ka", Secret: "s"},
+		Spec{Name: "b", Type: TypeHMAC, APIKey: "kb", Secret: "s"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key, err := store.Get(""); err != nil || key.Name != "b" {
+		t.Errorf("Get(\"\") = %v, %v; want the default key b", key, err)
+	}
+	if key, err := store.Get("a"); err != nil || key.APIKey != "ka" {
+		t.Errorf("Get(a) = %v, %v", key, err)
+	}
+	if _, err := store.Get("c"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(c) = %v, want ErrNotFound", err)
+	}
+	empty, _ := NewStore("")
+	if _, err := empty.Get(""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get on an empty store = %v, want ErrNotFound", err)
+	}
+}
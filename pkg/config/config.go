@@ -1,8 +1,11 @@
 package config
 
 import (
+	"fmt"
 	"log"
+	"strings"
 	"sync/atomic"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -19,9 +22,179 @@ type HTTP struct {
 	Port int    `mapstructure:"port"`
 }
 
+type Admin struct {
+	Token string `mapstructure:"token"`
+}
+
+type Store struct {
+	Path            string        `mapstructure:"path"`
+	GapScanInterval time.Duration `mapstructure:"gap_scan_interval"`
+	GapAutoRepair   bool          `mapstructure:"gap_auto_repair"`
+}
+
+type Stream struct {
+	Enabled bool `mapstructure:"enabled"`
+	// BaseURL overrides the stream host of the Binance environment.
+	BaseURL                 string   `mapstructure:"base_url"`
+	Streams                 []string `mapstructure:"streams"`
+	MaxStreamsPerConnection int      `mapstructure:"max_streams_per_connection"`
+}
+
+type OrderBook struct {
+	Symbols       []string `mapstructure:"symbols"`
+	SnapshotLimit int      `mapstructure:"snapshot_limit"`
+}
+
+type Push struct {
+	MaxConnections   int      `mapstructure:"max_connections"`
+	MaxSubscriptions int      `mapstructure:"max_subscriptions"`
+	MaxStreams       int      `mapstructure:"max_streams"` // upstream streams opened on demand, all clients together
+	BufferSize       int      `mapstructure:"buffer_size"`
+	AllowedOrigins   []string `mapstructure:"allowed_origins"` // browser origins allowed to open websockets, "*" for any
+}
+
+type RateLimit struct {
+	WeightLimits     map[string]int `mapstructure:"weight_limits"`
+	SAPIWeightLimits map[string]int `mapstructure:"sapi_weight_limits"` // IP weight limits of the /sapi endpoints
+	SafetyMargin     float64        `mapstructure:"safety_margin"`
+	MaxWait          time.Duration  `mapstructure:"max_wait"`
+}
+
+type HTTPClient struct {
+	Timeout          time.Duration `mapstructure:"timeout"`
+	MaxRetries       int           `mapstructure:"max_retries"`
+	RetryBackoff     time.Duration `mapstructure:"retry_backoff"`
+	MaxRetryBackoff  time.Duration `mapstructure:"max_retry_backoff"`
+	BreakerThreshold int           `mapstructure:"breaker_threshold"`
+	BreakerCooldown  time.Duration `mapstructure:"breaker_cooldown"`
+}
+
+// CachePolicy controls how one kind of cached data is kept. Unset fields
+// fall back to the enclosing policy.
+type CachePolicy struct {
+	TTL               time.Duration `mapstructure:"ttl"`
+	RefreshInterval   time.Duration `mapstructure:"refresh_interval"`
+	MaxStaleness      time.Duration `mapstructure:"max_staleness"`
+	ServeStaleOnError *bool         `mapstructure:"serve_stale_on_error"`
+	// Intervals overrides the policy per kline interval. Keys are lower cased
+	// when loaded, so the monthly interval is written as "1mo".
+	Intervals map[string]CachePolicy `mapstructure:"intervals"`
+}
+
+type Redis struct {
+	Addr        string        `mapstructure:"addr"`
+	Password    string        `mapstructure:"password"`
+	DB          int           `mapstructure:"db"`
+	KeyPrefix   string        `mapstructure:"key_prefix"`
+	PoolSize    int           `mapstructure:"pool_size"`
+	DialTimeout time.Duration `mapstructure:"dial_timeout"`
+	IOTimeout   time.Duration `mapstructure:"io_timeout"`
+}
+
+type Cache struct {
+	// Backend is memory, redis or tiered (memory in front of redis).
+	Backend          string                 `mapstructure:"backend"`
+	Redis            Redis                  `mapstructure:"redis"`
+	MaxEntries       int                    `mapstructure:"max_entries"`
+	MaxBytes         int64                  `mapstructure:"max_bytes"`
+	RefreshWorkers   int                    `mapstructure:"refresh_workers"`
+	RefreshQueueSize int                    `mapstructure:"refresh_queue_size"`
+	Default          CachePolicy            `mapstructure:"default"`
+	Policies         map[string]CachePolicy `mapstructure:"policies"`
+}
+
+// Exchange configures an additional market data provider.
+type Exchange struct {
+	Enabled bool   `mapstructure:"enabled"`
+	BaseURL string `mapstructure:"base_url"`
+	// Fixtures replays recorded responses from this directory instead of calling BaseURL.
+	// With Record set, requests without a fixture go to BaseURL and are recorded.
+	Fixtures string `mapstructure:"fixtures"`
+	Record   bool   `mapstructure:"record"`
+}
+
+// FuturesMarket configures a Binance futures market. Its weight limits are
+// tracked apart from the spot ones. BaseURL overrides the hosts of the Binance environment.
+type FuturesMarket struct {
+	Exchange     `mapstructure:",squash"`
+	WeightLimits map[string]int `mapstructure:"weight_limits"`
+}
+
+type Futures struct {
+	USDM  FuturesMarket `mapstructure:"usdm"`
+	COINM FuturesMarket `mapstructure:"coinm"`
+}
+
+// APIKey is a Binance API key. HMAC keys use Secret, Ed25519 keys the PKCS#8
+// PEM file at PrivateKeyPath. Values may reference environment variables as ${NAME}.
+type APIKey struct {
+	Name           string `mapstructure:"name"`
+	Type           string `mapstructure:"type"`
+	APIKey         string `mapstructure:"api_key"`
+	Secret         string `mapstructure:"secret"`
+	PrivateKeyPath string `mapstructure:"private_key_path"`
+}
+
+// BinanceAuth configures the keys of authenticated Binance endpoints and how
+// signed requests are timestamped.
+type BinanceAuth struct {
+	DefaultKey       string        `mapstructure:"default_key"`
+	RecvWindow       time.Duration `mapstructure:"recv_window"`
+	TimeSyncInterval time.Duration `mapstructure:"time_sync_interval"`
+	Keys             []APIKey      `mapstructure:"keys"`
+}
+
+// BinanceEnvironment holds the base URLs of one Binance deployment. Each REST
+// API lists equivalent hosts, tried in order while the earlier ones are unhealthy.
+type BinanceEnvironment struct {
+	Spot   []string `mapstructure:"spot"`
+	USDM   []string `mapstructure:"usdm"`
+	COINM  []string `mapstructure:"coinm"`
+	Stream string   `mapstructure:"stream"`
+}
+
+// Binance selects the Binance environment, such as prod, testnet or a local mock.
+type Binance struct {
+	Environment  string                        `mapstructure:"environment"`
+	Environments map[string]BinanceEnvironment `mapstructure:"environments"`
+	// UnhealthyCooldown is how long a failing host is skipped before it is tried again.
+	UnhealthyCooldown time.Duration `mapstructure:"unhealthy_cooldown"`
+}
+
+// Env returns the selected environment. Its spot hosts are required.
+func (b Binance) Env() (BinanceEnvironment, error) {
+	env, ok := b.Environments[strings.ToLower(b.Environment)]
+	if !ok {
+		return BinanceEnvironment{}, fmt.Errorf("unknown binance environment %q", b.Environment)
+	}
+	if len(env.Spot) == 0 {
+		return BinanceEnvironment{}, fmt.Errorf("binance environment %q has no spot hosts", b.Environment)
+	}
+	return env, nil
+}
+
+type Analytics struct {
+	// FundingWindows are the default rolling funding windows, e.g. "1d" or "7d".
+	FundingWindows []string `mapstructure:"funding_windows"`
+}
+
 type Config struct {
-	App  App  `mapstructure:"app"`
-	HTTP HTTP `mapstructure:"http"`
+	App         App         `mapstructure:"app"`
+	HTTP        HTTP        `mapstructure:"http"`
+	Admin       Admin       `mapstructure:"admin"`
+	Store       Store       `mapstructure:"store"`
+	Stream      Stream      `mapstructure:"stream"`
+	OrderBook   OrderBook   `mapstructure:"order_book"`
+	Push        Push        `mapstructure:"push"`
+	RateLimit   RateLimit   `mapstructure:"rate_limit"`
+	HTTPClient  HTTPClient  `mapstructure:"http_client"`
+	Cache       Cache       `mapstructure:"cache"`
+	Binance     Binance     `mapstructure:"binance"`
+	Coinbase    Exchange    `mapstructure:"coinbase"`
+	Kraken      Exchange    `mapstructure:"kraken"`
+	Futures     Futures     `mapstructure:"futures"`
+	Analytics   Analytics   `mapstructure:"analytics"`
+	BinanceAuth BinanceAuth `mapstructure:"binance_auth"`
 }
 
 // Global config variable
@@ -35,7 +208,8 @@ func InitConfig(path string) {
 		log.Fatalf("viper.ReadInConfig() has error: %v", err)
 	}
 
-	// Optionally override with env variables
+	// Optionally override with env variables, e.g. BINANCE_ENVIRONMENT=testnet
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	viper.AutomaticEnv()
 
 	var cfg *Config
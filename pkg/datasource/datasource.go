@@ -0,0 +1,71 @@
+package datasource
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Where the data of a response came from, from freshest to oldest.
+const (
+	Upstream = "upstream"
+	Cache    = "cache"
+	Stale    = "stale"
+)
+
+var rank = map[string]int{Upstream: 1, Cache: 2, Stale: 3}
+
+type contextKey struct{}
+
+// Info collects the origin of the data served for one request.
+// A response assembled from several fetches reports the least fresh one.
+type Info struct {
+	lock      sync.Mutex
+	source    string
+	fetchedAt time.Time
+}
+
+// NewContext returns a context carrying an empty Info.
+func NewContext(ctx context.Context) (context.Context, *Info) {
+	info := &Info{}
+	return context.WithValue(ctx, contextKey{}, info), info
+}
+
+// FromContext returns the Info of ctx, or nil if there is none.
+func FromContext(ctx context.Context) *Info {
+	info, _ := ctx.Value(contextKey{}).(*Info)
+	return info
+}
+
+// Detach returns a context whose fetches are not recorded, for lookups that
+// only support a response, e.g. resolving a symbol name.
+func Detach(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextKey{}, (*Info)(nil))
+}
+
+// Record notes that data from source, fetched at fetchedAt, went into the response of ctx.
+// It does nothing if ctx carries no Info.
+func Record(ctx context.Context, source string, fetchedAt time.Time) {
+	if info := FromContext(ctx); info != nil {
+		info.Record(source, fetchedAt)
+	}
+}
+
+// Record keeps the least fresh source and the oldest fetch time seen so far.
+func (i *Info) Record(source string, fetchedAt time.Time) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if rank[source] > rank[i.source] {
+		i.source = source
+	}
+	if i.fetchedAt.IsZero() || fetchedAt.Before(i.fetchedAt) {
+		i.fetchedAt = fetchedAt
+	}
+}
+
+// Get returns what was recorded. ok is false if nothing was.
+func (i *Info) Get() (source string, fetchedAt time.Time, ok bool) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	return i.source, i.fetchedAt, i.source != ""
+}
@@ -0,0 +1,31 @@
+package datasource
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRecord(t *testing.T) {
+	// Recording without an Info is a no-op.
+	Record(context.Background(), Upstream, time.Now())
+
+	ctx, info := NewContext(context.Background())
+	if _, _, ok := info.Get(); ok {
+		t.Fatal("empty Info reports a source")
+	}
+	older := time.Now().Add(-time.Minute)
+	newer := time.Now()
+	Record(ctx, Cache, older)
+	Record(ctx, Upstream, newer)
+	if source, fetchedAt, ok := info.Get(); !ok || source != Cache || !fetchedAt.Equal(older) {
+		t.Errorf("Get = %q, %v, %v; want the cache fetch", source, fetchedAt, ok)
+	}
+	Record(ctx, Stale, newer)
+	if source, fetchedAt, _ := info.Get(); source != Stale || !fetchedAt.Equal(older) {
+		t.Errorf("Get = %q, %v; want stale with the oldest fetch time", source, fetchedAt)
+	}
+	if FromContext(ctx) != info {
+		t.Error("FromContext did not return the Info of NewContext")
+	}
+}
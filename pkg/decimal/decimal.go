@@ -0,0 +1,158 @@
+package decimal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// Decimal is an arbitrary-precision decimal number that keeps the exact textual
+// representation it was parsed from, so prices and quantities survive a round
+// trip without float rounding.
+type Decimal struct {
+	value string
+}
+
+// Zero is the decimal value 0.
+var Zero = Decimal{value: "0"}
+
+// plainDecimal is the only notation Parse accepts: digits on both sides of an
+// optional point, with an optional leading minus.
+var plainDecimal = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
+// Parse parses a plain decimal string such as "27123.45000000".
+// Returns an error if the input is empty or not a valid decimal.
+func Parse(s string) (Decimal, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Decimal{}, errors.New("empty decimal")
+	}
+	if !plainDecimal.MatchString(s) {
+		return Decimal{}, fmt.Errorf("invalid decimal %q: only plain notation is supported", s)
+	}
+	return Decimal{value: s}, nil
+}
+
+// MustParse is like Parse but panics if the input is invalid.
+func MustParse(s string) Decimal {
+	d, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// NewFromRat converts a rational number to a decimal rounded to prec fractional digits.
+func NewFromRat(r *big.Rat, prec int) Decimal {
+	return Decimal{value: r.FloatString(prec)}
+}
+
+// NewFromFloat converts a float64 to a decimal rounded to prec fractional digits.
+func NewFromFloat(f float64, prec int) Decimal {
+	r := new(big.Rat)
+	if r.SetFloat64(f) == nil {
+		return Zero
+	}
+	return NewFromRat(r, prec)
+}
+
+// String returns the decimal in its original textual form.
+func (d Decimal) String() string {
+	if d.value == "" {
+		return "0"
+	}
+	return d.value
+}
+
+// Rat returns the decimal as a big.Rat.
+func (d Decimal) Rat() *big.Rat {
+	r, ok := new(big.Rat).SetString(d.String())
+	if !ok {
+		return new(big.Rat)
+	}
+	return r
+}
+
+// Float64 returns the nearest float64 value of the decimal.
+func (d Decimal) Float64() float64 {
+	f, _ := d.Rat().Float64()
+	return f
+}
+
+// Cmp compares d and other and returns -1, 0 or +1.
+func (d Decimal) Cmp(other Decimal) int {
+	return d.Rat().Cmp(other.Rat())
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of d.
+func (d Decimal) Sign() int {
+	return d.Rat().Sign()
+}
+
+// Add returns d + other.
+func (d Decimal) Add(other Decimal) Decimal {
+	return fromRat(new(big.Rat).Add(d.Rat(), other.Rat()), d, other)
+}
+
+// Sub returns d - other.
+func (d Decimal) Sub(other Decimal) Decimal {
+	return fromRat(new(big.Rat).Sub(d.Rat(), other.Rat()), d, other)
+}
+
+// Mul returns d * other.
+func (d Decimal) Mul(other Decimal) Decimal {
+	return NewFromRat(new(big.Rat).Mul(d.Rat(), other.Rat()), d.Scale()+other.Scale())
+}
+
+// Div returns d / other rounded to prec fractional digits.
+// Returns an error when dividing by zero.
+func (d Decimal) Div(other Decimal, prec int) (Decimal, error) {
+	if other.Sign() == 0 {
+		return Decimal{}, errors.New("division by zero")
+	}
+	return NewFromRat(new(big.Rat).Quo(d.Rat(), other.Rat()), prec), nil
+}
+
+// Scale returns the number of fractional digits in the textual form of d.
+func (d Decimal) Scale() int {
+	if i := strings.IndexByte(d.value, '.'); i >= 0 {
+		return len(d.value) - i - 1
+	}
+	return 0
+}
+
+// Equal reports whether d and other represent the same number.
+func (d Decimal) Equal(other Decimal) bool {
+	return d.Cmp(other) == 0
+}
+
+// MarshalJSON encodes the decimal as a JSON string to preserve precision.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON accepts either a JSON string or a JSON number.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return errors.New("decimal must not be null")
+	}
+	if strings.HasPrefix(s, `"`) {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	}
+	parsed, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+func fromRat(r *big.Rat, a, b Decimal) Decimal {
+	return NewFromRat(r, max(a.Scale(), b.Scale()))
+}
@@ -0,0 +1,84 @@
+package decimal
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	accepted := map[string]string{
+		"0":               "0",
+		"27123.45000000":  "27123.45000000",
+		"-0.00012":        "-0.00012",
+		" 1.5 ":           "1.5",
+		"000123":          "000123",
+		"1000000000000.1": "1000000000000.1",
+	}
+	for in, want := range accepted {
+		d, err := Parse(in)
+		if err != nil {
+			t.Errorf("Parse(%q) = %v", in, err)
+			continue
+		}
+		if d.String() != want {
+			t.Errorf("Parse(%q) = %s, want %s", in, d, want)
+		}
+	}
+
+	rejected := []string{
+		"", " ", "abc", "1.2.3", "1/3", "1e5", "1E-8",
+		"0x1F", "0b101", "0o17", "1_000", "+5", ".5", "5.", "-", "-.5", "1,5", "NaN", "Inf",
+	}
+	for _, in := range rejected {
+		if d, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q) = %s, want an error", in, d)
+		}
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	a, b := MustParse("1.10"), MustParse("0.205")
+	tests := []struct {
+		name string
+		got  Decimal
+		want string
+	}{
+		{"add", a.Add(b), "1.305"},
+		{"sub", a.Sub(b), "0.895"},
+		{"mul", a.Mul(b), "0.22550"},
+		{"from rat", NewFromFloat(0.1, 3), "0.100"},
+	}
+	for _, tt := range tests {
+		if tt.got.String() != tt.want {
+			t.Errorf("%s = %s, want %s", tt.name, tt.got, tt.want)
+		}
+	}
+	if q, err := a.Div(b, 4); err != nil || q.String() != "5.3659" {
+		t.Errorf("div = %s, %v", q, err)
+	}
+	if _, err := a.Div(Zero, 4); err == nil {
+		t.Error("division by zero succeeded")
+	}
+	if !MustParse("1.50").Equal(MustParse("1.5")) || a.Cmp(b) != 1 || MustParse("-3").Sign() != -1 {
+		t.Error("comparison of equal values with different scales failed")
+	}
+}
+
+func TestJSON(t *testing.T) {
+	var v struct {
+		Price Decimal `json:"price"`
+		Qty   Decimal `json:"qty"`
+	}
+	if err := json.Unmarshal([]byte(`{"price":"0.10000000","qty":12.5}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	out, _ := json.Marshal(v)
+	if string(out) != `{"price":"0.10000000","qty":"12.5"}` {
+		t.Errorf("round trip = %s", out)
+	}
+	for _, in := range []string{`{"price":null}`, `{"price":"0x10"}`, `{"price":1e3}`, `{"price":true}`} {
+		if err := json.Unmarshal([]byte(in), &v); err == nil {
+			t.Errorf("Unmarshal(%s) succeeded", in)
+		}
+	}
+}
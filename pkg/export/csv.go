@@ -0,0 +1,81 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+type csvWriter struct {
+	out         io.Writer
+	w           *csv.Writer
+	schema      []Column
+	wroteHeader bool
+}
+
+// NewCSVWriter creates a writer producing RFC 4180 CSV with a header row.
+func NewCSVWriter(w io.Writer, schema []Column) Writer {
+	return &csvWriter{out: w, w: csv.NewWriter(w), schema: schema}
+}
+
+func (c *csvWriter) writeHeader() error {
+	if c.wroteHeader {
+		return nil
+	}
+	c.wroteHeader = true
+	header := make([]string, len(c.schema))
+	for i, col := range c.schema {
+		header[i] = col.Name
+	}
+	return c.w.Write(header)
+}
+
+func (c *csvWriter) Write(rows [][]any) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	record := make([]string, len(c.schema))
+	for _, row := range rows {
+		if err := checkRow(c.schema, row); err != nil {
+			return err
+		}
+		for i, v := range row {
+			switch v := v.(type) {
+			case int64:
+				record[i] = strconv.FormatInt(v, 10)
+			case string:
+				record[i] = v
+			case bool:
+				record[i] = strconv.FormatBool(v)
+			}
+		}
+		if err := c.w.Write(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *csvWriter) Flush() error {
+	c.w.Flush()
+	if err := c.w.Error(); err != nil {
+		return err
+	}
+	flushUnderlying(c.out)
+	return nil
+}
+
+func (c *csvWriter) Close() error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	return c.Flush()
+}
+
+func (c *csvWriter) ContentType() string {
+	return "text/csv; charset=utf-8"
+}
+
+func (c *csvWriter) Extension() string {
+	return "csv"
+}
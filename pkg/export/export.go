@@ -0,0 +1,87 @@
+package export
+
+import (
+	"fmt"
+	"io"
+)
+
+// ColumnType is the physical type of an exported column.
+type ColumnType int
+
+const (
+	Int64 ColumnType = iota
+	String
+	Bool
+)
+
+// Column describes one column of an export schema.
+type Column struct {
+	Name string
+	Type ColumnType
+}
+
+// Writer writes rows of a fixed schema to an underlying stream. Row values
+// must be int64, string or bool according to the column types.
+type Writer interface {
+	// Write encodes a batch of rows. Writers may buffer until Flush.
+	Write(rows [][]any) error
+	// Flush pushes buffered rows to the underlying stream. Formats storing
+	// rows in groups, like Parquet, hold back a group until it is complete.
+	Flush() error
+	// Close flushes and writes any trailer the format requires.
+	Close() error
+	// ContentType returns the MIME type of the format.
+	ContentType() string
+	// Extension returns the file name extension of the format.
+	Extension() string
+}
+
+// Format names accepted by NewWriter.
+const (
+	FormatCSV     = "csv"
+	FormatNDJSON  = "ndjson"
+	FormatParquet = "parquet"
+)
+
+// NewWriter creates a writer for the named format.
+func NewWriter(format string, w io.Writer, schema []Column) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return NewCSVWriter(w, schema), nil
+	case FormatNDJSON:
+		return NewNDJSONWriter(w, schema), nil
+	case FormatParquet:
+		return NewParquetWriter(w, schema), nil
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+func checkRow(schema []Column, row []any) error {
+	if len(row) != len(schema) {
+		return fmt.Errorf("row has %d values, schema has %d columns", len(row), len(schema))
+	}
+	for i, col := range schema {
+		ok := false
+		switch col.Type {
+		case Int64:
+			_, ok = row[i].(int64)
+		case String:
+			_, ok = row[i].(string)
+		case Bool:
+			_, ok = row[i].(bool)
+		}
+		if !ok {
+			return fmt.Errorf("column %s: unexpected value type %T", col.Name, row[i])
+		}
+	}
+	return nil
+}
+
+// flushUnderlying pushes data through writers that buffer themselves, such as
+// http.ResponseWriter, so each flushed batch reaches the client as a chunk.
+func flushUnderlying(w io.Writer) {
+	if f, ok := w.(interface{ Flush() }); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,77 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+type ndjsonWriter struct {
+	out    io.Writer
+	w      *bufio.Writer
+	schema []Column
+	keys   [][]byte
+}
+
+// NewNDJSONWriter creates a writer producing one JSON object per line with
+// keys in schema order.
+func NewNDJSONWriter(w io.Writer, schema []Column) Writer {
+	keys := make([][]byte, len(schema))
+	for i, col := range schema {
+		keys[i], _ = json.Marshal(col.Name)
+	}
+	return &ndjsonWriter{out: w, w: bufio.NewWriter(w), schema: schema, keys: keys}
+}
+
+func (n *ndjsonWriter) Write(rows [][]any) error {
+	for _, row := range rows {
+		if err := checkRow(n.schema, row); err != nil {
+			return err
+		}
+		n.w.WriteByte('{')
+		for i, v := range row {
+			if i > 0 {
+				n.w.WriteByte(',')
+			}
+			n.w.Write(n.keys[i])
+			n.w.WriteByte(':')
+			switch v := v.(type) {
+			case int64:
+				n.w.WriteString(strconv.FormatInt(v, 10))
+			case string:
+				b, err := json.Marshal(v)
+				if err != nil {
+					return err
+				}
+				n.w.Write(b)
+			case bool:
+				n.w.WriteString(strconv.FormatBool(v))
+			}
+		}
+		if _, err := n.w.WriteString("}\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *ndjsonWriter) Flush() error {
+	if err := n.w.Flush(); err != nil {
+		return err
+	}
+	flushUnderlying(n.out)
+	return nil
+}
+
+func (n *ndjsonWriter) Close() error {
+	return n.Flush()
+}
+
+func (n *ndjsonWriter) ContentType() string {
+	return "application/x-ndjson"
+}
+
+func (n *ndjsonWriter) Extension() string {
+	return "ndjson"
+}
@@ -0,0 +1,270 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+// Parquet enum values used by this writer (see parquet.thrift).
+const (
+	parquetTypeBoolean   = 0
+	parquetTypeInt64     = 2
+	parquetTypeByteArray = 6
+
+	parquetRequired = 0
+
+	parquetConvertedUTF8 = 0
+
+	parquetEncodingPlain = 0
+	parquetEncodingRLE   = 3
+
+	parquetCodecUncompressed = 0
+
+	parquetPageData = 0
+)
+
+var parquetMagic = []byte("PAR1")
+
+// parquetRowGroupSize is the encoded size at which buffered rows are written
+// out as a row group, so exports are not split into many tiny groups.
+const parquetRowGroupSize = 8 << 20
+
+type parquetColumnChunk struct {
+	offset     int64
+	size       int64
+	numValues  int64
+	columnType ColumnType
+	name       string
+}
+
+type parquetRowGroup struct {
+	columns []parquetColumnChunk
+	numRows int64
+	size    int64
+}
+
+// parquetWriter writes an uncompressed, PLAIN-encoded Parquet file with only
+// required columns. Rows are encoded into per-column buffers as they are
+// written and go out as one row group once parquetRowGroupSize is reached,
+// so at most one row group and the footer are held in memory.
+type parquetWriter struct {
+	w         io.Writer
+	schema    []Column
+	offset    int64
+	rowGroups []parquetRowGroup
+	numRows   int64
+	err       error
+
+	columns     []bytes.Buffer // encoded values of the open row group
+	pendingRows int
+}
+
+// NewParquetWriter creates a writer producing a Parquet file. Int64 columns
+// map to INT64, String columns to UTF8 BYTE_ARRAY and Bool columns to BOOLEAN.
+func NewParquetWriter(w io.Writer, schema []Column) Writer {
+	return &parquetWriter{w: w, schema: schema, columns: make([]bytes.Buffer, len(schema))}
+}
+
+func (p *parquetWriter) write(b []byte) {
+	if p.err != nil {
+		return
+	}
+	n, err := p.w.Write(b)
+	p.offset += int64(n)
+	p.err = err
+}
+
+func (p *parquetWriter) Write(rows [][]any) error {
+	if len(rows) == 0 {
+		return p.err
+	}
+	for _, row := range rows {
+		if err := checkRow(p.schema, row); err != nil {
+			return err
+		}
+	}
+	for i, col := range p.schema {
+		encodePlain(&p.columns[i], col.Type, rows, i, p.pendingRows)
+	}
+	p.pendingRows += len(rows)
+	if p.pendingSize() >= parquetRowGroupSize {
+		p.writeRowGroup()
+	}
+	return p.err
+}
+
+func (p *parquetWriter) pendingSize() int {
+	size := 0
+	for i := range p.columns {
+		size += p.columns[i].Len()
+	}
+	return size
+}
+
+// writeRowGroup writes the buffered rows as one row group, a single data page per column.
+func (p *parquetWriter) writeRowGroup() {
+	if p.pendingRows == 0 {
+		return
+	}
+	if p.offset == 0 {
+		p.write(parquetMagic)
+	}
+	group := parquetRowGroup{numRows: int64(p.pendingRows)}
+	for i, col := range p.schema {
+		data := p.columns[i].Bytes()
+		header := pageHeader(len(data), p.pendingRows)
+
+		chunk := parquetColumnChunk{
+			offset:     p.offset,
+			size:       int64(len(header) + len(data)),
+			numValues:  int64(p.pendingRows),
+			columnType: col.Type,
+			name:       col.Name,
+		}
+		p.write(header)
+		p.write(data)
+		p.columns[i].Reset()
+		group.columns = append(group.columns, chunk)
+		group.size += chunk.size
+	}
+	p.rowGroups = append(p.rowGroups, group)
+	p.numRows += group.numRows
+	p.pendingRows = 0
+}
+
+// encodePlain appends column col of rows to buf. Booleans are bit-packed, so
+// their position continues from the first rows already in buf.
+func encodePlain(buf *bytes.Buffer, typ ColumnType, rows [][]any, col, first int) {
+	switch typ {
+	case Int64:
+		for _, row := range rows {
+			buf.Write(binary.LittleEndian.AppendUint64(nil, uint64(row[col].(int64))))
+		}
+	case String:
+		for _, row := range rows {
+			s := row[col].(string)
+			buf.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(s))))
+			buf.WriteString(s)
+		}
+	case Bool:
+		for i, row := range rows {
+			bit := first + i
+			if bit%8 == 0 {
+				buf.WriteByte(0)
+			}
+			if row[col].(bool) {
+				buf.Bytes()[bit/8] |= 1 << (bit % 8)
+			}
+		}
+	}
+}
+
+func pageHeader(size, numValues int) []byte {
+	t := &thriftWriter{}
+	t.structBegin()
+	t.i32Field(1, parquetPageData)
+	t.i32Field(2, int32(size))
+	t.i32Field(3, int32(size))
+	t.structField(5)
+	t.i32Field(1, int32(numValues))
+	t.i32Field(2, parquetEncodingPlain)
+	t.i32Field(3, parquetEncodingRLE)
+	t.i32Field(4, parquetEncodingRLE)
+	t.structEnd()
+	t.structEnd()
+	return t.buf.Bytes()
+}
+
+func physicalType(typ ColumnType) int32 {
+	switch typ {
+	case Int64:
+		return parquetTypeInt64
+	case Bool:
+		return parquetTypeBoolean
+	default:
+		return parquetTypeByteArray
+	}
+}
+
+func (p *parquetWriter) footer() []byte {
+	t := &thriftWriter{}
+	t.structBegin()
+	t.i32Field(1, 1)
+
+	t.listField(2, thriftStruct, len(p.schema)+1)
+	t.structBegin()
+	t.stringField(4, "schema")
+	t.i32Field(5, int32(len(p.schema)))
+	t.structEnd()
+	for _, col := range p.schema {
+		t.structBegin()
+		t.i32Field(1, physicalType(col.Type))
+		t.i32Field(3, parquetRequired)
+		t.stringField(4, col.Name)
+		if col.Type == String {
+			t.i32Field(6, parquetConvertedUTF8)
+		}
+		t.structEnd()
+	}
+
+	t.i64Field(3, p.numRows)
+
+	t.listField(4, thriftStruct, len(p.rowGroups))
+	for _, group := range p.rowGroups {
+		t.structBegin()
+		t.listField(1, thriftStruct, len(group.columns))
+		for _, chunk := range group.columns {
+			t.structBegin()
+			t.i64Field(2, chunk.offset)
+			t.structField(3)
+			t.i32Field(1, physicalType(chunk.columnType))
+			t.listField(2, thriftI32, 2)
+			t.i32(parquetEncodingPlain)
+			t.i32(parquetEncodingRLE)
+			t.listField(3, thriftBinary, 1)
+			t.binary(chunk.name)
+			t.i32Field(4, parquetCodecUncompressed)
+			t.i64Field(5, chunk.numValues)
+			t.i64Field(6, chunk.size)
+			t.i64Field(7, chunk.size)
+			t.i64Field(9, chunk.offset)
+			t.structEnd()
+			t.structEnd()
+		}
+		t.i64Field(2, group.size)
+		t.i64Field(3, group.numRows)
+		t.structEnd()
+	}
+
+	t.stringField(6, "go-finance-dataset")
+	t.structEnd()
+	return t.buf.Bytes()
+}
+
+func (p *parquetWriter) Flush() error {
+	if p.err == nil {
+		flushUnderlying(p.w)
+	}
+	return p.err
+}
+
+func (p *parquetWriter) Close() error {
+	p.writeRowGroup()
+	if p.offset == 0 {
+		p.write(parquetMagic)
+	}
+	footer := p.footer()
+	p.write(footer)
+	p.write(binary.LittleEndian.AppendUint32(nil, uint32(len(footer))))
+	p.write(parquetMagic)
+	return p.Flush()
+}
+
+func (p *parquetWriter) ContentType() string {
+	return "application/vnd.apache.parquet"
+}
+
+func (p *parquetWriter) Extension() string {
+	return "parquet"
+}
@@ -0,0 +1,265 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// thriftReader decodes the Thrift compact protocol into generic values:
+// structs become map[int16]any keyed by field id, lists []any.
+type thriftReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *thriftReader) byte() byte {
+	b := r.data[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *thriftReader) varint() uint64 {
+	v, n := binary.Uvarint(r.data[r.pos:])
+	if n <= 0 {
+		panic(fmt.Sprintf("bad varint at %d", r.pos))
+	}
+	r.pos += n
+	return v
+}
+
+func (r *thriftReader) zigzag() int64 {
+	v := r.varint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *thriftReader) value(typ byte) any {
+	switch typ {
+	case 1:
+		return true
+	case 2:
+		return false
+	case thriftI32, thriftI64:
+		return r.zigzag()
+	case thriftBinary:
+		n := int(r.varint())
+		s := string(r.data[r.pos : r.pos+n])
+		r.pos += n
+		return s
+	case thriftList:
+		header := r.byte()
+		size := int(header >> 4)
+		if size == 15 {
+			size = int(r.varint())
+		}
+		out := make([]any, size)
+		for i := range out {
+			out[i] = r.value(header & 0x0F)
+		}
+		return out
+	case thriftStruct:
+		return r.readStruct()
+	}
+	panic(fmt.Sprintf("unsupported thrift type %d at %d", typ, r.pos))
+}
+
+func (r *thriftReader) readStruct() map[int16]any {
+	out := make(map[int16]any)
+	var last int16
+	for {
+		header := r.byte()
+		if header == 0 {
+			return out
+		}
+		id := last + int16(header>>4)
+		if header>>4 == 0 {
+			id = int16(r.zigzag())
+		}
+		out[id] = r.value(header & 0x0F)
+		last = id
+	}
+}
+
+// parquetFile is a decoded Parquet file: its footer and the values of every column.
+type parquetFile struct {
+	meta      map[int16]any
+	rowGroups []int64
+	columns   [][]any
+}
+
+func readParquet(t *testing.T, data []byte, schema []Column) parquetFile {
+	t.Helper()
+	if !bytes.HasPrefix(data, parquetMagic) || !bytes.HasSuffix(data, parquetMagic) {
+		t.Fatal("missing PAR1 magic")
+	}
+	footerLen := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footer := &thriftReader{data: data[len(data)-8-footerLen : len(data)-8]}
+	file := parquetFile{meta: footer.readStruct(), columns: make([][]any, len(schema))}
+	if footer.pos != footerLen {
+		t.Fatalf("footer decoded %d of %d bytes", footer.pos, footerLen)
+	}
+
+	elements := file.meta[2].([]any)
+	if len(elements) != len(schema)+1 || elements[0].(map[int16]any)[5] != int64(len(schema)) {
+		t.Fatalf("schema = %v", elements)
+	}
+	for i, col := range schema {
+		el := elements[i+1].(map[int16]any)
+		if el[4] != col.Name || el[1] != int64(physicalType(col.Type)) || el[3] != int64(parquetRequired) {
+			t.Fatalf("schema element %d = %v, want column %s", i, el, col.Name)
+		}
+	}
+
+	for _, g := range file.meta[4].([]any) {
+		group := g.(map[int16]any)
+		numRows := group[3].(int64)
+		file.rowGroups = append(file.rowGroups, numRows)
+		var size int64
+		for i, c := range group[1].([]any) {
+			meta := c.(map[int16]any)[3].(map[int16]any)
+			offset, chunkSize := meta[9].(int64), meta[6].(int64)
+			size += chunkSize
+			if meta[5] != numRows || meta[3].([]any)[0] != schema[i].Name {
+				t.Fatalf("column chunk %v does not match its row group of %d rows", meta, numRows)
+			}
+			page := &thriftReader{data: data[offset : offset+chunkSize]}
+			header := page.readStruct()
+			values := data[offset+int64(page.pos) : offset+chunkSize]
+			if header[2] != int64(len(values)) || header[5].(map[int16]any)[1] != numRows {
+				t.Fatalf("page header %v does not match %d bytes of %d values", header, len(values), numRows)
+			}
+			file.columns[i] = append(file.columns[i], decodePlain(t, schema[i].Type, values, int(numRows))...)
+		}
+		if group[2] != size {
+			t.Fatalf("row group size = %v, want the sum of its chunks %d", group[2], size)
+		}
+	}
+	return file
+}
+
+func decodePlain(t *testing.T, typ ColumnType, data []byte, n int) []any {
+	t.Helper()
+	out := make([]any, 0, n)
+	pos := 0
+	for i := range n {
+		switch typ {
+		case Int64:
+			out = append(out, int64(binary.LittleEndian.Uint64(data[pos:])))
+			pos += 8
+		case String:
+			size := int(binary.LittleEndian.Uint32(data[pos:]))
+			out = append(out, string(data[pos+4:pos+4+size]))
+			pos += 4 + size
+		case Bool:
+			out = append(out, data[i/8]&(1<<(i%8)) != 0)
+			pos = (i + 8) / 8
+		}
+	}
+	if pos != len(data) {
+		t.Fatalf("decoded %d of %d bytes", pos, len(data))
+	}
+	return out
+}
+
+var testSchema = []Column{
+	{Name: "id", Type: Int64},
+	{Name: "price", Type: String},
+	{Name: "is_buyer_maker", Type: Bool},
+}
+
+func testRow(i int, pad string) []any {
+	return []any{int64(i) - 5, fmt.Sprintf("%d.%s", i, pad), i%3 == 0}
+}
+
+func TestParquetRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewParquetWriter(&buf, testSchema)
+	var rows [][]any
+	// Small batches flushed one by one, as the export service writes pages.
+	for batch := range 100 {
+		page := make([][]any, 0, 7)
+		for i := range 7 {
+			page = append(page, testRow(batch*7+i, ""))
+		}
+		if err := w.Write(page); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+		rows = append(rows, page...)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	file := readParquet(t, buf.Bytes(), testSchema)
+	if file.meta[1] != int64(1) || file.meta[3] != int64(len(rows)) {
+		t.Fatalf("version %v with %v rows, want 1 with %d", file.meta[1], file.meta[3], len(rows))
+	}
+	if len(file.rowGroups) != 1 {
+		t.Fatalf("%d row groups, want the small batches combined into 1", len(file.rowGroups))
+	}
+	for c := range testSchema {
+		for r, row := range rows {
+			if file.columns[c][r] != row[c] {
+				t.Fatalf("row %d column %s = %v, want %v", r, testSchema[c].Name, file.columns[c][r], row[c])
+			}
+		}
+	}
+}
+
+func TestParquetSplitsLargeExports(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewParquetWriter(&buf, testSchema)
+	pad := strings.Repeat("0", 1000)
+	rows := 0
+	for rows*len(pad) < 5*parquetRowGroupSize/2 {
+		page := make([][]any, 0, 1000)
+		for i := range 1000 {
+			page = append(page, testRow(rows+i, pad))
+		}
+		if err := w.Write(page); err != nil {
+			t.Fatal(err)
+		}
+		rows += len(page)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	file := readParquet(t, buf.Bytes(), testSchema)
+	if len(file.rowGroups) != 3 {
+		t.Fatalf("%d row groups, want 3", len(file.rowGroups))
+	}
+	var total int64
+	for _, n := range file.rowGroups {
+		total += n
+	}
+	if total != int64(rows) || len(file.columns[0]) != rows {
+		t.Fatalf("%d rows in row groups and %d decoded, want %d", total, len(file.columns[0]), rows)
+	}
+	if last := file.columns[0][rows-1]; last != int64(rows-1)-5 {
+		t.Fatalf("last id = %v, want %d", last, rows-6)
+	}
+	// Booleans stay bit-aligned across the batches of a row group.
+	for r := range rows {
+		if file.columns[2][r] != (r%3 == 0) {
+			t.Fatalf("row %d is_buyer_maker = %v", r, file.columns[2][r])
+		}
+	}
+}
+
+func TestParquetEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewParquetWriter(&buf, testSchema)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	file := readParquet(t, buf.Bytes(), testSchema)
+	if file.meta[3] != int64(0) || len(file.rowGroups) != 0 {
+		t.Fatalf("empty file has %v rows in %d row groups", file.meta[3], len(file.rowGroups))
+	}
+}
@@ -0,0 +1,181 @@
+package fixture
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/bits"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Server is an HTTP stand-in for a REST API that replays recorded responses.
+//
+// A GET for /0/public/Depth?pair=XXBTZEUR&count=10 is answered with the first file found of
+//
+//	<dir>/0/public/Depth@count=10&pair=XXBTZEUR.json
+//	<dir>/0/public/Depth@count=_&pair=XXBTZEUR.json
+//	<dir>/0/public/Depth@count=10&pair=_.json
+//	<dir>/0/public/Depth@count=_&pair=_.json
+//	<dir>/0/public/Depth.json
+//
+// Query parameters are sorted by key, and a "_" value matches any value. Requests
+// without a fixture get a 404, unless an upstream is set: then they are proxied to
+// it and the response is recorded under the query-specific name for the next run.
+type Server struct {
+	dir      string
+	upstream string
+	client   *http.Client
+
+	lock     sync.Mutex
+	listener net.Listener
+	server   *http.Server
+}
+
+// NewServer replays the fixtures in dir. A non-empty upstream base URL enables recording.
+func NewServer(dir, upstream string) *Server {
+	return &Server{
+		dir:      dir,
+		upstream: strings.TrimSuffix(upstream, "/"),
+		client:   &http.Client{},
+	}
+}
+
+// ListenAndServe listens on addr, e.g. "127.0.0.1:0", and serves in the
+// background. It returns the base URL to point clients at.
+func (s *Server) ListenAndServe(addr string) (string, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return "", err
+	}
+	s.lock.Lock()
+	s.listener = listener
+	s.server = &http.Server{Handler: s}
+	s.lock.Unlock()
+	go s.server.Serve(listener)
+	return "http://" + listener.Addr().String(), nil
+}
+
+// Close stops the server.
+func (s *Server) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.server == nil {
+		return nil
+	}
+	return s.server.Close()
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "fixtures only cover GET requests", http.StatusMethodNotAllowed)
+		return
+	}
+	names := fixtureNames(r.URL)
+	for _, name := range names {
+		body, err := os.ReadFile(filepath.Join(s.dir, filepath.FromSlash(name)))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+		return
+	}
+	if s.upstream == "" {
+		http.Error(w, fmt.Sprintf("no fixture for %s", r.URL.RequestURI()), http.StatusNotFound)
+		return
+	}
+	s.record(w, r, names[0])
+}
+
+// record proxies r to the upstream and saves a successful response as name.
+func (s *Server) record(w http.ResponseWriter, r *http.Request, name string) {
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, s.upstream+r.URL.RequestURI(), nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	if resp.StatusCode == http.StatusOK {
+		file := filepath.Join(s.dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err == nil {
+			err = os.WriteFile(file, body, 0o644)
+		}
+		if err != nil {
+			http.Error(w, fmt.Sprintf("error recording fixture: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", resp.Header.Get("Content-Type"))
+	w.WriteHeader(resp.StatusCode)
+	w.Write(body)
+}
+
+// anyValue is the query value of a fixture name that matches any value.
+const anyValue = "_"
+
+// maxWildcardParams bounds the query parameters tried as wildcards, as every
+// combination is a candidate file.
+const maxWildcardParams = 4
+
+// fixtureNames returns the candidate fixture paths of u, most specific first.
+func fixtureNames(u *url.URL) []string {
+	base := strings.TrimPrefix(path.Clean("/"+u.Path), "/")
+	if base == "" {
+		base = "index"
+	}
+	query := u.Query()
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	if len(keys) == 0 {
+		return []string{base + ".json"}
+	}
+
+	wildcards := min(len(keys), maxWildcardParams)
+	masks := make([]int, 0, 1<<wildcards)
+	for mask := range 1 << wildcards {
+		masks = append(masks, mask)
+	}
+	sort.SliceStable(masks, func(i, j int) bool {
+		return bits.OnesCount(uint(masks[i])) < bits.OnesCount(uint(masks[j]))
+	})
+	names := make([]string, 0, len(masks)+1)
+	for _, mask := range masks {
+		parts := make([]string, len(keys))
+		for i, key := range keys {
+			value := url.QueryEscape(query.Get(key))
+			if i < wildcards && mask&(1<<i) != 0 {
+				value = anyValue
+			}
+			parts[i] = url.QueryEscape(key) + "=" + value
+		}
+		names = append(names, base+"@"+strings.Join(parts, "&")+".json")
+	}
+	return append(names, base+".json")
+}
@@ -0,0 +1,196 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without calling upstream while the circuit breaker
+// of the request's host is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// Client sends HTTP requests. It is satisfied by *http.Client, so tests and
+// callers can inject their own transport.
+type Client interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+type Options struct {
+	// Timeout bounds each attempt. A deadline on the request context still applies.
+	Timeout time.Duration
+	// MaxRetries is the number of extra attempts for idempotent requests that
+	// fail with a network error or a 5xx status.
+	MaxRetries int
+	// RetryBackoff is the base of the exponential backoff between attempts,
+	// capped at MaxRetryBackoff. Each wait is jittered.
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+	// BreakerThreshold consecutive failures of a host open its circuit for
+	// BreakerCooldown. Zero disables the breaker.
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+// breaker is the circuit breaker state of one host.
+type breaker struct {
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+type client struct {
+	httpClient *http.Client
+	opts       Options
+
+	lock     sync.Mutex
+	breakers map[string]*breaker // by host, so one failing host does not block the others
+}
+
+func New(opts Options) Client {
+	return &client{
+		httpClient: &http.Client{Timeout: opts.Timeout},
+		opts:       opts,
+		breakers:   make(map[string]*breaker),
+	}
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func (c *client) Do(req *http.Request) (*http.Response, error) {
+	attempts := 1
+	if isIdempotent(req.Method) {
+		attempts += c.opts.MaxRetries
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			if err := c.sleep(req.Context(), attempt); err != nil {
+				return nil, err
+			}
+			if req.Body != nil && req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				req.Body = body
+			}
+		}
+		if err := c.allow(req.URL.Host); err != nil {
+			return nil, err
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			// The caller gave up, which says nothing about upstream health.
+			if req.Context().Err() != nil {
+				c.release(req.URL.Host)
+				return nil, err
+			}
+			c.record(req.URL.Host, false)
+			lastErr = err
+			continue
+		}
+		if resp.StatusCode >= http.StatusInternalServerError {
+			c.record(req.URL.Host, false)
+			if attempt < attempts-1 {
+				io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+				lastErr = fmt.Errorf("received status code %d", resp.StatusCode)
+				continue
+			}
+			return resp, nil
+		}
+		c.record(req.URL.Host, true)
+		return resp, nil
+	}
+	return nil, lastErr
+}
+
+// sleep waits out the jittered backoff before the given retry attempt.
+func (c *client) sleep(ctx context.Context, attempt int) error {
+	backoff := c.opts.RetryBackoff << (attempt - 1)
+	if c.opts.MaxRetryBackoff > 0 && (backoff > c.opts.MaxRetryBackoff || backoff <= 0) {
+		backoff = c.opts.MaxRetryBackoff
+	}
+	if backoff <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(backoff/2 + rand.N(backoff/2+1))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// breakerLocked returns the breaker of host, creating it on first use.
+func (c *client) breakerLocked(host string) *breaker {
+	b, ok := c.breakers[host]
+	if !ok {
+		b = &breaker{}
+		c.breakers[host] = b
+	}
+	return b
+}
+
+// allow fails fast while the circuit of host is open. Once the cooldown has
+// passed a single probe request is let through to decide whether to close it again.
+func (c *client) allow(host string) error {
+	if c.opts.BreakerThreshold <= 0 {
+		return nil
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	b := c.breakerLocked(host)
+	if b.failures < c.opts.BreakerThreshold {
+		return nil
+	}
+	if time.Now().Before(b.openUntil) || b.probing {
+		return ErrCircuitOpen
+	}
+	b.probing = true
+	return nil
+}
+
+func (c *client) record(host string, success bool) {
+	if c.opts.BreakerThreshold <= 0 {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	b := c.breakerLocked(host)
+	b.probing = false
+	if success {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= c.opts.BreakerThreshold {
+		b.openUntil = time.Now().Add(c.opts.BreakerCooldown)
+	}
+}
+
+// release gives up a probe slot of host without judging it.
+func (c *client) release(host string) {
+	if c.opts.BreakerThreshold <= 0 {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.breakerLocked(host).probing = false
+}
@@ -0,0 +1,96 @@
+package httpclient
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func get(t *testing.T, c Client, url string) (*http.Response, error) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.Do(req)
+	if resp != nil {
+		resp.Body.Close()
+	}
+	return resp, err
+}
+
+func TestRetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := New(Options{MaxRetries: 2})
+	resp, err := get(t, c, srv.URL)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("Do = %v, %v; want 200", resp, err)
+	}
+	if calls.Load() != 3 {
+		t.Fatalf("%d calls, want 3", calls.Load())
+	}
+}
+
+func TestBreakerIsPerHost(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer healthy.Close()
+
+	c := New(Options{BreakerThreshold: 2, BreakerCooldown: time.Minute})
+	for range 2 {
+		if _, err := get(t, c, failing.URL); err != nil {
+			t.Fatalf("Do(failing) error = %v before the breaker opened", err)
+		}
+	}
+	if _, err := get(t, c, failing.URL); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Do(failing) error = %v, want ErrCircuitOpen", err)
+	}
+	resp, err := get(t, c, healthy.URL)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("Do(healthy) = %v, %v; want 200 with the other host's breaker open", resp, err)
+	}
+}
+
+func TestBreakerProbesAfterCooldown(t *testing.T) {
+	var ok atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ok.Load() {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	c := New(Options{BreakerThreshold: 1, BreakerCooldown: 10 * time.Millisecond})
+	get(t, c, srv.URL)
+	if _, err := get(t, c, srv.URL); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("error = %v, want ErrCircuitOpen", err)
+	}
+	ok.Store(true)
+	time.Sleep(20 * time.Millisecond)
+	resp, err := get(t, c, srv.URL)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("probe = %v, %v; want 200", resp, err)
+	}
+	if _, err := get(t, c, srv.URL); err != nil {
+		t.Fatalf("error = %v after a successful probe, want the breaker closed", err)
+	}
+}
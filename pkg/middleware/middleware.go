@@ -2,24 +2,61 @@ package middleware
 
 import (
 	"bytes"
+	"crypto/subtle"
 	"io"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ntdat104/go-finance-dataset/internal/application/constants"
 	"github.com/ntdat104/go-finance-dataset/pkg/config"
+	"github.com/ntdat104/go-finance-dataset/pkg/datasource"
 	"github.com/ntdat104/go-finance-dataset/pkg/datetime"
 	"github.com/ntdat104/go-finance-dataset/pkg/logger"
 	"go.uber.org/zap"
 )
 
+// maxLoggedBodySize caps how much of a response body is kept for logging,
+// so streamed downloads are not buffered in memory.
+const maxLoggedBodySize = 64 * 1024
+
+// privatePathPrefixes are the routes serving account data or admin operations,
+// whose bodies are never logged.
+var privatePathPrefixes = []string{
+	constants.ApiAccount,
+	"/api/v1/admin/",
+}
+
+// redacted replaces the value of a credential header in the log.
+const redacted = "[REDACTED]"
+
+// isPrivatePath reports whether the bodies of path must stay out of the log.
+func isPrivatePath(path string) bool {
+	for _, prefix := range privatePathPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactHeader logs only whether a credential header was sent.
+func redactHeader(c *gin.Context, name string) string {
+	if c.GetHeader(name) == "" {
+		return ""
+	}
+	return redacted
+}
+
 type bodyLogWriter struct {
 	gin.ResponseWriter
 	body *bytes.Buffer
 }
 
 func (w bodyLogWriter) Write(b []byte) (int, error) {
-	w.body.Write(b) // capture response body
+	if remaining := maxLoggedBodySize - w.body.Len(); remaining > 0 {
+		w.body.Write(b[:min(len(b), remaining)]) // capture response body
+	}
 	return w.ResponseWriter.Write(b)
 }
 
@@ -35,6 +72,20 @@ func AuthMiddleware() gin.HandlerFunc {
 	}
 }
 
+// AdminAuthMiddleware only lets through requests carrying "Authorization: Bearer <token>".
+// An empty token disables the protected routes entirely.
+func AdminAuthMiddleware(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provided, ok := strings.CutPrefix(c.GetHeader(constants.Authorization), "Bearer ")
+		if token == "" || !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
 func CorsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
@@ -52,20 +103,33 @@ func CorsMiddleware() gin.HandlerFunc {
 	}
 }
 
+// DataSourceMiddleware lets services record where the data of a response came from,
+// which the response envelope then reports in its meta.
+func DataSourceMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, _ := datasource.NewContext(c.Request.Context())
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
 func ZapLoggerWithBody() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := datetime.GetCurrentLocalTime()
+		private := isPrivatePath(c.Request.URL.Path)
 
 		// Clone the request body
 		var reqBody []byte
-		if c.Request.Body != nil {
+		if c.Request.Body != nil && !private {
 			reqBody, _ = io.ReadAll(c.Request.Body)
 			c.Request.Body = io.NopCloser(bytes.NewBuffer(reqBody)) // restore
 		}
 
 		// Wrap the response writer to capture response body
 		blw := &bodyLogWriter{body: bytes.NewBufferString(""), ResponseWriter: c.Writer}
-		c.Writer = blw
+		if !private {
+			c.Writer = blw
+		}
 
 		// Process request
 		c.Next()
@@ -83,9 +147,9 @@ func ZapLoggerWithBody() gin.HandlerFunc {
 			zap.String("method", c.Request.Method),
 			zap.String("path", c.Request.URL.Path),
 			zap.String("x_api_key", c.GetHeader(constants.X_API_KEY)),
-			zap.String("x_api_secret", c.GetHeader(constants.X_API_SECRET)),
-			zap.String("access_token", c.GetHeader(constants.Authorization)),
-			zap.String("signature", c.GetHeader(constants.Signature)),
+			zap.String("x_api_secret", redactHeader(c, constants.X_API_SECRET)),
+			zap.String("access_token", redactHeader(c, constants.Authorization)),
+			zap.String("signature", redactHeader(c, constants.Signature)),
 			zap.Int("status", c.Writer.Status()),
 			zap.String("client_ip", c.ClientIP()),
 			zap.String("user_agent", c.Request.UserAgent()),
@@ -0,0 +1,162 @@
+package resp
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"strconv"
+	"time"
+)
+
+type Options struct {
+	Addr        string
+	Password    string
+	DB          int
+	PoolSize    int
+	DialTimeout time.Duration
+	// IOTimeout bounds writing a command and reading its reply.
+	IOTimeout time.Duration
+}
+
+// Client is a pooled RESP client safe for concurrent use.
+type Client struct {
+	opts Options
+	pool chan *conn
+}
+
+type conn struct {
+	netConn net.Conn
+	reader  *bufio.Reader
+	writer  *bufio.Writer
+}
+
+func NewClient(opts Options) *Client {
+	if opts.PoolSize <= 0 {
+		opts.PoolSize = 1
+	}
+	return &Client{
+		opts: opts,
+		pool: make(chan *conn, opts.PoolSize),
+	}
+}
+
+// Do sends a command and returns its reply, see readReply for the reply types.
+// Error replies are returned as an Error.
+func (c *Client) Do(args ...string) (any, error) {
+	cn, err := c.get()
+	if err != nil {
+		return nil, err
+	}
+	reply, err := cn.do(args, c.opts.IOTimeout)
+	if err != nil {
+		// The connection may hold half a reply, so it cannot be reused.
+		cn.netConn.Close()
+		return nil, err
+	}
+	c.put(cn)
+	if replyErr, ok := reply.(Error); ok {
+		return nil, replyErr
+	}
+	return reply, nil
+}
+
+// Bytes runs a command that replies with a bulk string. A nil reply is ErrNil.
+func (c *Client) Bytes(args ...string) ([]byte, error) {
+	reply, err := c.Do(args...)
+	if err != nil {
+		return nil, err
+	}
+	if reply == nil {
+		return nil, ErrNil
+	}
+	b, ok := reply.([]byte)
+	if !ok {
+		return nil, errors.New("resp: unexpected reply type")
+	}
+	return b, nil
+}
+
+// Int runs a command that replies with an integer.
+func (c *Client) Int(args ...string) (int64, error) {
+	reply, err := c.Do(args...)
+	if err != nil {
+		return 0, err
+	}
+	n, ok := reply.(int64)
+	if !ok {
+		return 0, errors.New("resp: unexpected reply type")
+	}
+	return n, nil
+}
+
+// Close closes the idle pooled connections.
+func (c *Client) Close() {
+	for {
+		select {
+		case cn := <-c.pool:
+			cn.netConn.Close()
+		default:
+			return
+		}
+	}
+}
+
+func (c *Client) get() (*conn, error) {
+	select {
+	case cn := <-c.pool:
+		return cn, nil
+	default:
+	}
+
+	netConn, err := net.DialTimeout("tcp", c.opts.Addr, c.opts.DialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	cn := &conn{
+		netConn: netConn,
+		reader:  bufio.NewReader(netConn),
+		writer:  bufio.NewWriter(netConn),
+	}
+	if c.opts.Password != "" {
+		if err := cn.expectOK([]string{"AUTH", c.opts.Password}, c.opts.IOTimeout); err != nil {
+			netConn.Close()
+			return nil, err
+		}
+	}
+	if c.opts.DB != 0 {
+		if err := cn.expectOK([]string{"SELECT", strconv.Itoa(c.opts.DB)}, c.opts.IOTimeout); err != nil {
+			netConn.Close()
+			return nil, err
+		}
+	}
+	return cn, nil
+}
+
+func (c *Client) put(cn *conn) {
+	select {
+	case c.pool <- cn:
+	default:
+		cn.netConn.Close()
+	}
+}
+
+func (cn *conn) do(args []string, timeout time.Duration) (any, error) {
+	if timeout > 0 {
+		cn.netConn.SetDeadline(time.Now().Add(timeout))
+	}
+	if err := writeCommand(cn.writer, args); err != nil {
+		return nil, err
+	}
+	return readReply(cn.reader)
+}
+
+func (cn *conn) expectOK(args []string, timeout time.Duration) error {
+	reply, err := cn.do(args, timeout)
+	if err != nil {
+		return err
+	}
+	if replyErr, ok := reply.(Error); ok {
+		return replyErr
+	}
+	return nil
+}
@@ -0,0 +1,339 @@
+package resp
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server is an in-process stand-in for Redis. It keeps data in memory and
+// supports PING, AUTH, SELECT, GET, SET (EX, PX, NX), DEL, EXISTS, PTTL,
+// SCAN (MATCH, COUNT), DBSIZE and FLUSHDB.
+type Server struct {
+	lock     sync.Mutex
+	data     map[string]serverEntry
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+}
+
+type serverEntry struct {
+	value    []byte
+	expireAt time.Time // zero means no expiry
+}
+
+func NewServer() *Server {
+	return &Server{
+		data:  make(map[string]serverEntry),
+		conns: make(map[net.Conn]struct{}),
+	}
+}
+
+// ListenAndServe listens on addr, e.g. "127.0.0.1:0", and serves in the
+// background. It returns the address actually listened on.
+func (s *Server) ListenAndServe(addr string) (string, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return "", err
+	}
+	s.lock.Lock()
+	s.listener = listener
+	s.lock.Unlock()
+	go s.serve(listener)
+	return listener.Addr().String(), nil
+}
+
+// Close stops listening and drops every client connection.
+func (s *Server) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for cn := range s.conns {
+		cn.Close()
+	}
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Close()
+}
+
+func (s *Server) serve(listener net.Listener) {
+	for {
+		cn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		s.lock.Lock()
+		s.conns[cn] = struct{}{}
+		s.lock.Unlock()
+		go s.handle(cn)
+	}
+}
+
+func (s *Server) handle(cn net.Conn) {
+	defer func() {
+		s.lock.Lock()
+		delete(s.conns, cn)
+		s.lock.Unlock()
+		cn.Close()
+	}()
+
+	r := bufio.NewReader(cn)
+	w := bufio.NewWriter(cn)
+	for {
+		req, err := readReply(r)
+		if err != nil {
+			return
+		}
+		items, ok := req.([]any)
+		if !ok || len(items) == 0 {
+			writeError(w, "ERR protocol error")
+		} else {
+			args := make([]string, len(items))
+			for i, item := range items {
+				b, _ := item.([]byte)
+				args[i] = string(b)
+			}
+			s.exec(w, args)
+		}
+		if err := w.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) exec(w *bufio.Writer, args []string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := time.Now()
+	switch strings.ToUpper(args[0]) {
+	case "PING":
+		w.WriteString("+PONG\r\n")
+	case "AUTH", "SELECT":
+		w.WriteString("+OK\r\n")
+	case "GET":
+		if len(args) != 2 {
+			writeArity(w, args[0])
+			return
+		}
+		entry, ok := s.lookup(args[1], now)
+		if !ok {
+			w.WriteString("$-1\r\n")
+			return
+		}
+		writeBulk(w, entry.value)
+	case "SET":
+		s.set(w, args, now)
+	case "DEL", "EXISTS":
+		if len(args) < 2 {
+			writeArity(w, args[0])
+			return
+		}
+		n := 0
+		for _, key := range args[1:] {
+			if _, ok := s.lookup(key, now); ok {
+				n++
+				if strings.EqualFold(args[0], "DEL") {
+					delete(s.data, key)
+				}
+			}
+		}
+		writeInt(w, int64(n))
+	case "PTTL":
+		if len(args) != 2 {
+			writeArity(w, args[0])
+			return
+		}
+		entry, ok := s.lookup(args[1], now)
+		switch {
+		case !ok:
+			writeInt(w, -2)
+		case entry.expireAt.IsZero():
+			writeInt(w, -1)
+		default:
+			writeInt(w, entry.expireAt.Sub(now).Milliseconds())
+		}
+	case "SCAN":
+		s.scan(w, args, now)
+	case "DBSIZE":
+		n := 0
+		for key := range s.data {
+			if _, ok := s.lookup(key, now); ok {
+				n++
+			}
+		}
+		writeInt(w, int64(n))
+	case "FLUSHDB":
+		s.data = make(map[string]serverEntry)
+		w.WriteString("+OK\r\n")
+	default:
+		writeError(w, fmt.Sprintf("ERR unknown command '%s'", args[0]))
+	}
+}
+
+// lookup returns an unexpired entry, dropping it if it has expired.
+func (s *Server) lookup(key string, now time.Time) (serverEntry, bool) {
+	entry, ok := s.data[key]
+	if !ok {
+		return entry, false
+	}
+	if !entry.expireAt.IsZero() && !now.Before(entry.expireAt) {
+		delete(s.data, key)
+		return entry, false
+	}
+	return entry, true
+}
+
+func (s *Server) set(w *bufio.Writer, args []string, now time.Time) {
+	if len(args) < 3 {
+		writeArity(w, args[0])
+		return
+	}
+	entry := serverEntry{value: []byte(args[2])}
+	nx := false
+	for i := 3; i < len(args); i++ {
+		switch opt := strings.ToUpper(args[i]); opt {
+		case "NX":
+			nx = true
+		case "EX", "PX":
+			if i+1 >= len(args) {
+				writeError(w, "ERR syntax error")
+				return
+			}
+			n, err := strconv.ParseInt(args[i+1], 10, 64)
+			if err != nil || n <= 0 {
+				writeError(w, "ERR invalid expire time in 'set' command")
+				return
+			}
+			unit := time.Millisecond
+			if opt == "EX" {
+				unit = time.Second
+			}
+			entry.expireAt = now.Add(time.Duration(n) * unit)
+			i++
+		default:
+			writeError(w, "ERR syntax error")
+			return
+		}
+	}
+	if _, exists := s.lookup(args[1], now); exists && nx {
+		w.WriteString("$-1\r\n")
+		return
+	}
+	s.data[args[1]] = entry
+	w.WriteString("+OK\r\n")
+}
+
+// scan treats the cursor as an offset into the sorted key space, which is
+// stable enough for a stand-in.
+func (s *Server) scan(w *bufio.Writer, args []string, now time.Time) {
+	if len(args) < 2 {
+		writeArity(w, args[0])
+		return
+	}
+	cursor, err := strconv.Atoi(args[1])
+	if err != nil || cursor < 0 {
+		writeError(w, "ERR invalid cursor")
+		return
+	}
+	pattern, count := "*", 10
+	for i := 2; i+1 < len(args); i += 2 {
+		switch strings.ToUpper(args[i]) {
+		case "MATCH":
+			pattern = args[i+1]
+		case "COUNT":
+			if count, err = strconv.Atoi(args[i+1]); err != nil || count <= 0 {
+				writeError(w, "ERR syntax error")
+				return
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(s.data))
+	for key := range s.data {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	end := min(cursor+count, len(keys))
+	var matched []string
+	for _, key := range keys[min(cursor, end):end] {
+		if _, ok := s.lookup(key, now); ok && globMatch(pattern, key) {
+			matched = append(matched, key)
+		}
+	}
+	next := end
+	if end >= len(keys) {
+		next = 0
+	}
+
+	w.WriteString("*2\r\n")
+	writeBulk(w, []byte(strconv.Itoa(next)))
+	fmt.Fprintf(w, "*%d\r\n", len(matched))
+	for _, key := range matched {
+		writeBulk(w, []byte(key))
+	}
+}
+
+// globMatch matches Redis style patterns with *, ? and backslash escapes.
+func globMatch(pattern, s string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for i := len(s); i >= 0; i-- {
+				if globMatch(pattern[1:], s[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(s) == 0 {
+				return false
+			}
+		case '\\':
+			if len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+			fallthrough
+		default:
+			if len(s) == 0 || s[0] != pattern[0] {
+				return false
+			}
+		}
+		pattern, s = pattern[1:], s[1:]
+	}
+	return len(s) == 0
+}
+
+// EscapeGlob escapes the glob characters of s for use in a MATCH pattern.
+func EscapeGlob(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`*?[]\`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func writeBulk(w *bufio.Writer, b []byte) {
+	fmt.Fprintf(w, "$%d\r\n", len(b))
+	w.Write(b)
+	w.WriteString("\r\n")
+}
+
+func writeInt(w *bufio.Writer, n int64) {
+	fmt.Fprintf(w, ":%d\r\n", n)
+}
+
+func writeError(w *bufio.Writer, msg string) {
+	fmt.Fprintf(w, "-%s\r\n", msg)
+}
+
+func writeArity(w *bufio.Writer, cmd string) {
+	writeError(w, fmt.Sprintf("ERR wrong number of arguments for '%s' command", strings.ToLower(cmd)))
+}